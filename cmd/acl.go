@@ -0,0 +1,382 @@
+package cmd
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// xsiNamespace porte l'attribut xsi:type qui distingue les bénéficiaires d'une ACL
+const xsiNamespace = "http://www.w3.org/2001/XMLSchema-instance"
+
+// Types de bénéficiaires d'une ACL
+const (
+	granteeUser  = "CanonicalUser"
+	granteeEmail = "AmazonCustomerByEmail"
+	granteeGroup = "Group"
+)
+
+// aclPermissions sont les permissions qu'une ACL peut accorder
+var aclPermissions = []string{"READ", "WRITE", "READ_ACP", "WRITE_ACP", "FULL_CONTROL"}
+
+// objectCannedACLs complète les ACL prédéfinies des buckets par celles propres aux objets
+var objectCannedACLs = append(slices.Clone(bucketCannedACLs), "aws-exec-read", "bucket-owner-read", "bucket-owner-full-control")
+
+// aclGroups associe les noms courts des groupes prédéfinis à leur URI
+var aclGroups = map[string]string{
+	"AllUsers":           "http://acs.amazonaws.com/groups/global/AllUsers",
+	"AuthenticatedUsers": "http://acs.amazonaws.com/groups/global/AuthenticatedUsers",
+	"LogDelivery":        "http://acs.amazonaws.com/groups/s3/LogDelivery",
+}
+
+// ACLOwner est le propriétaire d'un bucket ou d'un objet
+type ACLOwner struct {
+	ID          string `xml:"ID"`
+	DisplayName string `xml:"DisplayName,omitempty"`
+}
+
+// Grantee désigne le bénéficiaire d'une permission : utilisateur (ID canonique), adresse e-mail ou groupe (URI)
+type Grantee struct {
+	Type         string `xml:"http://www.w3.org/2001/XMLSchema-instance type,attr"`
+	ID           string `xml:"ID,omitempty"`
+	DisplayName  string `xml:"DisplayName,omitempty"`
+	EmailAddress string `xml:"EmailAddress,omitempty"`
+	URI          string `xml:"URI,omitempty"`
+}
+
+// MarshalXML écrit xsi:type avec le préfixe attendu par S3, que encoding/xml ne sait pas produire
+func (g Grantee) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Attr = []xml.Attr{
+		{Name: xml.Name{Local: "xmlns:xsi"}, Value: xsiNamespace},
+		{Name: xml.Name{Local: "xsi:type"}, Value: g.Type},
+	}
+	type fields struct {
+		ID           string `xml:"ID,omitempty"`
+		DisplayName  string `xml:"DisplayName,omitempty"`
+		EmailAddress string `xml:"EmailAddress,omitempty"`
+		URI          string `xml:"URI,omitempty"`
+	}
+	return e.EncodeElement(fields{g.ID, g.DisplayName, g.EmailAddress, g.URI}, start)
+}
+
+// Grant associe un bénéficiaire à une permission
+type Grant struct {
+	Grantee    Grantee `xml:"Grantee"`
+	Permission string  `xml:"Permission"`
+}
+
+// AccessControlPolicy représente le document XML de la sous-ressource ?acl
+type AccessControlPolicy struct {
+	XMLName xml.Name `xml:"AccessControlPolicy"`
+	Xmlns   string   `xml:"xmlns,attr,omitempty"`
+	Owner   ACLOwner `xml:"Owner"`
+	Grants  []Grant  `xml:"AccessControlList>Grant"`
+}
+
+// aclChange décrit la modification demandée : une ACL prédéfinie, ou des permissions explicites
+// qui remplacent (ou complètent, avec add) les permissions actuelles
+type aclChange struct {
+	canned string
+	grants []Grant
+	add    bool
+}
+
+var (
+	aclRawXML    bool
+	aclVersionID string
+	aclCanned    string
+	aclGrants    []string
+	aclAdd       bool
+	aclRecursive bool
+)
+
+// ACLCmd regroupe les sous-commandes de gestion des ACL
+var ACLCmd = &cobra.Command{
+	Use:   "acl",
+	Short: "Reads or sets the access control list of a bucket or an object",
+	Long: `Reads or sets ACLs. The target is an object (<bucket-name> <object-key> or
+s3://bucket/key) or a whole bucket (<bucket-name> or s3://bucket).
+For example:
+
+bs3 acl get s3://press-kit/logo.png
+bs3 acl set s3://press-kit/2024/ --recursive --canned public-read
+bs3 acl set reports --grant read=email:auditor@example.com --grant full-control=id:79a59df900b949e5`,
+}
+
+var aclGetCmd = &cobra.Command{
+	Use:   "get <target>",
+	Short: "Shows the owner and the grants of a bucket or an object (or the S3 XML with --xml)",
+	Run: func(cmd *cobra.Command, args []string) {
+		bucketName, key, _, err := tagTarget(args)
+		if err != nil {
+			log.Printf("Usage: acl get <bucket-name> [object-key] [--version-id <id>] [--xml] (%v)", err)
+			return
+		}
+		client, err := newConfiguredClient()
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+
+		policy, raw, err := getACL(cmd.Context(), client, bucketName, key, aclVersionID)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		if aclRawXML {
+			fmt.Println(string(raw))
+			return
+		}
+		printACL(policy)
+	},
+}
+
+var aclSetCmd = &cobra.Command{
+	Use:   "set <target> (--canned <acl> | --grant <permission>=<type>:<value>...)",
+	Short: "Applies a canned ACL or explicit grants to a bucket, an object or every object under a prefix",
+	Long: `Applies a canned ACL (--canned) or explicit grants (--grant, repeatable). A grant is written
+<permission>=<type>:<value>, where permission is read, write, read-acp, write-acp or full-control
+and the grantee is id:<canonical-id>, email:<address>, uri:<group-uri> or group:<AllUsers|AuthenticatedUsers|LogDelivery>.
+
+Explicit grants replace the current ones (the owner keeps FULL_CONTROL), or are added to them with --add.
+With --recursive, the target is a prefix and the ACL is applied to every object under it.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		bucketName, key, _, err := tagTarget(args)
+		if err != nil || (aclCanned == "") == (len(aclGrants) == 0) {
+			log.Println("Usage: acl set <bucket-name> [object-key|prefix] (--canned <acl> | --grant <permission>=<type>:<value>...) [--add] [--recursive]")
+			return
+		}
+		change := aclChange{canned: aclCanned, add: aclAdd}
+		if change.grants, err = parseGrants(aclGrants); err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		cannedACLs := objectCannedACLs
+		if key == "" && !aclRecursive {
+			cannedACLs = bucketCannedACLs
+		}
+		if change.canned != "" && !slices.Contains(cannedACLs, change.canned) {
+			log.Printf("Error: unsupported canned ACL '%s' (expected one of %s)", change.canned, strings.Join(cannedACLs, ", "))
+			return
+		}
+		if change.add && change.canned != "" {
+			log.Println("Error: --add only applies to --grant")
+			return
+		}
+		if aclRecursive && aclVersionID != "" {
+			log.Println("Error: --version-id cannot be combined with --recursive")
+			return
+		}
+		client, err := newConfiguredClient()
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+
+		if aclRecursive {
+			setACLRecursive(cmd.Context(), client, bucketName, key, change)
+			return
+		}
+		if err := applyACL(cmd.Context(), client, bucketName, key, aclVersionID, change); err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		fmt.Printf("ACL of %s updated.\n", tagTargetName(bucketName, key))
+	},
+}
+
+// setACLRecursive applique l'ACL à chaque objet sous le préfixe ; un échec n'interrompt pas les suivants
+func setACLRecursive(ctx context.Context, client *s3Client, bucketName, prefix string, change aclChange) {
+	objects, err := listObjects(ctx, client, bucketName, prefix)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	if len(objects) == 0 {
+		fmt.Printf("No objects under prefix '%s' in bucket '%s'.\n", prefix, bucketName)
+		return
+	}
+
+	failed := 0
+	for i, obj := range objects {
+		if err := applyACL(ctx, client, bucketName, obj.Key, "", change); err != nil {
+			if ctx.Err() != nil {
+				log.Printf("Error: interrupted after %d of %d object(s)", i, len(objects))
+				return
+			}
+			fmt.Printf("Failed to set the ACL of '%s': %v\n", obj.Key, err)
+			failed++
+		}
+	}
+	fmt.Printf("ACL applied to %d object(s) under prefix '%s' in bucket '%s'.\n", len(objects)-failed, prefix, bucketName)
+	if failed > 0 {
+		log.Printf("Error: %d object(s) could not be updated", failed)
+	}
+}
+
+// parseGrants lit les permissions --grant de la forme permission=type:valeur
+func parseGrants(values []string) ([]Grant, error) {
+	var grants []Grant
+	for _, value := range values {
+		permission, grantee, found := strings.Cut(value, "=")
+		kind, target, typed := strings.Cut(grantee, ":")
+		if !found || !typed || target == "" {
+			return nil, fmt.Errorf("invalid grant '%s' (expected <permission>=<id|email|uri|group>:<value>)", value)
+		}
+		permission = strings.ToUpper(strings.ReplaceAll(permission, "-", "_"))
+		if !slices.Contains(aclPermissions, permission) {
+			return nil, fmt.Errorf("invalid permission in grant '%s' (expected read, write, read-acp, write-acp or full-control)", value)
+		}
+
+		grant := Grant{Permission: permission}
+		switch strings.ToLower(kind) {
+		case "id":
+			grant.Grantee = Grantee{Type: granteeUser, ID: target}
+		case "email":
+			if !strings.Contains(target, "@") {
+				return nil, fmt.Errorf("invalid email address in grant '%s'", value)
+			}
+			grant.Grantee = Grantee{Type: granteeEmail, EmailAddress: target}
+		case "uri":
+			grant.Grantee = Grantee{Type: granteeGroup, URI: target}
+		case "group":
+			uri, ok := aclGroups[target]
+			if !ok {
+				return nil, fmt.Errorf("unknown group '%s' in grant '%s' (expected AllUsers, AuthenticatedUsers or LogDelivery)", target, value)
+			}
+			grant.Grantee = Grantee{Type: granteeGroup, URI: uri}
+		default:
+			return nil, fmt.Errorf("invalid grantee type '%s' in grant '%s' (expected id, email, uri or group)", kind, value)
+		}
+		grants = append(grants, grant)
+	}
+	return grants, nil
+}
+
+// getACL lit l'ACL d'un bucket ou d'un objet, avec le document XML brut
+func getACL(ctx context.Context, client *s3Client, bucketName, key, versionID string) (*AccessControlPolicy, []byte, error) {
+	data, err := client.getSubresource(ctx, bucketName, key, "acl", versionQuery(versionID))
+	if err != nil {
+		return nil, nil, aclError(err, bucketName)
+	}
+	var policy AccessControlPolicy
+	if err := xml.Unmarshal(data, &policy); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse ACL: %w", err)
+	}
+	return &policy, data, nil
+}
+
+// applyACL applique la modification : l'ACL prédéfinie par l'en-tête x-amz-acl, les permissions
+// explicites par un document complet construit à partir de l'ACL actuelle (pour son propriétaire)
+func applyACL(ctx context.Context, client *s3Client, bucketName, key, versionID string, change aclChange) error {
+	if change.canned != "" {
+		header := http.Header{"X-Amz-Acl": {change.canned}}
+		return aclError(client.putSubresource(ctx, bucketName, key, "acl", versionQuery(versionID), nil, header), bucketName)
+	}
+
+	current, _, err := getACL(ctx, client, bucketName, key, versionID)
+	if err != nil {
+		return err
+	}
+	policy := AccessControlPolicy{Xmlns: s3XMLNamespace, Owner: current.Owner}
+	if change.add {
+		policy.Grants = current.Grants
+	} else {
+		policy.Grants = []Grant{{Grantee: Grantee{Type: granteeUser, ID: current.Owner.ID}, Permission: "FULL_CONTROL"}}
+	}
+	for _, grant := range change.grants {
+		if !slices.ContainsFunc(policy.Grants, func(g Grant) bool { return sameGrant(g, grant) }) {
+			policy.Grants = append(policy.Grants, grant)
+		}
+	}
+
+	body, err := xml.Marshal(policy)
+	if err != nil {
+		return fmt.Errorf("failed to marshal ACL: %w", err)
+	}
+	return aclError(client.putSubresource(ctx, bucketName, key, "acl", versionQuery(versionID), body, nil), bucketName)
+}
+
+func sameGrant(a, b Grant) bool {
+	return a.Permission == b.Permission && a.Grantee.Type == b.Grantee.Type && a.Grantee.ID == b.Grantee.ID &&
+		strings.EqualFold(a.Grantee.EmailAddress, b.Grantee.EmailAddress) && a.Grantee.URI == b.Grantee.URI
+}
+
+// errACLsDisabled identifie le refus d'un bucket dont les ACL sont désactivées
+var errACLsDisabled = errors.New("ACLs are disabled")
+
+// aclError explique le refus d'un bucket dont les ACL sont désactivées
+func aclError(err error, bucketName string) error {
+	var s3Err *S3Error
+	if errors.As(err, &s3Err) && s3Err.Code == "AccessControlListNotSupported" {
+		return fmt.Errorf("%w on bucket '%s' (Object Ownership: bucket owner enforced); use a bucket policy instead", errACLsDisabled, bucketName)
+	}
+	return err
+}
+
+// printACL affiche le propriétaire puis un tableau bénéficiaire / type / permission
+func printACL(policy *AccessControlPolicy) {
+	fmt.Printf("Owner: %s\n", describeUser(policy.Owner.DisplayName, policy.Owner.ID))
+	if len(policy.Grants) == 0 {
+		fmt.Println("No grants.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "GRANTEE\tTYPE\tPERMISSION")
+	public := false
+	for _, grant := range policy.Grants {
+		name, kind := describeGrantee(grant.Grantee)
+		fmt.Fprintf(w, "%s\t%s\t%s\n", name, kind, grant.Permission)
+		public = public || grant.Grantee.URI == aclGroups["AllUsers"]
+	}
+	w.Flush()
+	if public {
+		fmt.Println("Warning: the ACL grants access to everyone (AllUsers).")
+	}
+}
+
+// describeGrantee retourne le nom lisible et le type d'un bénéficiaire
+func describeGrantee(g Grantee) (string, string) {
+	switch g.Type {
+	case granteeGroup:
+		for name, uri := range aclGroups {
+			if uri == g.URI {
+				return name, "group"
+			}
+		}
+		return g.URI, "group"
+	case granteeEmail:
+		return g.EmailAddress, "email"
+	default:
+		return describeUser(g.DisplayName, g.ID), "user"
+	}
+}
+
+func describeUser(displayName, id string) string {
+	if displayName == "" {
+		return id
+	}
+	return fmt.Sprintf("%s (%s)", displayName, id)
+}
+
+func init() {
+	aclGetCmd.Flags().BoolVar(&aclRawXML, "xml", false, "print the AccessControlPolicy XML as returned by S3")
+	for _, c := range []*cobra.Command{aclGetCmd, aclSetCmd} {
+		c.Flags().StringVar(&aclVersionID, "version-id", "", "target a specific version of the object")
+	}
+	aclSetCmd.Flags().StringVar(&aclCanned, "canned", "", "canned ACL: "+strings.Join(objectCannedACLs, ", "))
+	aclSetCmd.Flags().StringArrayVar(&aclGrants, "grant", nil, "grant a permission: <permission>=<id|email|uri|group>:<value>; repeat for several grants")
+	aclSetCmd.Flags().BoolVar(&aclAdd, "add", false, "add the --grant permissions to the current ones instead of replacing them")
+	aclSetCmd.Flags().BoolVar(&aclRecursive, "recursive", false, "apply the ACL to every object under the prefix")
+	ACLCmd.AddCommand(aclGetCmd, aclSetCmd)
+	RootCmd.AddCommand(ACLCmd)
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Styles d'adressage des buckets
+const (
+	addressingPath    = "path"
+	addressingVirtual = "virtual"
+	addressingAuto    = "auto"
+)
+
+var dnsBucketPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+
+// expandEndpointTemplate remplace {region} dans un modèle d'endpoint (ex. https://s3.{region}.example.com)
+func expandEndpointTemplate(template, region string) string {
+	return strings.ReplaceAll(template, "{region}", region)
+}
+
+// isDNSCompatibleBucket indique si le nom du bucket peut servir de label DNS (adressage virtual-host)
+func isDNSCompatibleBucket(bucket string) bool {
+	return dnsBucketPattern.MatchString(bucket) &&
+		!strings.Contains(bucket, "..") &&
+		!strings.Contains(bucket, ".-") &&
+		!strings.Contains(bucket, "-.") &&
+		net.ParseIP(bucket) == nil
+}
+
+// endpointFor retourne l'endpoint à utiliser pour un bucket, en tenant compte de sa région connue
+func (c *s3Client) endpointFor(bucket string) (*url.URL, error) {
+	endpoint := c.profile.APIURL
+	if region := c.regionFor(bucket); c.profile.EndpointTemplate != "" && region != c.profile.Region {
+		endpoint = expandEndpointTemplate(c.profile.EndpointTemplate, region)
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid API URL '%s'", endpoint)
+	}
+	return u, nil
+}
+
+// useVirtualHost choisit l'adressage virtual-host pour le bucket selon le style du profil.
+// En mode auto, les endpoints IP/localhost et les noms non compatibles DNS restent en path-style.
+func (c *s3Client) useVirtualHost(bucket string, endpoint *url.URL) bool {
+	switch c.profile.AddressingStyle {
+	case addressingVirtual:
+		return true
+	case addressingPath:
+		return false
+	}
+
+	host := endpoint.Hostname()
+	if net.ParseIP(host) != nil || host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+	if !isDNSCompatibleBucket(bucket) {
+		return false
+	}
+	// Un bucket contenant des points casserait la correspondance du certificat wildcard
+	return !(endpoint.Scheme == "https" && strings.Contains(bucket, "."))
+}
+
+// bucketURL construit l'URL d'un bucket
+func (c *s3Client) bucketURL(bucket string) (*url.URL, error) {
+	return c.objectURL(bucket, "")
+}
+
+// objectURL construit l'URL d'un objet en path-style (endpoint/bucket/key)
+// ou en virtual-host-style (bucket.endpoint/key)
+func (c *s3Client) objectURL(bucket, key string) (*url.URL, error) {
+	u, err := c.endpointFor(bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	basePath := strings.TrimSuffix(u.Path, "/")
+	switch {
+	case bucket == "":
+		u.Path = basePath + "/"
+	case c.useVirtualHost(bucket, u):
+		u.Host = bucket + "." + u.Host
+		u.Path = basePath + "/" + key
+	default:
+		u.Path = basePath + "/" + bucket + "/" + key
+	}
+	return u, nil
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	applyPrune bool
+	applyYes   bool
+)
+
+// ApplyCmd modifie les buckets pour qu'ils correspondent au fichier déclaratif
+var ApplyCmd = &cobra.Command{
+	Use:   "apply <config-file>",
+	Short: "Creates and updates buckets to match a declarative bucket configuration",
+	Long: `Prints the plan (see plan), then makes the changes in order: for each declared bucket,
+creation, then versioning, encryption, policy, CORS, lifecycle and tags. With --prune, the buckets
+of the account that are not declared are deleted last; S3 only deletes empty buckets. These
+deletions are confirmed at a prompt before any change, or beforehand with --yes.
+
+Nothing is changed if a bucket is in conflict (region mismatch, unreadable section). On error,
+apply stops; the changes already made are kept and running apply again resumes from there.
+For example:
+
+bs3 apply buckets.yaml
+bs3 apply buckets.yaml --prune
+bs3 apply buckets.yaml --prune --yes`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 {
+			log.Println("Usage: apply <config-file> [--prune [--yes]]")
+			return
+		}
+		declared, err := loadBucketsFile(args[0])
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+
+		client, err := newConfiguredClient()
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		plan, err := buildBucketPlan(cmd.Context(), client, declared, applyPrune)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		printBucketPlan(plan)
+
+		if conflicts := countBucketSteps(plan, bucketConflict); conflicts > 0 {
+			log.Printf("Error: %d bucket(s) in conflict; nothing was changed", conflicts)
+			return
+		}
+		total := countPlannedChanges(plan)
+		if total == 0 {
+			fmt.Println("Nothing to apply: the buckets match the configuration.")
+			return
+		}
+
+		// Les suppressions de --prune sont confirmées avant toute modification
+		if deletes := countBucketSteps(plan, bucketDelete); deletes > 0 && !applyYes {
+			confirmed, err := confirmPrune(cmd.InOrStdin(), deletes)
+			if err != nil {
+				log.Printf("Error: %v", err)
+				return
+			}
+			if !confirmed {
+				fmt.Println("Apply cancelled: nothing was changed.")
+				return
+			}
+		}
+
+		done, err := applyBucketPlan(cmd.Context(), client, plan)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			fmt.Printf("%d of %d change(s) applied before the error; fix it and run apply again.\n", done, total)
+			return
+		}
+		fmt.Printf("Apply complete: %d change(s) applied.\n", done)
+	},
+}
+
+// confirmPrune demande de confirmer la suppression des buckets non déclarés ; sans terminal
+// pour répondre, la suppression doit être confirmée par --yes
+func confirmPrune(in io.Reader, count int) (bool, error) {
+	if f, ok := in.(*os.File); ok && !isTerminal(f) {
+		return false, fmt.Errorf("--prune would delete %d bucket(s): pass --yes to confirm, or run apply from a terminal; nothing was changed", count)
+	}
+	fmt.Printf("Delete %d bucket(s) not declared in the configuration? Type 'yes' to confirm: ", count)
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+	return strings.TrimSpace(answer) == "yes", nil
+}
+
+// countPlannedChanges compte les appels du plan : création ou suppression d'un bucket, et chaque section modifiée
+func countPlannedChanges(plan []bucketStep) int {
+	n := 0
+	for _, step := range plan {
+		if step.action == bucketCreate || step.action == bucketDelete {
+			n++
+		}
+		n += len(step.changes)
+	}
+	return n
+}
+
+// applyBucketPlan applique le plan dans l'ordre et s'arrête à la première erreur ;
+// retourne le nombre de modifications faites
+func applyBucketPlan(ctx context.Context, client *s3Client, plan []bucketStep) (int, error) {
+	done := 0
+	for _, step := range plan {
+		switch step.action {
+		case bucketCreate:
+			err := createBucket(ctx, client, step.bucket, createBucketOptions{region: step.region})
+			var conflict *bucketConflictError
+			if errors.As(err, &conflict) {
+				return done, fmt.Errorf("cannot create bucket '%s': the name is already taken (bucket names are global)", step.bucket)
+			}
+			if err != nil {
+				return done, fmt.Errorf("cannot create bucket '%s': %s", step.bucket, strings.TrimSpace(err.Error()))
+			}
+			done++
+			fmt.Printf("Bucket '%s' created.\n", step.bucket)
+		case bucketDelete:
+			if err := removeBucket(ctx, client, step.bucket); err != nil {
+				return done, fmt.Errorf("cannot delete bucket '%s': %w", step.bucket, err)
+			}
+			done++
+			fmt.Printf("Bucket '%s' deleted.\n", step.bucket)
+			continue
+		}
+
+		for _, change := range step.changes {
+			setting := change.setting
+			if err := setting.apply(ctx, client, step.bucket); err != nil {
+				return done, fmt.Errorf("cannot update the %s of bucket '%s': %w", setting.key, step.bucket, err)
+			}
+			done++
+			if setting.desired.Status == sectionConfigured {
+				fmt.Printf("Bucket '%s': %s set to %s.\n", step.bucket, setting.key, setting.desired.Summary)
+			} else {
+				fmt.Printf("Bucket '%s': %s removed.\n", step.bucket, setting.key)
+			}
+		}
+	}
+	return done, nil
+}
+
+func init() {
+	ApplyCmd.Flags().BoolVar(&applyPrune, "prune", false, "delete the buckets of the account that are not declared")
+	ApplyCmd.Flags().BoolVar(&applyYes, "yes", false, "with --prune, delete the undeclared buckets without asking for confirmation")
+	RootCmd.AddCommand(ApplyCmd)
+}
@@ -0,0 +1,349 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// bucketsFile est le fichier de configuration déclarative lu par plan et apply
+type bucketsFile struct {
+	Buckets []bucketDeclaration `yaml:"buckets"`
+}
+
+// bucketDeclaration décrit l'état voulu d'un bucket. Une section absente n'est pas gérée et reste
+// telle quelle ; une section vide ({} ou []) ne doit pas être configurée et est supprimée.
+type bucketDeclaration struct {
+	Name       string              `yaml:"name"`
+	Region     string              `yaml:"region,omitempty"`
+	Versioning *bool               `yaml:"versioning,omitempty"`
+	Encryption *encryptionSpec     `yaml:"encryption,omitempty"`
+	Policy     yaml.Node           `yaml:"policy,omitempty"`
+	CORS       []corsRuleSpec      `yaml:"cors,omitempty"`
+	Lifecycle  []lifecycleRuleSpec `yaml:"lifecycle,omitempty"`
+	Tags       map[string]string   `yaml:"tags,omitempty"`
+}
+
+// encryptionSpec est la forme YAML du chiffrement par défaut d'un bucket
+type encryptionSpec struct {
+	SSE       string `yaml:"sse,omitempty"`
+	KMSKeyID  string `yaml:"kms_key_id,omitempty"`
+	BucketKey bool   `yaml:"bucket_key,omitempty"`
+}
+
+// bucketSetting est une section gérée d'un bucket : son état voulu, décrit comme par describe-bucket,
+// et l'appel qui l'applique. showDiff affiche le détail des documents (politique, règles) dans le plan.
+type bucketSetting struct {
+	key      string
+	desired  bucketSection
+	showDiff bool
+	upToDate func(actual bucketSection) bool
+	apply    func(ctx context.Context, client *s3Client, bucketName string) error
+}
+
+// desiredBucket est un bucket déclaré, avec ses sections gérées dans l'ordre d'application
+type desiredBucket struct {
+	name     string
+	region   string
+	settings []bucketSetting
+}
+
+// loadBucketsFile lit et vérifie un fichier de configuration déclarative, avant tout appel
+func loadBucketsFile(path string) ([]desiredBucket, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file bucketsFile
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid configuration file '%s': %w", path, err)
+	}
+	if len(file.Buckets) == 0 {
+		return nil, fmt.Errorf("configuration file '%s' declares no buckets", path)
+	}
+
+	seen := map[string]bool{}
+	var buckets []desiredBucket
+	for _, declaration := range file.Buckets {
+		if err := validateBucketName(declaration.Name); err != nil {
+			return nil, err
+		}
+		if seen[declaration.Name] {
+			return nil, fmt.Errorf("bucket '%s' is declared twice", declaration.Name)
+		}
+		seen[declaration.Name] = true
+		bucket, err := declaration.desired()
+		if err != nil {
+			return nil, fmt.Errorf("bucket '%s': %w", declaration.Name, err)
+		}
+		buckets = append(buckets, bucket)
+	}
+	return buckets, nil
+}
+
+// desired convertit la déclaration en sections gérées, vérifiées comme par les commandes
+// encryption, policy, cors et lifecycle
+func (d bucketDeclaration) desired() (desiredBucket, error) {
+	bucket := desiredBucket{name: d.Name, region: d.Region}
+
+	if d.Versioning != nil {
+		config := VersioningConfiguration{Xmlns: s3XMLNamespace, Status: "Suspended"}
+		if *d.Versioning {
+			config.Status = "Enabled"
+		}
+		bucket.settings = append(bucket.settings, bucketSetting{
+			key:     "versioning",
+			desired: versioningSectionOf(&config),
+			// Le résumé commence par le statut ; un bucket jamais versionné n'a pas à être suspendu
+			upToDate: func(actual bucketSection) bool {
+				status, _, _ := strings.Cut(actual.Summary, ",")
+				return status == config.Status || (actual.Status == sectionNotConfigured && config.Status == "Suspended")
+			},
+			apply: func(ctx context.Context, client *s3Client, bucketName string) error {
+				body, err := xml.Marshal(config)
+				if err != nil {
+					return err
+				}
+				return client.putSubresource(ctx, bucketName, "", "versioning", nil, body, nil)
+			},
+		})
+	}
+
+	if d.Encryption != nil {
+		config, err := d.Encryption.configuration()
+		if err != nil {
+			return bucket, err
+		}
+		bucket.settings = append(bucket.settings, bucketSetting{
+			key:     "encryption",
+			desired: encryptionSectionOf(config),
+			apply: func(ctx context.Context, client *s3Client, bucketName string) error {
+				if config == nil {
+					return client.deleteSubresource(ctx, bucketName, "", "encryption", nil)
+				}
+				body, err := xml.Marshal(config)
+				if err != nil {
+					return err
+				}
+				return client.putSubresource(ctx, bucketName, "", "encryption", nil, body, nil)
+			},
+		})
+	}
+
+	if d.Policy.Kind != 0 && d.Policy.ShortTag() != "!!null" {
+		data, err := policyDocument(&d.Policy)
+		if err != nil {
+			return bucket, err
+		}
+		policy := ""
+		if data != nil {
+			if problems := validatePolicy(data, d.Name); len(problems) > 0 {
+				return bucket, fmt.Errorf("invalid policy: %w", errors.Join(problems...))
+			}
+			if policy, err = normalizeJSON(data); err != nil {
+				return bucket, err
+			}
+		}
+		bucket.settings = append(bucket.settings, bucketSetting{
+			key:      "policy",
+			desired:  policySectionOf(policy),
+			showDiff: true,
+			apply: func(ctx context.Context, client *s3Client, bucketName string) error {
+				if data == nil {
+					return client.deleteSubresource(ctx, bucketName, "", "policy", nil)
+				}
+				header := http.Header{"Content-Type": {"application/json"}}
+				return client.putSubresource(ctx, bucketName, "", "policy", nil, data, header)
+			},
+		})
+	}
+
+	if d.CORS != nil {
+		var config *CORSConfiguration
+		if len(d.CORS) > 0 {
+			config = corsSpec{Rules: d.CORS}.configuration()
+			if err := validateCORSConfig(config); err != nil {
+				return bucket, err
+			}
+		}
+		bucket.settings = append(bucket.settings, bucketSetting{
+			key:      "cors",
+			desired:  corsSectionOf(config),
+			showDiff: true,
+			apply: func(ctx context.Context, client *s3Client, bucketName string) error {
+				if config == nil {
+					return client.deleteSubresource(ctx, bucketName, "", "cors", nil)
+				}
+				body, err := xml.Marshal(config)
+				if err != nil {
+					return err
+				}
+				return client.putSubresource(ctx, bucketName, "", "cors", nil, body, nil)
+			},
+		})
+	}
+
+	if d.Lifecycle != nil {
+		var config *LifecycleConfiguration
+		if len(d.Lifecycle) > 0 {
+			var err error
+			if config, err = (lifecycleSpec{Rules: d.Lifecycle}).configuration(); err != nil {
+				return bucket, err
+			}
+		}
+		bucket.settings = append(bucket.settings, bucketSetting{
+			key:      "lifecycle",
+			desired:  lifecycleSectionOf(config),
+			showDiff: true,
+			apply: func(ctx context.Context, client *s3Client, bucketName string) error {
+				if config == nil {
+					return client.deleteSubresource(ctx, bucketName, "", "lifecycle", nil)
+				}
+				body, err := xml.Marshal(config)
+				if err != nil {
+					return err
+				}
+				return client.putSubresource(ctx, bucketName, "", "lifecycle", nil, body, nil)
+			},
+		})
+	}
+
+	if d.Tags != nil {
+		tags := d.Tags
+		bucket.settings = append(bucket.settings, bucketSetting{
+			key:     "tags",
+			desired: tagsSectionOf(tags),
+			apply: func(ctx context.Context, client *s3Client, bucketName string) error {
+				if len(tags) == 0 {
+					return client.deleteSubresource(ctx, bucketName, "", "tagging", nil)
+				}
+				return putTags(ctx, client, bucketName, "", tags)
+			},
+		})
+	}
+
+	for i := range bucket.settings {
+		if setting := &bucket.settings[i]; setting.upToDate == nil {
+			desired := setting.desired
+			setting.upToDate = func(actual bucketSection) bool { return sameSection(actual, desired) }
+		}
+	}
+	return bucket, nil
+}
+
+// configuration convertit le chiffrement déclaré ; nil si le bucket ne doit pas en avoir
+func (spec encryptionSpec) configuration() (*ServerSideEncryptionConfiguration, error) {
+	switch {
+	case spec.SSE == "" && (spec.KMSKeyID != "" || spec.BucketKey):
+		return nil, fmt.Errorf("encryption: sse is required with kms_key_id or bucket_key")
+	case spec.SSE == "":
+		return nil, nil
+	case spec.SSE != sseS3 && spec.SSE != sseKMS:
+		return nil, fmt.Errorf("encryption: unsupported sse '%s' (expected %s or %s)", spec.SSE, sseS3, sseKMS)
+	case spec.SSE != sseKMS && (spec.KMSKeyID != "" || spec.BucketKey):
+		return nil, fmt.Errorf("encryption: kms_key_id and bucket_key only apply to sse %s", sseKMS)
+	}
+	rule := ServerSideEncryptionRule{
+		ApplyServerSideEncryptionByDefault: ServerSideEncryptionByDefault{SSEAlgorithm: spec.SSE, KMSMasterKeyID: spec.KMSKeyID},
+		BucketKeyEnabled:                   spec.BucketKey,
+	}
+	return &ServerSideEncryptionConfiguration{Xmlns: s3XMLNamespace, Rules: []ServerSideEncryptionRule{rule}}, nil
+}
+
+// policyDocument retourne la politique déclarée en JSON : document YAML, ou texte JSON tel quel.
+// Un document vide ({} ou "") retourne nil : le bucket ne doit pas avoir de politique.
+func policyDocument(node *yaml.Node) ([]byte, error) {
+	if node.Kind == yaml.ScalarNode {
+		if strings.TrimSpace(node.Value) == "" {
+			return nil, nil
+		}
+		return []byte(node.Value), nil
+	}
+	if node.Kind == yaml.MappingNode && len(node.Content) == 0 {
+		return nil, nil
+	}
+	document, err := jsonValue(node)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(document)
+}
+
+// jsonValue convertit un nœud YAML en valeur JSON. Les dates restent des chaînes :
+// la Version d'une politique (2012-10-17) ne doit pas devenir un horodatage.
+func jsonValue(node *yaml.Node) (any, error) {
+	switch node.Kind {
+	case yaml.AliasNode:
+		return jsonValue(node.Alias)
+	case yaml.MappingNode:
+		document := map[string]any{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			value, err := jsonValue(node.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			document[node.Content[i].Value] = value
+		}
+		return document, nil
+	case yaml.SequenceNode:
+		list := []any{}
+		for _, item := range node.Content {
+			value, err := jsonValue(item)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		return list, nil
+	}
+	switch node.ShortTag() {
+	case "!!str", "!!timestamp":
+		return node.Value, nil
+	}
+	var value any
+	if err := node.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// sameSection compare deux sections par leur état et leur détail
+func sameSection(a, b bucketSection) bool {
+	return a.Status == b.Status && sectionText(a) == sectionText(b)
+}
+
+// sectionText est le détail d'une section en JSON indenté, vide si elle n'est pas configurée
+func sectionText(section bucketSection) string {
+	if section.Status != sectionConfigured {
+		return ""
+	}
+	text, err := formatJSON(section.Details)
+	if err != nil {
+		return ""
+	}
+	return text
+}
+
+// newBucketDescription décrit un bucket qui reste à créer : aucune section gérée n'est configurée
+func newBucketDescription(bucketName string) *BucketDescription {
+	return &BucketDescription{
+		Bucket:     bucketName,
+		Versioning: versioningSectionOf(&VersioningConfiguration{}),
+		Encryption: encryptionSectionOf(nil),
+		Policy:     policySectionOf(""),
+		CORS:       corsSectionOf(nil),
+		Lifecycle:  lifecycleSectionOf(nil),
+		Tags:       tagsSectionOf(nil),
+	}
+}
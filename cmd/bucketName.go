@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Préfixes et suffixes réservés par S3 dans les noms de bucket
+var (
+	reservedBucketPrefixes = []string{"xn--", "sthree-", "amzn-s3-demo-"}
+	reservedBucketSuffixes = []string{"-s3alias", "--ol-s3", ".mrap", "--x-s3", "--table-s3"}
+)
+
+// validateBucketName vérifie les règles de nommage des buckets S3 et explique la règle enfreinte
+func validateBucketName(name string) error {
+	invalid := func(format string, args ...any) error {
+		return fmt.Errorf("invalid bucket name '%s': %s", name, fmt.Sprintf(format, args...))
+	}
+
+	if len(name) < 3 || len(name) > 63 {
+		return invalid("must be between 3 and 63 characters long (got %d)", len(name))
+	}
+	for _, c := range name {
+		switch {
+		case c >= 'A' && c <= 'Z':
+			if validateBucketName(strings.ToLower(name)) == nil {
+				return invalid("uppercase letters are not allowed; use '%s'", strings.ToLower(name))
+			}
+			return invalid("uppercase letters are not allowed")
+		case c == '_':
+			return invalid("underscores are not allowed; use hyphens instead")
+		case !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '.' || c == '-'):
+			return invalid("character '%c' is not allowed (only lowercase letters, digits, dots and hyphens)", c)
+		}
+	}
+	if first, last := name[0], name[len(name)-1]; first == '.' || first == '-' || last == '.' || last == '-' {
+		return invalid("must begin and end with a letter or a digit")
+	}
+	if strings.Contains(name, "..") {
+		return invalid("must not contain two adjacent dots")
+	}
+	if strings.Contains(name, ".-") || strings.Contains(name, "-.") {
+		return invalid("a dot must not be next to a hyphen")
+	}
+	if net.ParseIP(name) != nil {
+		return invalid("must not be formatted as an IP address")
+	}
+	for _, prefix := range reservedBucketPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return invalid("the prefix '%s' is reserved", prefix)
+		}
+	}
+	for _, suffix := range reservedBucketSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return invalid("the suffix '%s' is reserved", suffix)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var catRange string
+
+// CatCmd représente la commande cat
+var CatCmd = &cobra.Command{
+	Use:   "cat <bucket-name> <object-key> | cat s3://bucket/key",
+	Short: "Streams an object, or a byte range of it, to standard output",
+	Long: `Streams an object to standard output without writing it to disk.
+--range selects a byte range: start-end (inclusive), start- (to the end) or -n (last n bytes).
+For example:
+
+bs3 cat s3://bucket/logs/app.log --range -4096 | less`,
+	Run: func(cmd *cobra.Command, args []string) {
+		bucketName, key, _, err := objectArgs(args)
+		if err != nil {
+			log.Printf("Usage: cat <bucket-name> <object-key> or cat s3://bucket/key (%v)", err)
+			return
+		}
+
+		var rangeHeader string
+		if catRange != "" {
+			if rangeHeader, err = parseByteRange(catRange); err != nil {
+				log.Printf("Error: %v", err)
+				return
+			}
+		}
+
+		// Récupérer l'URL de l'API depuis le profil actif
+		client, err := newConfiguredClient()
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+
+		req, err := client.newRequest(cmd.Context(), "GET", bucketName, key, nil, nil)
+		if err != nil {
+			log.Printf("Error creating request: %v", err)
+			return
+		}
+		if rangeHeader != "" {
+			req.Header.Set("Range", rangeHeader)
+		}
+		resp, err := client.do(req)
+		if err != nil {
+			log.Printf("Error making GET request: %v", err)
+			return
+		}
+		defer resp.Body.Close()
+
+		switch resp.StatusCode {
+		case http.StatusOK, http.StatusPartialContent:
+			if rangeHeader != "" && resp.StatusCode == http.StatusOK {
+				log.Printf("Warning: the server ignored the requested range; streaming the whole object")
+			}
+			// Le contenu est copié tel quel : aucun message ne doit se mêler à la sortie standard
+			if _, err := io.Copy(os.Stdout, resp.Body); err != nil {
+				log.Printf("Error: failed to stream object: %v", err)
+			}
+		case http.StatusNotFound:
+			log.Printf("Error: object '%s' not found in bucket '%s'", key, bucketName)
+		case http.StatusRequestedRangeNotSatisfiable:
+			log.Printf("Error: range '%s' is not satisfiable for object '%s'", catRange, key)
+		default:
+			log.Printf("Error: %v", responseError(resp))
+		}
+	},
+}
+
+// parseByteRange convertit une plage "début-fin", "début-" ou "-n" (n derniers octets),
+// avec ou sans préfixe bytes=, en valeur d'en-tête Range
+func parseByteRange(spec string) (string, error) {
+	value := strings.TrimPrefix(strings.TrimSpace(spec), "bytes=")
+	invalid := fmt.Errorf("invalid range '%s' (expected start-end, start- or -n, in bytes)", spec)
+
+	start, end, ok := strings.Cut(value, "-")
+	if !ok || (start == "" && end == "") {
+		return "", invalid
+	}
+	from, to := int64(-1), int64(-1)
+	var err error
+	if start != "" {
+		if from, err = strconv.ParseInt(start, 10, 64); err != nil || from < 0 {
+			return "", invalid
+		}
+	}
+	if end != "" {
+		if to, err = strconv.ParseInt(end, 10, 64); err != nil || to < 0 {
+			return "", invalid
+		}
+	}
+	if (from >= 0 && to >= 0 && to < from) || (start == "" && to == 0) {
+		return "", invalid
+	}
+	return "bytes=" + value, nil
+}
+
+func init() {
+	CatCmd.Flags().StringVar(&catRange, "range", "", "byte range to stream: start-end, start- or -n (last n bytes)")
+	RootCmd.AddCommand(CatCmd)
+}
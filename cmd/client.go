@@ -0,0 +1,277 @@
+package cmd
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// s3Client centralise l'adressage, la signature et l'envoi des requêtes vers l'API S3
+type s3Client struct {
+	profile *Profile
+	http    *http.Client
+
+	// bucketRegions mémorise les régions apprises via les redirections (x-amz-bucket-region)
+	mu            sync.Mutex
+	bucketRegions map[string]string
+}
+
+// requestTarget identifie le bucket et l'objet visés, pour re-router la requête après une redirection
+type requestTarget struct {
+	bucket string
+	key    string
+}
+
+type requestTargetKey struct{}
+
+// newS3Client construit un client pour le profil actif (--profile ou MYCLI_PROFILE)
+func newS3Client() (*s3Client, error) {
+	profile, err := loadProfile(viper.GetString("profile"))
+	if err != nil {
+		return nil, err
+	}
+	transport, err := sharedTransport(profile)
+	if err != nil {
+		return nil, err
+	}
+	return &s3Client{
+		profile: profile,
+		http: &http.Client{
+			Transport: transport,
+			// Les redirections S3 sont traitées par do() afin de re-signer la requête
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+		bucketRegions: map[string]string{},
+	}, nil
+}
+
+// newConfiguredClient crée le client du profil actif et vérifie que l'URL de l'API est renseignée
+func newConfiguredClient() (*s3Client, error) {
+	client, err := newS3Client()
+	if err != nil {
+		return nil, err
+	}
+	if client.profile.APIURL == "" {
+		return nil, fmt.Errorf("API URL is not configured. Please set it in the config file or environment variables")
+	}
+	return client, nil
+}
+
+// newRequest prépare une requête vers un bucket et/ou un objet selon le style d'adressage du profil.
+// Un bucket vide désigne le service lui-même (liste des buckets).
+// La requête est rattachée au contexte de la commande : son annulation (Ctrl-C) interrompt l'envoi.
+func (c *s3Client) newRequest(ctx context.Context, method, bucket, key string, query url.Values, body io.Reader) (*http.Request, error) {
+	u, err := c.objectURL(bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	if len(query) > 0 {
+		u.RawQuery = query.Encode()
+	}
+
+	ctx = context.WithValue(ctx, requestTargetKey{}, requestTarget{bucket: bucket, key: key})
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s request: %w", method, err)
+	}
+	return req, nil
+}
+
+// do signe la requête avec les identifiants du profil puis l'envoie, en réessayant les erreurs transitoires.
+// Une redirection 301/307 portant x-amz-bucket-region est rejouée vers l'endpoint de cette région.
+func (c *s3Client) do(req *http.Request) (*http.Response, error) {
+	resp, err := c.sendWithRetry(req)
+	if err != nil {
+		return nil, err
+	}
+
+	target, _ := req.Context().Value(requestTargetKey{}).(requestTarget)
+	region := resp.Header.Get("X-Amz-Bucket-Region")
+	redirected := resp.StatusCode == http.StatusMovedPermanently || resp.StatusCode == http.StatusTemporaryRedirect
+	if !redirected || target.bucket == "" || region == "" || region == c.regionFor(target.bucket) {
+		return resp, nil
+	}
+	resp.Body.Close()
+
+	c.mu.Lock()
+	c.bucketRegions[target.bucket] = region
+	c.mu.Unlock()
+
+	retry, err := c.redirectRequest(req, target, region)
+	if err != nil {
+		return nil, err
+	}
+	return c.sendWithRetry(retry)
+}
+
+// send résout les identifiants, signe pour la région du bucket et envoie la requête.
+// Les identifiants temporaires sont résolus à chaque appel pour être renouvelés à temps.
+func (c *s3Client) send(req *http.Request) (*http.Response, error) {
+	creds, err := resolveCredentials(req.Context(), c.profile)
+	if err != nil {
+		return nil, err
+	}
+	if creds != nil {
+		payloadHash, err := requestPayloadHash(req)
+		if err != nil {
+			return nil, err
+		}
+		target, _ := req.Context().Value(requestTargetKey{}).(requestTarget)
+		newSigner(creds, c.regionFor(target.bucket), "s3").sign(req, payloadHash, time.Now())
+	}
+	return c.http.Do(req)
+}
+
+// redirectRequest reconstruit la requête vers l'endpoint de la région du bucket
+func (c *s3Client) redirectRequest(req *http.Request, target requestTarget, region string) (*http.Request, error) {
+	u, err := c.objectURL(target.bucket, target.key)
+	if err != nil {
+		return nil, err
+	}
+	u.RawQuery = req.URL.RawQuery
+
+	retry := req.Clone(req.Context())
+	retry.URL = u
+	retry.Host = ""
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return nil, fmt.Errorf("bucket '%s' is in region '%s' and the request body cannot be replayed; set region: %s in the profile", target.bucket, region, region)
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, fmt.Errorf("failed to replay request body: %w", err)
+		}
+		retry.Body = body
+	}
+	return retry, nil
+}
+
+// regionFor retourne la région connue du bucket, ou celle du profil
+func (c *s3Client) regionFor(bucket string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if region, ok := c.bucketRegions[bucket]; ok {
+		return region
+	}
+	return c.profile.Region
+}
+
+// requestPayloadHash calcule le SHA-256 du corps quand il est relisible, sinon UNSIGNED-PAYLOAD.
+// Un appelant qui rejoue un gros corps (un fichier) demande UNSIGNED-PAYLOAD pour ne pas le relire.
+func requestPayloadHash(req *http.Request) (string, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return emptyPayloadHash, nil
+	}
+	if req.GetBody == nil || req.Header.Get("X-Amz-Content-Sha256") == unsignedPayload {
+		return unsignedPayload, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return "", fmt.Errorf("failed to read request body: %w", err)
+	}
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read request body: %w", err)
+	}
+	return hashSHA256(data), nil
+}
+
+// S3Error est une réponse en échec de l'API S3, avec son code d'erreur quand le corps en contient un
+type S3Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+func (e *S3Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("status code %d", e.StatusCode)
+	}
+	return fmt.Sprintf("%s: %s (status %d)", e.Code, e.Message, e.StatusCode)
+}
+
+// responseError lit le corps d'une réponse en échec et le convertit en *S3Error
+func responseError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	s3Err := &S3Error{StatusCode: resp.StatusCode}
+	var parsed S3ErrorResponse
+	if xml.Unmarshal(body, &parsed) == nil {
+		s3Err.Code, s3Err.Message = parsed.Code, parsed.Message
+	}
+	return s3Err
+}
+
+// bucketSubresources associe les sous-ressources S3 au nom utilisé dans les opérations
+var bucketSubresources = []struct{ query, name string }{
+	{"versioning", "Versioning"},
+	{"tagging", "Tagging"},
+	{"policy", "Policy"},
+	{"cors", "Cors"},
+	{"lifecycle", "Lifecycle"},
+	{"acl", "Acl"},
+	{"encryption", "Encryption"},
+	{"object-lock", "ObjectLockConfiguration"},
+	{"retention", "Retention"},
+	{"legal-hold", "LegalHold"},
+	{"location", "Location"},
+}
+
+// operationName déduit le nom de l'opération S3 (ex. GetObject, PutBucketVersioning),
+// utilisé pour choisir son délai (timeouts.<Opération>)
+func operationName(method string, target requestTarget, query url.Values, header http.Header) string {
+	switch {
+	case target.bucket == "":
+		return "ListBuckets"
+	case query.Has("delete"):
+		return "DeleteObjects"
+	case query.Has("versions"):
+		return "ListObjectVersions"
+	case query.Has("uploads") && method == "POST":
+		return "CreateMultipartUpload"
+	case query.Has("uploads"):
+		return "ListMultipartUploads"
+	case query.Has("uploadId"):
+		switch method {
+		case "PUT":
+			return "UploadPart"
+		case "POST":
+			return "CompleteMultipartUpload"
+		case "DELETE":
+			return "AbortMultipartUpload"
+		}
+		return "ListParts"
+	}
+
+	verbs := map[string]string{"GET": "Get", "PUT": "Put", "DELETE": "Delete", "HEAD": "Head", "POST": "Post", "OPTIONS": "Options"}
+	scope := "Bucket"
+	if target.key != "" {
+		scope = "Object"
+	}
+	for _, sub := range bucketSubresources {
+		if query.Has(sub.query) {
+			return verbs[method] + scope + sub.name
+		}
+	}
+
+	if target.key == "" {
+		switch method {
+		case "GET":
+			return "ListObjects"
+		case "PUT":
+			return "CreateBucket"
+		}
+		return verbs[method] + "Bucket"
+	}
+	if method == "PUT" && header.Get("X-Amz-Copy-Source") != "" {
+		return "CopyObject"
+	}
+	return verbs[method] + "Object"
+}
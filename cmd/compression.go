@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Algorithmes de compression proposés par upload-file --compress
+const (
+	compressGzip = "gzip"
+	compressZstd = "zstd"
+)
+
+// En-têtes de métadonnées décrivant la compression d'un objet
+const (
+	metaCompression  = "X-Amz-Meta-Bs3-Compression"
+	metaOriginalSize = "X-Amz-Meta-Bs3-Original-Size"
+)
+
+// parseCompression valide la valeur de --compress
+func parseCompression(value string) (string, error) {
+	switch algorithm := strings.ToLower(value); algorithm {
+	case compressGzip, compressZstd:
+		return algorithm, nil
+	default:
+		return "", fmt.Errorf("unsupported compression '%s' (expected gzip or zstd)", value)
+	}
+}
+
+// compressedExtension est l'extension usuelle d'un fichier compressé avec l'algorithme
+func compressedExtension(algorithm string) string {
+	if algorithm == compressZstd {
+		return ".zst"
+	}
+	return ".gz"
+}
+
+// compressStream retourne le flux compressé de src ; la compression s'effectue au fil de la lecture
+// dans une goroutine, et une erreur de lecture de src est transmise au lecteur. Fermer le flux
+// interrompt la compression.
+func compressStream(algorithm string, src io.Reader) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		var writer io.WriteCloser
+		if algorithm == compressZstd {
+			encoder, err := zstd.NewWriter(pw)
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+			writer = encoder
+		} else {
+			writer = gzip.NewWriter(pw)
+		}
+		_, err := io.Copy(writer, src)
+		if closeErr := writer.Close(); err == nil {
+			err = closeErr
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr
+}
+
+// decompressStream retourne le contenu décompressé de src
+func decompressStream(algorithm string, src io.Reader) (io.ReadCloser, error) {
+	switch algorithm {
+	case compressGzip:
+		return gzip.NewReader(src)
+	case compressZstd:
+		decoder, err := zstd.NewReader(src)
+		if err != nil {
+			return nil, err
+		}
+		return decoder.IOReadCloser(), nil
+	default:
+		return nil, fmt.Errorf("unsupported compression '%s'", algorithm)
+	}
+}
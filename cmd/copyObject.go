@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	copyVersionID     string
+	copySSE           sseFlags
+	copySourceKeyFile string
+	copySourceKeyEnv  string
+)
+
+// CopyObjectResult représente la réponse de CopyObject
+type CopyObjectResult struct {
+	ETag         string `xml:"ETag"`
+	LastModified string `xml:"LastModified"`
+}
+
+// copyResult décrit l'objet créé par une copie
+type copyResult struct {
+	ETag       string
+	VersionID  string
+	Encryption string
+}
+
+// CopyObjectCmd représente la commande copy-object
+var CopyObjectCmd = &cobra.Command{
+	Use:   "copy-object <source> <destination>",
+	Short: "Copies an object, or one of its versions, on the server side",
+	Long: `Copies an object without downloading it. Source and destination are given as
+s3://bucket/key or as <bucket-name> <object-key> pairs.
+For example:
+
+bs3 copy-object s3://photos/2024/cat.jpg s3://backup/cat.jpg --version-id 3HL4kqtJlcpXroDTDmJ
+
+The copy is encrypted as requested by --sse or --sse-c-key-file/--sse-c-key-env; a source
+encrypted with a customer key (SSE-C) needs --source-sse-c-key-file or --source-sse-c-key-env.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		srcBucket, srcKey, rest, err := objectArgs(args)
+		if err != nil {
+			log.Printf("Usage: copy-object <source> <destination> (%v)", err)
+			return
+		}
+		dstBucket, dstKey, _, err := objectArgs(rest)
+		if err != nil {
+			log.Printf("Usage: copy-object <source> <destination> (%v)", err)
+			return
+		}
+
+		client, err := newConfiguredClient()
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+
+		// Chiffrement de la copie et clé SSE-C éventuelle de la source
+		sse, err := copySSE.options()
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		sourceKey, err := loadCustomerKey(copySourceKeyFile, copySourceKeyEnv)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		source := sseOptions{customerKey: sourceKey}
+		header := http.Header{}
+		sse.apply(header)
+		source.applyCopySource(header)
+		sse.warnPlainHTTP(client)
+		source.warnPlainHTTP(client)
+
+		result, err := copyObject(cmd.Context(), client, srcBucket, srcKey, copyVersionID, dstBucket, dstKey, header)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		fmt.Printf("Copied s3://%s/%s to s3://%s/%s.\n", srcBucket, srcKey, dstBucket, dstKey)
+		if result.VersionID != "" {
+			fmt.Printf("New version: %s\n", result.VersionID)
+		}
+		if result.Encryption != "" {
+			fmt.Printf("Encryption: %s\n", result.Encryption)
+		}
+	},
+}
+
+// copySource construit l'en-tête X-Amz-Copy-Source (/bucket/clé, suivi de ?versionId=)
+func copySource(bucket, key, versionID string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	source := "/" + bucket + "/" + strings.Join(segments, "/")
+	if versionID != "" {
+		source += "?versionId=" + url.QueryEscape(versionID)
+	}
+	return source
+}
+
+// copyObject copie l'objet source (ou sa version versionID) vers la destination.
+// S3 peut signaler un échec dans une réponse 200 : le corps est donc toujours vérifié.
+func copyObject(ctx context.Context, client *s3Client, srcBucket, srcKey, versionID, dstBucket, dstKey string, header http.Header) (*copyResult, error) {
+	req, err := client.newRequest(ctx, "PUT", dstBucket, dstKey, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("X-Amz-Copy-Source", copySource(srcBucket, srcKey, versionID))
+
+	resp, err := client.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		if s3Err := responseError(resp).(*S3Error); s3Err.Code == "NoSuchBucket" {
+			return nil, s3Err
+		}
+		if versionID != "" {
+			return nil, fmt.Errorf("version '%s' of object '%s' not found in bucket '%s'", versionID, srcKey, srcBucket)
+		}
+		return nil, fmt.Errorf("object '%s' not found in bucket '%s'", srcKey, srcBucket)
+	case resp.StatusCode >= 300:
+		return nil, responseError(resp)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if bytes.Contains(body, []byte("<Error>")) {
+		var s3Err S3ErrorResponse
+		xml.Unmarshal(body, &s3Err)
+		return nil, &S3Error{StatusCode: resp.StatusCode, Code: s3Err.Code, Message: s3Err.Message}
+	}
+	var parsed CopyObjectResult
+	xml.Unmarshal(body, &parsed)
+	return &copyResult{ETag: parsed.ETag, VersionID: resp.Header.Get("X-Amz-Version-Id"), Encryption: describeEncryption(resp.Header)}, nil
+}
+
+func init() {
+	CopyObjectCmd.Flags().StringVar(&copyVersionID, "version-id", "", "copy a specific version of the source object")
+	addSSEFlags(CopyObjectCmd, &copySSE, true)
+	CopyObjectCmd.Flags().StringVar(&copySourceKeyFile, "source-sse-c-key-file", "", "file holding the SSE-C customer key of the source object")
+	CopyObjectCmd.Flags().StringVar(&copySourceKeyEnv, "source-sse-c-key-env", "", "environment variable holding the SSE-C customer key of the source object (base64)")
+	RootCmd.AddCommand(CopyObjectCmd)
+}
@@ -0,0 +1,267 @@
+package cmd
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	corsRawXML     bool
+	corsTestOrigin string
+	corsTestMethod string
+	corsTestHeader []string
+	corsTestKey    string
+)
+
+// CORSCmd regroupe les sous-commandes de gestion de la configuration CORS d'un bucket
+var CORSCmd = &cobra.Command{
+	Use:   "cors",
+	Short: "Reads, replaces, deletes or tests the CORS configuration of a bucket",
+}
+
+var corsGetCmd = &cobra.Command{
+	Use:   "get <bucket-name>",
+	Short: "Prints the CORS rules of a bucket (YAML, or the S3 XML with --xml)",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 {
+			log.Println("Usage: cors get <bucket-name> [--xml]")
+			return
+		}
+		bucketName := args[0]
+		client, err := newConfiguredClient()
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+
+		config, raw, err := getCORS(cmd.Context(), client, bucketName)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		if config == nil {
+			fmt.Printf("No CORS configuration on bucket '%s'.\n", bucketName)
+			return
+		}
+		if corsRawXML {
+			fmt.Println(string(raw))
+			return
+		}
+		text, err := corsSpecYAML(config)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		fmt.Print(text)
+	},
+}
+
+var corsPutCmd = &cobra.Command{
+	Use:   "put <bucket-name> <rules-file|->",
+	Short: "Replaces the CORS rules of a bucket from a YAML, JSON or S3 XML file",
+	Long: `Replaces the CORS rules of a bucket. The file is either the S3 CORSConfiguration XML or
+a friendlier YAML/JSON document:
+
+rules:
+  - id: web-app
+    origins: [https://app.example.com]
+    methods: [GET, HEAD, PUT]
+    headers: ["*"]
+    expose: [ETag]
+    max_age: 3600
+
+Use - to read the rules from standard input.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 2 {
+			log.Println("Usage: cors put <bucket-name> <rules-file|->")
+			return
+		}
+		bucketName := args[0]
+
+		var data []byte
+		var err error
+		if args[1] == "-" {
+			data, err = io.ReadAll(cmd.InOrStdin())
+		} else {
+			data, err = os.ReadFile(args[1])
+		}
+		if err != nil {
+			log.Printf("Error reading CORS rules: %v", err)
+			return
+		}
+		config, err := parseCORSConfig(data)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		body, err := xml.Marshal(config)
+		if err != nil {
+			log.Printf("Error marshalling XML: %v", err)
+			return
+		}
+
+		client, err := newConfiguredClient()
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		if err := client.putSubresource(cmd.Context(), bucketName, "", "cors", nil, body, nil); err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		fmt.Printf("CORS configuration of bucket '%s' replaced (%d rule(s)).\n", bucketName, len(config.Rules))
+	},
+}
+
+var corsDeleteCmd = &cobra.Command{
+	Use:   "delete <bucket-name>",
+	Short: "Deletes the CORS configuration of a bucket",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 {
+			log.Println("Usage: cors delete <bucket-name>")
+			return
+		}
+		bucketName := args[0]
+		client, err := newConfiguredClient()
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+
+		if err := client.deleteSubresource(cmd.Context(), bucketName, "", "cors", nil); err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		fmt.Printf("CORS configuration deleted from bucket '%s'.\n", bucketName)
+	},
+}
+
+var corsTestCmd = &cobra.Command{
+	Use:   "test <bucket-name> --origin <origin> --method <method>",
+	Short: "Sends a browser-like OPTIONS preflight and explains whether it would pass",
+	Long: `Sends the OPTIONS preflight request a browser would send before a cross-origin request,
+without signing it, then explains the result against the bucket's CORS rules.
+For example:
+
+bs3 cors test assets --origin https://app.example.com --method PUT --header content-type --key uploads/a.png`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 || corsTestOrigin == "" {
+			log.Println("Usage: cors test <bucket-name> --origin <origin> [--method <method>] [--header <name>]... [--key <object-key>]")
+			return
+		}
+		bucketName := args[0]
+		method := strings.ToUpper(corsTestMethod)
+		client, err := newConfiguredClient()
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+
+		// Requête préliminaire non signée, comme celle d'un navigateur : envoyée sans client.do,
+		// elle garde le délai de l'opération (timeouts.OptionsObject)
+		key := corsTestKey
+		if key == "" {
+			key = "cors-preflight-test"
+		}
+		ctx, cancel := cmd.Context(), context.CancelFunc(func() {})
+		if timeout := client.operationTimeout("OptionsObject"); timeout > 0 {
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+		}
+		defer cancel()
+		req, err := client.newRequest(ctx, "OPTIONS", bucketName, key, nil, nil)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		req.Header.Set("Origin", corsTestOrigin)
+		req.Header.Set("Access-Control-Request-Method", method)
+		if len(corsTestHeader) > 0 {
+			req.Header.Set("Access-Control-Request-Headers", strings.ToLower(strings.Join(corsTestHeader, ", ")))
+		}
+		resp, err := client.http.Do(req)
+		if err != nil {
+			log.Printf("Error sending preflight request: %v", err)
+			return
+		}
+		resp.Body.Close()
+
+		allowOrigin := resp.Header.Get("Access-Control-Allow-Origin")
+		passed := resp.StatusCode == http.StatusOK && allowOrigin != ""
+		fmt.Printf("Preflight from %s for %s on s3://%s/%s: ", corsTestOrigin, method, bucketName, key)
+		if passed {
+			fmt.Printf("ALLOWED (HTTP %d)\n", resp.StatusCode)
+			fmt.Printf("  Access-Control-Allow-Origin:  %s\n", allowOrigin)
+			fmt.Printf("  Access-Control-Allow-Methods: %s\n", resp.Header.Get("Access-Control-Allow-Methods"))
+			if value := resp.Header.Get("Access-Control-Allow-Headers"); value != "" {
+				fmt.Printf("  Access-Control-Allow-Headers: %s\n", value)
+			}
+			if value := resp.Header.Get("Access-Control-Expose-Headers"); value != "" {
+				fmt.Printf("  Access-Control-Expose-Headers: %s\n", value)
+			}
+			if value := resp.Header.Get("Access-Control-Max-Age"); value != "" {
+				fmt.Printf("  Access-Control-Max-Age: %s (the browser caches this answer)\n", value)
+			}
+		} else {
+			fmt.Printf("DENIED (HTTP %d)\n", resp.StatusCode)
+		}
+
+		// Expliquer le résultat à partir des règles du bucket
+		config, _, err := getCORS(cmd.Context(), client, bucketName)
+		if err != nil {
+			log.Printf("Warning: cannot read the CORS rules to explain the result: %v", err)
+			return
+		}
+		if config == nil {
+			fmt.Printf("Bucket '%s' has no CORS configuration: every preflight request is rejected.\n", bucketName)
+			return
+		}
+		fmt.Println("Rule evaluation (the first matching rule applies):")
+		matched := false
+		for i, rule := range config.Rules {
+			reason := corsRuleMismatch(rule, corsTestOrigin, method, corsTestHeader)
+			if reason == "" {
+				fmt.Printf("  - %s: matches\n", corsRuleName(i, rule))
+				matched = true
+				break
+			}
+			fmt.Printf("  - %s: %s\n", corsRuleName(i, rule), reason)
+		}
+		if matched != passed {
+			fmt.Println("Note: the endpoint's answer differs from the local evaluation of the rules; it may not implement CORS like S3.")
+		}
+	},
+}
+
+// getCORS lit la configuration CORS du bucket ; nil (sans erreur) si le bucket n'en a pas
+func getCORS(ctx context.Context, client *s3Client, bucketName string) (*CORSConfiguration, []byte, error) {
+	data, err := client.getSubresource(ctx, bucketName, "", "cors", nil)
+	if s3Err, ok := err.(*S3Error); ok && s3Err.Code == "NoSuchCORSConfiguration" {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	var config CORSConfiguration
+	if err := xml.Unmarshal(data, &config); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse CORS configuration: %w", err)
+	}
+	return &config, data, nil
+}
+
+func init() {
+	corsGetCmd.Flags().BoolVar(&corsRawXML, "xml", false, "print the S3 CORSConfiguration XML as returned by the server")
+	corsTestCmd.Flags().StringVar(&corsTestOrigin, "origin", "", "origin of the page making the request (e.g. https://app.example.com)")
+	corsTestCmd.Flags().StringVar(&corsTestMethod, "method", "GET", "HTTP method of the cross-origin request")
+	corsTestCmd.Flags().StringArrayVar(&corsTestHeader, "header", nil, "request header the page will send (repeatable)")
+	corsTestCmd.Flags().StringVar(&corsTestKey, "key", "", "object key targeted by the request (default: a placeholder key)")
+	CORSCmd.AddCommand(corsGetCmd, corsPutCmd, corsDeleteCmd, corsTestCmd)
+	RootCmd.AddCommand(CORSCmd)
+}
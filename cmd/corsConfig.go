@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Limites de S3 sur les règles CORS
+const maxCORSRules = 100
+
+var corsMethods = []string{"GET", "PUT", "POST", "DELETE", "HEAD"}
+
+// CORSRule représente une règle de la configuration CORS S3
+type CORSRule struct {
+	ID             string   `xml:"ID,omitempty"`
+	AllowedOrigins []string `xml:"AllowedOrigin"`
+	AllowedMethods []string `xml:"AllowedMethod"`
+	AllowedHeaders []string `xml:"AllowedHeader,omitempty"`
+	ExposeHeaders  []string `xml:"ExposeHeader,omitempty"`
+	MaxAgeSeconds  int      `xml:"MaxAgeSeconds,omitempty"`
+}
+
+// CORSConfiguration représente le document XML de la sous-ressource ?cors
+type CORSConfiguration struct {
+	XMLName xml.Name   `xml:"CORSConfiguration"`
+	Xmlns   string     `xml:"xmlns,attr,omitempty"`
+	Rules   []CORSRule `xml:"CORSRule"`
+}
+
+// corsRuleSpec est la forme YAML/JSON d'une règle, plus lisible que le XML S3
+type corsRuleSpec struct {
+	ID      string   `yaml:"id,omitempty" json:"id,omitempty"`
+	Origins []string `yaml:"origins" json:"origins"`
+	Methods []string `yaml:"methods" json:"methods"`
+	Headers []string `yaml:"headers,omitempty" json:"headers,omitempty"`
+	Expose  []string `yaml:"expose,omitempty" json:"expose,omitempty"`
+	MaxAge  int      `yaml:"max_age,omitempty" json:"max_age,omitempty"`
+}
+
+type corsSpec struct {
+	Rules []corsRuleSpec `yaml:"rules" json:"rules"`
+}
+
+// parseCORSConfig lit une configuration CORS : XML S3 (document commençant par "<"),
+// ou YAML/JSON sous la forme {rules: [...]} ou d'une simple liste de règles
+func parseCORSConfig(data []byte) (*CORSConfiguration, error) {
+	trimmed := bytes.TrimSpace(data)
+	config := &CORSConfiguration{Xmlns: s3XMLNamespace}
+
+	if bytes.HasPrefix(trimmed, []byte("<")) {
+		if err := xml.Unmarshal(trimmed, config); err != nil {
+			return nil, fmt.Errorf("invalid CORS XML: %w", err)
+		}
+		config.Xmlns = s3XMLNamespace
+	} else {
+		var spec corsSpec
+		decoder := yaml.NewDecoder(bytes.NewReader(trimmed))
+		decoder.KnownFields(true)
+		if bytes.HasPrefix(trimmed, []byte("-")) || bytes.HasPrefix(trimmed, []byte("[")) {
+			err := decoder.Decode(&spec.Rules)
+			if err != nil {
+				return nil, fmt.Errorf("invalid CORS rules: %w", err)
+			}
+		} else if err := decoder.Decode(&spec); err != nil {
+			return nil, fmt.Errorf("invalid CORS rules: %w", err)
+		}
+		config = spec.configuration()
+	}
+
+	if err := validateCORSConfig(config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// validateCORSConfig applique les règles de S3 : origines et méthodes obligatoires,
+// méthodes reconnues, un seul joker par origine ou en-tête
+func validateCORSConfig(config *CORSConfiguration) error {
+	if len(config.Rules) == 0 {
+		return fmt.Errorf("the CORS configuration must contain at least one rule")
+	}
+	if len(config.Rules) > maxCORSRules {
+		return fmt.Errorf("the CORS configuration can contain at most %d rules (got %d)", maxCORSRules, len(config.Rules))
+	}
+	for i := range config.Rules {
+		rule := &config.Rules[i]
+		name := corsRuleName(i, *rule)
+		if len(rule.AllowedOrigins) == 0 {
+			return fmt.Errorf("%s: at least one origin is required", name)
+		}
+		if len(rule.AllowedMethods) == 0 {
+			return fmt.Errorf("%s: at least one method is required", name)
+		}
+		for j, method := range rule.AllowedMethods {
+			rule.AllowedMethods[j] = strings.ToUpper(method)
+			if !slices.Contains(corsMethods, rule.AllowedMethods[j]) {
+				return fmt.Errorf("%s: unsupported method '%s' (expected %s)", name, method, strings.Join(corsMethods, ", "))
+			}
+		}
+		for _, value := range append(append([]string{}, rule.AllowedOrigins...), rule.AllowedHeaders...) {
+			if strings.Count(value, "*") > 1 {
+				return fmt.Errorf("%s: '%s' can contain at most one '*' wildcard", name, value)
+			}
+		}
+		if rule.MaxAgeSeconds < 0 {
+			return fmt.Errorf("%s: max_age cannot be negative", name)
+		}
+	}
+	return nil
+}
+
+// corsSpecYAML convertit la configuration dans sa forme YAML lisible
+func corsSpecYAML(config *CORSConfiguration) (string, error) {
+	return marshalYAML(corsSpecOf(config))
+}
+
+// corsSpecOf convertit la configuration S3 dans sa forme lisible (YAML ou JSON)
+func corsSpecOf(config *CORSConfiguration) corsSpec {
+	spec := corsSpec{Rules: []corsRuleSpec{}}
+	for _, rule := range config.Rules {
+		spec.Rules = append(spec.Rules, corsRuleSpec{
+			ID:      rule.ID,
+			Origins: rule.AllowedOrigins,
+			Methods: rule.AllowedMethods,
+			Headers: rule.AllowedHeaders,
+			Expose:  rule.ExposeHeaders,
+			MaxAge:  rule.MaxAgeSeconds,
+		})
+	}
+	return spec
+}
+
+// configuration convertit les règles lisibles en configuration S3, sans la valider
+func (spec corsSpec) configuration() *CORSConfiguration {
+	config := &CORSConfiguration{Xmlns: s3XMLNamespace}
+	for _, rule := range spec.Rules {
+		config.Rules = append(config.Rules, CORSRule{
+			ID:             rule.ID,
+			AllowedOrigins: rule.Origins,
+			AllowedMethods: rule.Methods,
+			AllowedHeaders: rule.Headers,
+			ExposeHeaders:  rule.Expose,
+			MaxAgeSeconds:  rule.MaxAge,
+		})
+	}
+	return config
+}
+
+// marshalYAML encode un document YAML indenté de deux espaces, comme les exemples de la documentation
+func marshalYAML(document any) (string, error) {
+	var out strings.Builder
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(document); err != nil {
+		return "", err
+	}
+	if err := encoder.Close(); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+func corsRuleName(i int, rule CORSRule) string {
+	if rule.ID != "" {
+		return fmt.Sprintf("rule %d (%s)", i+1, rule.ID)
+	}
+	return fmt.Sprintf("rule %d", i+1)
+}
+
+// wildcardMatch compare une valeur à un motif contenant au plus un "*" (origines, en-têtes)
+func wildcardMatch(pattern, value string, caseSensitive bool) bool {
+	if !caseSensitive {
+		pattern, value = strings.ToLower(pattern), strings.ToLower(value)
+	}
+	before, after, found := strings.Cut(pattern, "*")
+	if !found {
+		return pattern == value
+	}
+	return len(value) >= len(before)+len(after) && strings.HasPrefix(value, before) && strings.HasSuffix(value, after)
+}
+
+// corsRuleMismatch explique pourquoi la règle ne s'applique pas à la requête préliminaire,
+// ou retourne "" si elle l'autorise. Comme S3, la première règle qui correspond est retenue.
+func corsRuleMismatch(rule CORSRule, origin, method string, headers []string) string {
+	originOK := slices.ContainsFunc(rule.AllowedOrigins, func(pattern string) bool { return wildcardMatch(pattern, origin, true) })
+	if !originOK {
+		return fmt.Sprintf("origin '%s' is not allowed (allowed: %s)", origin, allowedList(rule.AllowedOrigins))
+	}
+	if !slices.Contains(rule.AllowedMethods, method) {
+		return fmt.Sprintf("method %s is not allowed (allowed: %s)", method, allowedList(rule.AllowedMethods))
+	}
+	for _, header := range headers {
+		allowed := slices.ContainsFunc(rule.AllowedHeaders, func(pattern string) bool { return wildcardMatch(pattern, header, false) })
+		if !allowed {
+			return fmt.Sprintf("request header '%s' is not allowed (allowed: %s)", header, allowedList(rule.AllowedHeaders))
+		}
+	}
+	return ""
+}
+
+func allowedList(values []string) string {
+	if len(values) == 0 {
+		return "none"
+	}
+	return strings.Join(values, ", ")
+}
@@ -1,85 +1,239 @@
 package cmd
 
 import (
-    "fmt"
-    "net/http"
-    "log"
-    "time"
-    "io"
-    "github.com/spf13/cobra"
-    "github.com/spf13/viper"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// CreateBucketConfiguration représente le corps de création d'un bucket hors de la région par défaut
+type CreateBucketConfiguration struct {
+	XMLName            xml.Name `xml:"CreateBucketConfiguration"`
+	Xmlns              string   `xml:"xmlns,attr,omitempty"`
+	LocationConstraint string   `xml:"LocationConstraint"`
+}
+
+// bucketCannedACLs sont les ACL prédéfinies applicables à un bucket
+var bucketCannedACLs = []string{"private", "public-read", "public-read-write", "authenticated-read"}
+
+// createBucketOptions regroupe les options de create-bucket ; Object Lock ne peut être activé qu'à la création
+type createBucketOptions struct {
+	region     string
+	acl        string
+	objectLock bool
+}
+
+// bucketConflictError est un refus 409 : le nom est déjà pris (par nous ou par un autre compte)
+type bucketConflictError struct {
+	code    string
+	message string
+}
+
+func (e *bucketConflictError) Error() string {
+	return e.message
+}
+
+var (
+	createBucketRegion      string
+	createBucketACL         string
+	createBucketObjectLock  bool
+	createBucketVersioning  bool
+	createBucketIfNotExists bool
 )
 
 // createBucketCmd représente la commande create-bucket
 var createBucketCmd = &cobra.Command{
-    Use:   "create-bucket",
-    Short: "Create a new S3 bucket via the API",
-    Run: func(cmd *cobra.Command, args []string) {
-        if len(args) < 1 {
-            log.Println("Error: Bucket name is required")
-            return
-        }
-
-        bucketName := args[0]
-
-        // Utilisation de Viper pour récupérer l'URL de l'API depuis une variable d'environnement ou un fichier de config
-        apiURL := viper.GetString("s3.api_url")
-        if apiURL == "" {
-            log.Println("Error: S3 API URL is not configured. Please set it in the config file or environment variables.")
-            return
-        }
-
-        // URL complète pour créer le bucket
-        url := fmt.Sprintf("%s/%s/", apiURL, bucketName)
-
-        // Appel pour créer le bucket
-        if err := createBucket(url); err != nil {
-            fmt.Printf("%v", err)
-        } else {
-            fmt.Printf("Bucket '%s' created successfully.\n", bucketName)
-        }
-    },
+	Use:   "create-bucket",
+	Short: "Create a new S3 bucket via the API",
+	Long: `Creates a bucket. The name is checked against the S3 naming rules before any request.
+For example:
+
+bs3 create-bucket invoices-2024 --region eu-west-3 --versioning
+bs3 create-bucket records --object-lock --if-not-exists`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 {
+			log.Println("Error: Bucket name is required")
+			return
+		}
+
+		bucketName := args[0]
+
+		// Vérifier le nom et les options avant tout appel
+		if err := validateBucketName(bucketName); err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		opts := createBucketOptions{region: createBucketRegion, acl: createBucketACL, objectLock: createBucketObjectLock}
+		if opts.acl != "" && !slices.Contains(bucketCannedACLs, opts.acl) {
+			log.Printf("Error: unsupported canned ACL '%s' (expected one of %s)", opts.acl, strings.Join(bucketCannedACLs, ", "))
+			return
+		}
+
+		// Client S3 du profil actif (URL de l'API et identifiants)
+		client, err := newConfiguredClient()
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+
+		// Appel pour créer le bucket
+		err = createBucket(cmd.Context(), client, bucketName, opts)
+		var conflict *bucketConflictError
+		if errors.As(err, &conflict) && createBucketIfNotExists {
+			// Idempotent : un bucket existant qui nous appartient n'est pas une erreur, et il est laissé tel quel
+			if owned, ownErr := ownsBucket(cmd.Context(), client, bucketName, conflict); ownErr != nil {
+				log.Printf("Error: %v", ownErr)
+			} else if owned {
+				fmt.Printf("Bucket '%s' already exists and is yours; left unchanged (--if-not-exists).\n", bucketName)
+			} else {
+				log.Printf("Error: bucket name '%s' is already taken by another account (bucket names are global); choose another name", bucketName)
+			}
+			return
+		}
+		if err != nil {
+			fmt.Println(strings.TrimSpace(err.Error()))
+			return
+		}
+
+		fmt.Printf("Bucket '%s' created successfully.\n", bucketName)
+		if opts.region != "" {
+			fmt.Printf("Region: %s\n", opts.region)
+		}
+		if opts.acl != "" {
+			fmt.Printf("ACL: %s\n", opts.acl)
+		}
+		if opts.objectLock {
+			fmt.Println("Object Lock enabled (versioning is enabled with it and cannot be suspended).")
+		} else if createBucketVersioning {
+			// Le versioning se règle après la création ; un échec laisse le bucket créé
+			body, err := xml.Marshal(VersioningConfiguration{Xmlns: s3XMLNamespace, Status: "Enabled"})
+			if err == nil {
+				err = client.putSubresource(cmd.Context(), bucketName, "", "versioning", nil, body, nil)
+			}
+			if err != nil {
+				log.Printf("Error: bucket created, but enabling versioning failed: %v", err)
+				return
+			}
+			fmt.Println("Versioning enabled.")
+		}
+	},
 }
 
 // Fonction de création de bucket avec gestion des erreurs
-func createBucket(url string) error {
-    // Créer une requête PUT pour créer le bucket
-    req, err := http.NewRequest("PUT", url, nil)
-    if err != nil {
-        return fmt.Errorf("failed to create request: %w", err)
-    }
-
-    // Créer un client HTTP avec un timeout pour éviter les requêtes bloquantes
-    client := &http.Client{
-        Timeout: 10 * time.Second,
-    }
-
-    // Envoyer la requête
-    resp, err := client.Do(req)
-    if err != nil {
-        return fmt.Errorf("request failed: %w", err)
-    }
-    defer resp.Body.Close()
-
-    // Lire le corps de la réponse
-    body, err := io.ReadAll(resp.Body)
-    if err != nil {
-        return fmt.Errorf("failed to read response body: %w", err)
-    }
-
-    // Vérifier le code de statut HTTP et afficher un message détaillé
-    switch resp.StatusCode {
-        case http.StatusOK: // 200 - Handle as success 
-            return nil
-        case http.StatusConflict: // 409 - Bucket already exists
-            return fmt.Errorf("%s", string(body))
-        case http.StatusInternalServerError: // 500 - Server error
-            return fmt.Errorf("%s", string(body))
-        default:
-            return fmt.Errorf("unexpected error: received status code %d with message: %s", resp.StatusCode, string(body))
-    }
+func createBucket(ctx context.Context, client *s3Client, bucketName string, opts createBucketOptions) error {
+	// Hors de us-east-1 (région par défaut de S3), la région est précisée dans le corps ;
+	// la requête est signée pour cette région
+	var body []byte
+	if opts.region != "" {
+		client.mu.Lock()
+		client.bucketRegions[bucketName] = opts.region
+		client.mu.Unlock()
+		if opts.region != "us-east-1" {
+			var err error
+			body, err = xml.Marshal(CreateBucketConfiguration{Xmlns: s3XMLNamespace, LocationConstraint: opts.region})
+			if err != nil {
+				return fmt.Errorf("failed to marshal bucket configuration: %w", err)
+			}
+		}
+	}
+
+	// Créer une requête PUT pour créer le bucket
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := client.newRequest(ctx, "PUT", bucketName, "", nil, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/xml")
+	}
+	if opts.acl != "" {
+		req.Header.Set("X-Amz-Acl", opts.acl)
+	}
+	if opts.objectLock {
+		req.Header.Set("X-Amz-Bucket-Object-Lock-Enabled", "true")
+	}
+
+	// Envoyer la requête signée (délai et nouvelles tentatives gérés par le client)
+	resp, err := client.do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Lire le corps de la réponse
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+	var s3Err S3ErrorResponse
+	xml.Unmarshal(respBody, &s3Err)
+
+	// Vérifier le code de statut HTTP et afficher un message détaillé
+	switch resp.StatusCode {
+	case http.StatusOK: // 200 - Handle as success
+		return nil
+	case http.StatusConflict: // 409 - Bucket already exists
+		return &bucketConflictError{code: s3Err.Code, message: string(respBody)}
+	case http.StatusBadRequest: // 400 - Région ou ACL refusée
+		switch s3Err.Code {
+		case "InvalidLocationConstraint", "IllegalLocationConstraintException":
+			return fmt.Errorf("region '%s' was refused by the server: %s", opts.region, s3Err.Message)
+		case "InvalidBucketAclWithObjectOwnership", "AccessControlListNotSupported":
+			return fmt.Errorf("ACLs are disabled on new buckets of this server (Object Ownership: bucket owner enforced); create the bucket without --acl and use a bucket policy")
+		}
+		return fmt.Errorf("%s", string(respBody))
+	case http.StatusInternalServerError: // 500 - Server error
+		return fmt.Errorf("%s", string(respBody))
+	default:
+		return fmt.Errorf("unexpected error: received status code %d with message: %s", resp.StatusCode, string(respBody))
+	}
+}
+
+// ownsBucket indique si un bucket existant nous appartient : d'après le code d'erreur du 409,
+// sinon d'après une requête HEAD (403 pour le bucket d'un autre compte)
+func ownsBucket(ctx context.Context, client *s3Client, bucketName string, conflict *bucketConflictError) (bool, error) {
+	switch conflict.code {
+	case "BucketAlreadyOwnedByYou":
+		return true, nil
+	case "BucketAlreadyExists":
+		return false, nil
+	}
+	req, err := client.newRequest(ctx, "HEAD", bucketName, "", nil, nil)
+	if err != nil {
+		return false, err
+	}
+	resp, err := client.do(req)
+	if err != nil {
+		return false, fmt.Errorf("request failed: %w", err)
+	}
+	resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusForbidden:
+		return false, nil
+	default:
+		return false, fmt.Errorf("%s", strings.TrimSpace(conflict.message))
+	}
 }
 
 func init() {
-    RootCmd.AddCommand(createBucketCmd)
+	createBucketCmd.Flags().StringVar(&createBucketRegion, "region", "", "create the bucket in this region (LocationConstraint)")
+	createBucketCmd.Flags().StringVar(&createBucketACL, "acl", "", "canned ACL: "+strings.Join(bucketCannedACLs, ", "))
+	createBucketCmd.Flags().BoolVar(&createBucketObjectLock, "object-lock", false, "enable Object Lock (WORM retention and legal holds) on the new bucket")
+	createBucketCmd.Flags().BoolVar(&createBucketVersioning, "versioning", false, "enable versioning on the new bucket")
+	createBucketCmd.Flags().BoolVar(&createBucketIfNotExists, "if-not-exists", false, "succeed without changes if the bucket already exists and is yours")
+	RootCmd.AddCommand(createBucketCmd)
 }
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// credentialsRefreshWindow : les identifiants temporaires sont renouvelés avant leur expiration
+const credentialsRefreshWindow = 5 * time.Minute
+
+// Credentials regroupe une paire de clés d'accès et un éventuel jeton de session
+type Credentials struct {
+	AccessKeyID     string    `json:"access_key_id"`
+	SecretAccessKey string    `json:"secret_access_key"`
+	SessionToken    string    `json:"session_token,omitempty"`
+	Expiration      time.Time `json:"expiration,omitempty"`
+}
+
+// expiresSoon indique si les identifiants doivent être renouvelés
+func (c *Credentials) expiresSoon(now time.Time) bool {
+	return !c.Expiration.IsZero() && now.Add(credentialsRefreshWindow).After(c.Expiration)
+}
+
+// stsMutex évite plusieurs appels AssumeRole concurrents pour un même profil
+var stsMutex sync.Mutex
+
+// resolveCredentials retourne les identifiants du profil, ou nil pour un accès anonyme
+func resolveCredentials(ctx context.Context, p *Profile) (*Credentials, error) {
+	return resolveCredentialsChain(ctx, p, map[string]bool{})
+}
+
+func resolveCredentialsChain(ctx context.Context, p *Profile, seen map[string]bool) (*Credentials, error) {
+	if p.RoleARN == "" {
+		if p.AccessKey == "" {
+			return nil, nil
+		}
+		if p.SecretKey == "" {
+			return nil, fmt.Errorf("profile '%s' has an access_key but no secret_key", p.Name)
+		}
+		return &Credentials{AccessKeyID: p.AccessKey, SecretAccessKey: p.SecretKey, SessionToken: p.SessionToken}, nil
+	}
+
+	if seen[p.Name] {
+		return nil, fmt.Errorf("source_profile loop detected at profile '%s'", p.Name)
+	}
+	seen[p.Name] = true
+
+	if p.SourceProfile == "" {
+		return nil, fmt.Errorf("profile '%s' declares role_arn but no source_profile", p.Name)
+	}
+	source, err := loadProfile(p.SourceProfile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load source profile of '%s': %w", p.Name, err)
+	}
+	sourceCreds, err := resolveCredentialsChain(ctx, source, seen)
+	if err != nil {
+		return nil, err
+	}
+	if sourceCreds == nil {
+		return nil, fmt.Errorf("source profile '%s' has no credentials to call AssumeRole", source.Name)
+	}
+
+	stsMutex.Lock()
+	defer stsMutex.Unlock()
+
+	cachePath := stsCachePath(p, sourceCreds)
+	if cached, err := readCachedCredentials(cachePath); err == nil && !cached.expiresSoon(time.Now()) {
+		return cached, nil
+	}
+
+	creds, err := assumeRole(ctx, p, sourceCreds)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeCachedCredentials(cachePath, creds); err != nil {
+		// Le cache n'est qu'une optimisation : on continue avec les identifiants obtenus
+		handleError(fmt.Errorf("failed to cache session credentials: %v", err))
+	}
+	return creds, nil
+}
+
+// credentialsCacheDir retourne le répertoire de cache (MYCLI_CACHE_DIR ou le cache utilisateur)
+func credentialsCacheDir() (string, error) {
+	if dir := viper.GetString("cache_dir"); dir != "" {
+		return filepath.Join(dir, "sts"), nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "bs3", "sts"), nil
+}
+
+// stsCachePath identifie une session par profil, rôle, clé source et paramètres de l'appel AssumeRole
+// (point d'accès, région, ExternalId, durée) : en changer invalide le cache
+func stsCachePath(p *Profile, source *Credentials) string {
+	dir, err := credentialsCacheDir()
+	if err != nil {
+		return ""
+	}
+	key := strings.Join([]string{
+		p.Name, p.RoleARN, p.sessionName(), source.AccessKeyID,
+		p.STSEndpoint, p.Region, p.ExternalID, strconv.Itoa(p.DurationSeconds),
+	}, "\n")
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(dir, hex.EncodeToString(sum[:16])+".json")
+}
+
+func readCachedCredentials(path string) (*Credentials, error) {
+	if path == "" {
+		return nil, os.ErrNotExist
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var creds Credentials
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return nil, err
+	}
+	return &creds, nil
+}
+
+func writeCachedCredentials(path string, creds *Credentials) error {
+	if path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(creds)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
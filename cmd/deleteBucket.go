@@ -1,11 +1,12 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
 // deleteBucketCmd représente la commande `delete-bucket`
@@ -19,43 +20,51 @@ var DeleteBucketCmd = &cobra.Command{
 		}
 		bucketName := args[0]
 
-		// Récupérer l'URL du serveur S3 à partir de la configuration
-		apiURL := viper.GetString("s3.api_url")
-		if apiURL == "" {
-			log.Fatal("API URL is not configured. Please set it in the config file or environment variables.")
-		}
-
-		// Construire l'URL pour la requête DELETE
-		url := fmt.Sprintf("%s/%s/", apiURL, bucketName)
-
-		// Créer la requête HTTP DELETE
-		req, err := http.NewRequest("DELETE", url, nil)
-		if err != nil {
-			log.Fatalf("Error creating DELETE request: %v", err)
-		}
-
-		// Envoyer la requête DELETE
-		client := &http.Client{}
-		resp, err := client.Do(req)
+		// Récupérer l'URL du serveur S3 à partir du profil actif
+		client, err := newConfiguredClient()
 		if err != nil {
-			log.Fatalf("Error making DELETE request: %v", err)
+			log.Fatalf("Error: %v", err)
 		}
-		defer resp.Body.Close()
 
-		// Vérifier le statut de la réponse
-		switch resp.StatusCode {
-		case http.StatusOK, http.StatusNoContent:
+		// Supprimer le bucket et afficher le résultat selon le statut de la réponse
+		err = removeBucket(cmd.Context(), client, bucketName)
+		var s3Err *S3Error
+		switch {
+		case err == nil:
 			fmt.Printf("Bucket '%s' deleted successfully.\n", bucketName)
-		case http.StatusNotFound:
+		case errors.As(err, &s3Err) && s3Err.StatusCode == http.StatusNotFound:
 			fmt.Printf("Bucket '%s' does not exist or has already been deleted.\n", bucketName)
-		case http.StatusInternalServerError: 
-			fmt.Printf("Internal server error : Status code: %d\n", resp.StatusCode)
+		case errors.As(err, &s3Err) && s3Err.StatusCode == http.StatusInternalServerError:
+			fmt.Printf("Internal server error : Status code: %d\n", s3Err.StatusCode)
+		case errors.As(err, &s3Err):
+			fmt.Printf("Failed to delete bucket '%s'. Status code: %d\n", bucketName, s3Err.StatusCode)
 		default:
-			fmt.Printf("Failed to delete bucket '%s'. Status code: %d\n", bucketName, resp.StatusCode)
+			log.Fatalf("Error: %v", err)
 		}
 	},
 }
 
+// removeBucket supprime un bucket ; S3 refuse la suppression d'un bucket qui n'est pas vide
+func removeBucket(ctx context.Context, client *s3Client, bucketName string) error {
+	req, err := client.newRequest(ctx, "DELETE", bucketName, "", nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := client.do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	err = responseError(resp)
+	if s3Err, ok := err.(*S3Error); ok && s3Err.Code == "BucketNotEmpty" {
+		return fmt.Errorf("bucket '%s' is not empty; delete its objects (and versions) first", bucketName)
+	}
+	return err
+}
+
 func init() {
 	// Enregistrer la commande delete-bucket dans la racine
 	RootCmd.AddCommand(DeleteBucketCmd)
@@ -2,95 +2,218 @@ package cmd
 
 import (
 	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
 	"encoding/xml"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"net/url"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
 type DeleteObjectRequest struct {
-	XMLName xml.Name `xml:"Delete"`
+	XMLName xml.Name         `xml:"Delete"`
 	Objects []ObjectToDelete `xml:"Object"`
 }
 
 type ObjectToDelete struct {
-	Key string `xml:"Key"`
+	Key       string `xml:"Key"`
+	VersionId string `xml:"VersionId,omitempty"`
 }
 
+// DeleteResult est la réponse de ?delete : S3 répond 200 même quand des clés n'ont pas pu être supprimées
+type DeleteResult struct {
+	XMLName xml.Name      `xml:"DeleteResult"`
+	Errors  []DeleteError `xml:"Error"`
+}
+
+// DeleteError décrit une clé dont la suppression a été refusée (ex. version protégée par Object Lock)
+type DeleteError struct {
+	Key       string `xml:"Key"`
+	VersionId string `xml:"VersionId"`
+	Code      string `xml:"Code"`
+	Message   string `xml:"Message"`
+}
+
+var (
+	deleteVersionID  string
+	deleteTagFilters []string
+	deletePrefix     string
+	deleteDryRun     bool
+	deleteBypass     bool
+)
+
 // deleteObjectCmd represents the deleteObject command
 var DeleteObjectCmd = &cobra.Command{
-    Use:   "delete-object",
-    Short: "Deletes an object from the specified S3 bucket",
-    Long: `This command deletes an object from the specified S3 bucket.
+	Use:   "delete-object",
+	Short: "Deletes an object from the specified S3 bucket",
+	Long: `This command deletes an object from the specified S3 bucket.
 You need to specify the bucket name and the object key.
 For example:
 
 my-cli delete-object <bucket-name> <object-key>`,
-    Run: func(cmd *cobra.Command, args []string) {
+	Run: func(cmd *cobra.Command, args []string) {
+		// Suppression de tous les objets portant les tags demandés
+		if len(deleteTagFilters) > 0 {
+			deleteObjectsByTags(cmd, args)
+			return
+		}
+
 		// Vérification des arguments
-        if len(args) < 2 {
-            log.Fatal("Usage: delete-object <bucket-name> <object-key>")
-        }
-
-        bucketName := args[0]
-        objectKey := args[1]
-
-				// Récupérer l'URL de l'API depuis la configuration via Viper
-        apiURL := viper.GetString("s3.api_url")
-        if apiURL == "" {
-            log.Fatal("API URL is not configured. Please set it in the config file or environment variables.")
-        }
-
-				// Construire l'URL pour la requête POST de suppression
-        url := fmt.Sprintf("%s/%s/?delete", apiURL, bucketName)
-
-				// Préparer la requête de suppression en XML
-        deleteReq := DeleteObjectRequest{
-            Objects: []ObjectToDelete{
-                {Key: objectKey},
-            },
-        }
-
-				// Encoder la requête en XML
-        xmlData, err := xml.Marshal(deleteReq)
-        if err != nil {
-            log.Fatalf("Error marshalling XML: %v", err)
-        }
-
-				// Créer la requête HTTP POST
-        req, err := http.NewRequest("POST", url, bytes.NewBuffer(xmlData))
-        if err != nil {
-            log.Fatalf("Error creating request: %v", err)
-        }
-
-				// Ajouter les en-têtes nécessaires
-        req.Header.Set("Content-Type", "application/xml")
-
-				// Envoyer la requête
-        client := &http.Client{}
-        resp, err := client.Do(req)
-        if err != nil {
-            log.Fatalf("Error making request: %v", err)
-        }
-        defer resp.Body.Close()
-
-		// Traiter la réponse 
-        switch resp.StatusCode {
-        case http.StatusOK, http.StatusNoContent:
-            fmt.Printf("Successfully deleted object '%s' from bucket '%s'.\n", objectKey, bucketName)
-        case http.StatusNotFound:
-            fmt.Printf("Object '%s' not found in bucket '%s'.\n", objectKey, bucketName)
-        case http.StatusInternalServerError: 
-            fmt.Printf("Internal server error : Status code: %d\n", resp.StatusCode)
-        default:
-            fmt.Printf("Failed to delete object '%s' from bucket '%s'. Status code: %d\n", objectKey, bucketName, resp.StatusCode)
-        }
-    },
+		if len(args) < 2 {
+			log.Fatal("Usage: delete-object <bucket-name> <object-key>")
+		}
+
+		bucketName := args[0]
+		objectKey := args[1]
+
+		// Récupérer l'URL de l'API depuis le profil actif
+		client, err := newConfiguredClient()
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+
+		// Préparer la requête de suppression (?delete) de l'objet ou de sa version
+		req, err := newDeleteObjectsRequest(cmd.Context(), client, bucketName, []ObjectToDelete{{Key: objectKey, VersionId: deleteVersionID}})
+		if err != nil {
+			log.Fatalf("Error creating request: %v", err)
+		}
+		if deleteBypass {
+			req.Header.Set(bypassGovernanceHeader, "true")
+		}
+
+		// Envoyer la requête
+		resp, err := client.do(req)
+		if err != nil {
+			log.Fatalf("Error making request: %v", err)
+		}
+		defer resp.Body.Close()
+
+		// Traiter la réponse
+		switch resp.StatusCode {
+		case http.StatusOK, http.StatusNoContent:
+			// Une version verrouillée est signalée dans le corps de la réponse
+			var result DeleteResult
+			if body, err := io.ReadAll(resp.Body); err == nil && xml.Unmarshal(body, &result) == nil && len(result.Errors) > 0 {
+				reportLockedDelete(cmd, client, bucketName, objectKey, result.Errors[0].Code, result.Errors[0].Message)
+				return
+			}
+			if deleteVersionID != "" {
+				fmt.Printf("Successfully deleted version '%s' of object '%s' from bucket '%s'.\n", deleteVersionID, objectKey, bucketName)
+				return
+			}
+			fmt.Printf("Successfully deleted object '%s' from bucket '%s'.\n", objectKey, bucketName)
+		case http.StatusForbidden:
+			s3Err, _ := responseError(resp).(*S3Error)
+			reportLockedDelete(cmd, client, bucketName, objectKey, s3Err.Code, s3Err.Message)
+		case http.StatusNotFound:
+			fmt.Printf("Object '%s' not found in bucket '%s'.\n", objectKey, bucketName)
+		case http.StatusInternalServerError:
+			fmt.Printf("Internal server error : Status code: %d\n", resp.StatusCode)
+		default:
+			fmt.Printf("Failed to delete object '%s' from bucket '%s'. Status code: %d\n", objectKey, bucketName, resp.StatusCode)
+		}
+	},
+}
+
+// reportLockedDelete explique un refus de suppression : pour une version protégée par Object Lock,
+// sa rétention ou sa suspension légale et le moyen de la débloquer
+func reportLockedDelete(cmd *cobra.Command, client *s3Client, bucketName, objectKey, code, message string) {
+	target := fmt.Sprintf("object '%s'", objectKey)
+	if deleteVersionID != "" {
+		target = fmt.Sprintf("version '%s' of object '%s'", deleteVersionID, objectKey)
+	}
+	if code == "AccessDenied" {
+		if status, err := getLockStatus(cmd.Context(), client, bucketName, objectKey, deleteVersionID); err == nil && (status.legalHold == "ON" || status.mode != "") {
+			fmt.Printf("Cannot delete %s from bucket '%s': %s.\n", target, bucketName, status.blockReason(deleteBypass))
+			return
+		}
+	}
+	fmt.Printf("Failed to delete %s from bucket '%s': %s: %s\n", target, bucketName, code, message)
+}
+
+// deleteObjectsByTags supprime les objets du bucket (sous --prefix) dont les tags correspondent à --tag-filter
+func deleteObjectsByTags(cmd *cobra.Command, args []string) {
+	if len(args) < 1 {
+		log.Println("Usage: delete-object <bucket-name> --tag-filter <key=value> [--prefix <prefix>] [--dry-run]")
+		return
+	}
+	bucketName := args[0]
+	// La suppression par tags porte sur les versions courantes : elle ne vise aucune version précise
+	if deleteVersionID != "" || deleteBypass {
+		log.Println("Error: --version-id and --bypass-governance cannot be combined with --tag-filter")
+		return
+	}
+
+	filters, err := parseTagFilters(deleteTagFilters)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	client, err := newConfiguredClient()
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+
+	objects, err := listObjects(cmd.Context(), client, bucketName, deletePrefix)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	keys := make([]string, len(objects))
+	for i, obj := range objects {
+		keys[i] = obj.Key
+	}
+	selected, err := filterByTags(cmd.Context(), client, bucketName, keys, filters)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	if len(selected) == 0 {
+		fmt.Printf("No objects in bucket '%s' match the tag filter.\n", bucketName)
+		return
+	}
+
+	for _, key := range selected {
+		fmt.Printf("- %s\n", key)
+	}
+	if deleteDryRun {
+		fmt.Printf("Dry run: %d object(s) would be deleted from bucket '%s'.\n", len(selected), bucketName)
+		return
+	}
+	if err := deleteKeys(cmd.Context(), client, bucketName, selected); err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	fmt.Printf("Deleted %d object(s) matching the tag filter from bucket '%s'.\n", len(selected), bucketName)
+}
+
+// newDeleteObjectsRequest prépare la requête DeleteObjects (POST ?delete) ; S3 exige le Content-MD5 du document
+func newDeleteObjectsRequest(ctx context.Context, client *s3Client, bucketName string, objects []ObjectToDelete) (*http.Request, error) {
+	body, err := xml.Marshal(DeleteObjectRequest{Objects: objects})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal delete request: %w", err)
+	}
+	req, err := client.newRequest(ctx, "POST", bucketName, "", url.Values{"delete": {""}}, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	sum := md5.Sum(body)
+	req.Header.Set("Content-MD5", base64.StdEncoding.EncodeToString(sum[:]))
+	req.Header.Set("Content-Type", "application/xml")
+	return req, nil
 }
 
 func init() {
+	DeleteObjectCmd.Flags().StringArrayVar(&deleteTagFilters, "tag-filter", nil, "delete every object with this tag (key=value, or key for any value); repeat to require several tags")
+	DeleteObjectCmd.Flags().StringVar(&deletePrefix, "prefix", "", "with --tag-filter, only consider keys starting with this prefix")
+	DeleteObjectCmd.Flags().BoolVar(&deleteDryRun, "dry-run", false, "with --tag-filter, list the matching objects without deleting them")
+	DeleteObjectCmd.Flags().StringVar(&deleteVersionID, "version-id", "", "permanently delete a specific version of the object")
+	DeleteObjectCmd.Flags().BoolVar(&deleteBypass, "bypass-governance", false, "delete a version under GOVERNANCE retention (requires s3:BypassGovernanceRetention)")
 	RootCmd.AddCommand(DeleteObjectCmd)
 }
@@ -0,0 +1,376 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+)
+
+// LocationConstraint représente la réponse de la sous-ressource ?location ; vide pour us-east-1
+type LocationConstraint struct {
+	XMLName xml.Name `xml:"LocationConstraint"`
+	Region  string   `xml:",chardata"`
+}
+
+// États d'une section du rapport describe-bucket
+const (
+	sectionConfigured    = "configured"
+	sectionNotConfigured = "not_configured"
+	sectionError         = "error"
+)
+
+// bucketSection est une partie du rapport : son état, un résumé d'une ligne et,
+// pour la sortie JSON, le détail de la configuration
+type bucketSection struct {
+	Status  string `json:"status"`
+	Summary string `json:"summary,omitempty"`
+	Error   string `json:"error,omitempty"`
+	Details any    `json:"details,omitempty"`
+}
+
+// BucketDescription est le rapport complet de describe-bucket, dans l'ordre d'affichage
+type BucketDescription struct {
+	Bucket     string        `json:"bucket"`
+	Location   bucketSection `json:"location"`
+	Versioning bucketSection `json:"versioning"`
+	Encryption bucketSection `json:"encryption"`
+	Policy     bucketSection `json:"policy"`
+	CORS       bucketSection `json:"cors"`
+	Lifecycle  bucketSection `json:"lifecycle"`
+	Tags       bucketSection `json:"tags"`
+	ObjectLock bucketSection `json:"object_lock"`
+	ACL        bucketSection `json:"acl"`
+}
+
+// bucketSections associe chaque section à sa clé JSON, à son libellé, à son champ du rapport et à sa lecture
+var bucketSections = []struct {
+	key   string
+	label string
+	field func(*BucketDescription) *bucketSection
+	fetch func(context.Context, *s3Client, string) (bucketSection, error)
+}{
+	{"location", "Location", func(d *BucketDescription) *bucketSection { return &d.Location }, locationSection},
+	{"versioning", "Versioning", func(d *BucketDescription) *bucketSection { return &d.Versioning }, versioningSection},
+	{"encryption", "Encryption", func(d *BucketDescription) *bucketSection { return &d.Encryption }, encryptionSection},
+	{"policy", "Policy", func(d *BucketDescription) *bucketSection { return &d.Policy }, policySection},
+	{"cors", "CORS", func(d *BucketDescription) *bucketSection { return &d.CORS }, corsSection},
+	{"lifecycle", "Lifecycle", func(d *BucketDescription) *bucketSection { return &d.Lifecycle }, lifecycleSection},
+	{"tags", "Tags", func(d *BucketDescription) *bucketSection { return &d.Tags }, tagsSection},
+	{"object_lock", "Object Lock", func(d *BucketDescription) *bucketSection { return &d.ObjectLock }, objectLockSection},
+	{"acl", "ACL", func(d *BucketDescription) *bucketSection { return &d.ACL }, aclSection},
+}
+
+var describeBucketFormat string
+
+// DescribeBucketCmd affiche en un seul rapport l'ensemble des réglages d'un bucket
+var DescribeBucketCmd = &cobra.Command{
+	Use:   "describe-bucket <bucket-name>",
+	Short: "Shows every setting of a bucket in one report",
+	Long: `Reads the location, versioning, encryption, policy, CORS, lifecycle, tags, Object Lock
+and ACL of a bucket concurrently and prints them in one report. A section that is not configured
+or cannot be read does not prevent the others from being shown.
+For example:
+
+bs3 describe-bucket invoices-2024
+bs3 describe-bucket invoices-2024 --format json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 {
+			log.Println("Usage: describe-bucket <bucket-name> [--format text|json]")
+			return
+		}
+		bucketName := args[0]
+		if describeBucketFormat != "text" && describeBucketFormat != "json" {
+			log.Printf("Error: unsupported format '%s' (expected text or json)", describeBucketFormat)
+			return
+		}
+
+		client, err := newConfiguredClient()
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+
+		description, err := describeBucket(cmd.Context(), client, bucketName)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+
+		if describeBucketFormat == "json" {
+			out, err := formatJSON(description)
+			if err != nil {
+				log.Printf("Error encoding JSON: %v", err)
+				return
+			}
+			fmt.Print(out)
+			return
+		}
+		fmt.Printf("Bucket: %s\n", bucketName)
+		for _, s := range bucketSections {
+			section := s.field(description)
+			text := section.Summary
+			if section.Status == sectionError {
+				text = "error: " + section.Error
+			}
+			fmt.Printf("  %-12s %s\n", s.label+":", text)
+		}
+	},
+}
+
+// describeBucket lit toutes les sections en parallèle ; une erreur par section est reportée dans
+// le rapport, sauf si le bucket n'existe pas
+func describeBucket(ctx context.Context, client *s3Client, bucketName string) (*BucketDescription, error) {
+	description := &BucketDescription{Bucket: bucketName}
+	errs := make([]error, len(bucketSections))
+	var wg sync.WaitGroup
+	for i, s := range bucketSections {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			section, err := s.fetch(ctx, client, bucketName)
+			if err != nil {
+				section = bucketSection{Status: sectionError, Error: err.Error()}
+			}
+			*s.field(description) = section
+			errs[i] = err
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		var s3Err *S3Error
+		if errors.As(err, &s3Err) && s3Err.Code == "NoSuchBucket" {
+			return nil, fmt.Errorf("bucket '%s' does not exist", bucketName)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return description, nil
+}
+
+// section retourne la section de clé key du rapport
+func (d *BucketDescription) section(key string) *bucketSection {
+	for _, s := range bucketSections {
+		if s.key == key {
+			return s.field(d)
+		}
+	}
+	return nil
+}
+
+func configuredSection(summary string, details any) bucketSection {
+	return bucketSection{Status: sectionConfigured, Summary: summary, Details: details}
+}
+
+func notConfiguredSection(summary string) bucketSection {
+	return bucketSection{Status: sectionNotConfigured, Summary: summary}
+}
+
+func locationSection(ctx context.Context, client *s3Client, bucketName string) (bucketSection, error) {
+	data, err := client.getSubresource(ctx, bucketName, "", "location", nil)
+	if err != nil {
+		return bucketSection{}, err
+	}
+	var location LocationConstraint
+	if err := xml.Unmarshal(data, &location); err != nil {
+		return bucketSection{}, fmt.Errorf("failed to parse location: %w", err)
+	}
+	// Une contrainte vide désigne us-east-1, et EU l'ancien nom de eu-west-1
+	region := strings.TrimSpace(location.Region)
+	switch region {
+	case "":
+		region = "us-east-1"
+	case "EU":
+		region = "eu-west-1"
+	}
+	return configuredSection(region, map[string]string{"region": region}), nil
+}
+
+func versioningSection(ctx context.Context, client *s3Client, bucketName string) (bucketSection, error) {
+	config, err := getVersioning(ctx, client, bucketName)
+	if err != nil {
+		return bucketSection{}, err
+	}
+	return versioningSectionOf(config), nil
+}
+
+func versioningSectionOf(config *VersioningConfiguration) bucketSection {
+	if config.Status == "" {
+		return notConfiguredSection(versioningStatus(config))
+	}
+	summary := config.Status
+	if config.MFADelete != "" {
+		summary += ", MFA delete " + config.MFADelete
+	}
+	details := struct {
+		Status    string `json:"status"`
+		MFADelete string `json:"mfa_delete,omitempty"`
+	}{config.Status, config.MFADelete}
+	return configuredSection(summary, details)
+}
+
+func encryptionSection(ctx context.Context, client *s3Client, bucketName string) (bucketSection, error) {
+	config, err := getEncryption(ctx, client, bucketName)
+	if err != nil {
+		return bucketSection{}, err
+	}
+	return encryptionSectionOf(config), nil
+}
+
+func encryptionSectionOf(config *ServerSideEncryptionConfiguration) bucketSection {
+	if config == nil || len(config.Rules) == 0 {
+		return notConfiguredSection("none")
+	}
+	rule := config.Rules[0]
+	details := struct {
+		Algorithm string `json:"algorithm"`
+		KMSKeyID  string `json:"kms_key_id,omitempty"`
+		BucketKey bool   `json:"bucket_key,omitempty"`
+	}{rule.ApplyServerSideEncryptionByDefault.SSEAlgorithm, rule.ApplyServerSideEncryptionByDefault.KMSMasterKeyID, rule.BucketKeyEnabled}
+	return configuredSection(describeDefaultEncryption(rule), details)
+}
+
+func policySection(ctx context.Context, client *s3Client, bucketName string) (bucketSection, error) {
+	policy, err := getPolicy(ctx, client, bucketName)
+	if err != nil {
+		return bucketSection{}, err
+	}
+	return policySectionOf(policy), nil
+}
+
+// policySectionOf décrit une politique mise en forme par normalizeJSON
+func policySectionOf(policy string) bucketSection {
+	if policy == "" {
+		return notConfiguredSection("none")
+	}
+	// Le document est repris tel quel ; le résumé compte ses déclarations quand il est lisible
+	summary := "set"
+	var document PolicyDocument
+	if json.Unmarshal([]byte(policy), &document) == nil {
+		summary = fmt.Sprintf("%d statement(s)", len(document.Statement))
+	}
+	return configuredSection(summary, json.RawMessage(policy))
+}
+
+func corsSection(ctx context.Context, client *s3Client, bucketName string) (bucketSection, error) {
+	config, _, err := getCORS(ctx, client, bucketName)
+	if err != nil {
+		return bucketSection{}, err
+	}
+	return corsSectionOf(config), nil
+}
+
+func corsSectionOf(config *CORSConfiguration) bucketSection {
+	if config == nil || len(config.Rules) == 0 {
+		return notConfiguredSection("none")
+	}
+	return configuredSection(fmt.Sprintf("%d rule(s)", len(config.Rules)), corsSpecOf(config))
+}
+
+func lifecycleSection(ctx context.Context, client *s3Client, bucketName string) (bucketSection, error) {
+	config, _, err := getLifecycle(ctx, client, bucketName)
+	if err != nil {
+		return bucketSection{}, err
+	}
+	return lifecycleSectionOf(config), nil
+}
+
+func lifecycleSectionOf(config *LifecycleConfiguration) bucketSection {
+	if config == nil || len(config.Rules) == 0 {
+		return notConfiguredSection("none")
+	}
+	enabled := 0
+	for _, rule := range config.Rules {
+		if rule.Status == "Enabled" {
+			enabled++
+		}
+	}
+	return configuredSection(fmt.Sprintf("%d rule(s), %d enabled", len(config.Rules), enabled), lifecycleSpecOf(config))
+}
+
+func tagsSection(ctx context.Context, client *s3Client, bucketName string) (bucketSection, error) {
+	tags, err := getTags(ctx, client, bucketName, "")
+	if err != nil {
+		return bucketSection{}, err
+	}
+	return tagsSectionOf(tags), nil
+}
+
+func tagsSectionOf(tags map[string]string) bucketSection {
+	if len(tags) == 0 {
+		return notConfiguredSection("none")
+	}
+	pairs := make([]string, 0, len(tags))
+	for _, name := range sortedTagKeys(tags) {
+		pairs = append(pairs, name+"="+tags[name])
+	}
+	return configuredSection(strings.Join(pairs, ", "), tags)
+}
+
+func objectLockSection(ctx context.Context, client *s3Client, bucketName string) (bucketSection, error) {
+	config, err := getObjectLockConfiguration(ctx, client, bucketName)
+	if err != nil {
+		return bucketSection{}, err
+	}
+	if config == nil {
+		return notConfiguredSection("disabled"), nil
+	}
+	type retentionDetails struct {
+		Mode  string `json:"mode"`
+		Days  int    `json:"days,omitempty"`
+		Years int    `json:"years,omitempty"`
+	}
+	details := struct {
+		Enabled          bool              `json:"enabled"`
+		DefaultRetention *retentionDetails `json:"default_retention,omitempty"`
+	}{Enabled: true}
+	if config.Rule == nil {
+		return configuredSection("enabled, no default retention", details), nil
+	}
+	retention := config.Rule.DefaultRetention
+	details.DefaultRetention = &retentionDetails{retention.Mode, retention.Days, retention.Years}
+	return configuredSection("enabled, default retention "+describeDefaultRetention(retention), details), nil
+}
+
+func aclSection(ctx context.Context, client *s3Client, bucketName string) (bucketSection, error) {
+	policy, _, err := getACL(ctx, client, bucketName, "", "")
+	if errors.Is(err, errACLsDisabled) {
+		return notConfiguredSection("disabled (Object Ownership: bucket owner enforced)"), nil
+	}
+	if err != nil {
+		return bucketSection{}, err
+	}
+	type grantDetails struct {
+		Grantee    string `json:"grantee"`
+		Type       string `json:"type"`
+		Permission string `json:"permission"`
+	}
+	details := struct {
+		Owner  string         `json:"owner"`
+		Grants []grantDetails `json:"grants"`
+	}{Owner: describeUser(policy.Owner.DisplayName, policy.Owner.ID), Grants: []grantDetails{}}
+	public := false
+	for _, grant := range policy.Grants {
+		name, kind := describeGrantee(grant.Grantee)
+		details.Grants = append(details.Grants, grantDetails{name, kind, grant.Permission})
+		public = public || grant.Grantee.URI == aclGroups["AllUsers"]
+	}
+	summary := fmt.Sprintf("owner %s, %d grant(s)", details.Owner, len(policy.Grants))
+	if public {
+		summary += ", public (AllUsers)"
+	}
+	return configuredSection(summary, details), nil
+}
+
+func init() {
+	DescribeBucketCmd.Flags().StringVar(&describeBucketFormat, "format", "text", "output format: text or json")
+	RootCmd.AddCommand(DescribeBucketCmd)
+}
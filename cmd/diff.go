@@ -0,0 +1,61 @@
+package cmd
+
+import "strings"
+
+// lineDiff compare deux textes ligne à ligne (plus longue sous-séquence commune) et retourne
+// les lignes préfixées par "  " (inchangée), "- " (supprimée) ou "+ " (ajoutée)
+func lineDiff(before, after string) []string {
+	a := splitLines(before)
+	b := splitLines(after)
+
+	// common[i][j] : longueur de la plus longue sous-séquence commune de a[i:] et b[j:]
+	common := make([][]int, len(a)+1)
+	for i := range common {
+		common[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	var lines []string
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, "  "+a[i])
+			i++
+			j++
+		case i < len(a) && (j == len(b) || common[i+1][j] >= common[i][j+1]):
+			lines = append(lines, "- "+a[i])
+			i++
+		default:
+			lines = append(lines, "+ "+b[j])
+			j++
+		}
+	}
+	return lines
+}
+
+// hasChanges indique si un résultat de lineDiff contient des ajouts ou des suppressions
+func hasChanges(lines []string) bool {
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "  ") {
+			return true
+		}
+	}
+	return false
+}
+
+func splitLines(text string) []string {
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
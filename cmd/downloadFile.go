@@ -1,19 +1,39 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
-	"time"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
+var (
+	downloadRange     string
+	downloadVersionID string
+	downloadSSE       sseFlags
+	downloadMasterKey string
+	downloadRaw       bool
+)
+
+// downloadOptions précise la plage d'octets, la version de l'objet à télécharger,
+// la clé SSE-C éventuelle, la clé maîtresse des objets chiffrés côté client
+// et si le contenu compressé doit être conservé tel quel (raw)
+type downloadOptions struct {
+	rangeHeader   string
+	versionID     string
+	sse           sseOptions
+	masterKeyFile string
+	raw           bool
+}
+
 // downloadFileCmd représente la commande download-file
 var DownloadFileCmd = &cobra.Command{
 	Use:   "download-file",
@@ -28,93 +48,211 @@ var DownloadFileCmd = &cobra.Command{
 		fileName := args[1]
 		destPath := args[2]
 
-		// Récupérer l'URL de l'API depuis la configuration via Viper
-		apiURL := viper.GetString("s3.api_url")
-		if apiURL == "" {
-			log.Fatal("API URL is not configured. Please set it in the config file or environment variables.")
+		// Récupérer l'URL de l'API depuis le profil actif
+		client, err := newConfiguredClient()
+		if err != nil {
+			log.Fatalf("Error: %v", err)
 		}
 
-		// Construire l'URL pour l'API
-		url := fmt.Sprintf("%s/%s/%s", apiURL, bucketName, fileName)
+		// Plage d'octets éventuelle (--range) et clé SSE-C de l'objet
+		opts := downloadOptions{versionID: downloadVersionID, masterKeyFile: downloadMasterKey, raw: downloadRaw}
+		if opts.sse, err = downloadSSE.options(); err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		opts.sse.warnPlainHTTP(client)
+		if downloadRange != "" {
+			if opts.rangeHeader, err = parseByteRange(downloadRange); err != nil {
+				log.Printf("Error: %v", err)
+				return
+			}
+		}
 
 		// Télécharger le fichier
-		err := downloadFile(url, destPath, fileName)
+		err = downloadFile(cmd.Context(), client, bucketName, fileName, destPath, opts)
 		if err != nil {
 			log.Printf("Error: %v", err)
 		}
 	},
 }
 
-func downloadFile(url, destPath, fileName string) error {
+// downloadFile télécharge l'objet, ou seulement la plage ou la version précisées dans opts
+func downloadFile(ctx context.Context, client *s3Client, bucketName, fileName, destPath string, opts downloadOptions) (err error) {
+	// Suivre la progression (compteurs atomiques lus par la goroutine d'affichage) ;
+	// la taille n'est connue qu'à la réception des en-têtes
+	display, err := newProgress()
+	if err != nil {
+		return err
+	}
+	bar := display.track(fileName, -1)
+	defer func() {
+		if err != nil {
+			bar.fail(err)
+		}
+		display.finish()
+	}()
+
 	// Faire la requête HTTP GET
-	resp, err := http.Get(url)
+	req, err := client.newRequest(withTransfer(ctx, bar), "GET", bucketName, fileName, versionQuery(opts.versionID), nil)
 	if err != nil {
+		return fmt.Errorf("failed to create GET request: %w", err)
+	}
+	if opts.rangeHeader != "" {
+		req.Header.Set("Range", opts.rangeHeader)
+	}
+	opts.sse.apply(req.Header)
+	resp, err := client.do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("download of '%s' interrupted before any data was received", fileName)
+		}
 		return fmt.Errorf("failed to make GET request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Gérer les statuts HTTP
 	switch resp.StatusCode {
-	case http.StatusOK:
-		// Statut 200 OK - Procéder au téléchargement
+	case http.StatusOK, http.StatusPartialContent:
+		// Statut 200 OK (ou 206 pour une plage) - Procéder au téléchargement
+		if opts.rangeHeader != "" && resp.StatusCode == http.StatusOK {
+			log.Printf("Warning: the server ignored the requested range; downloading the whole object")
+		}
+
+		// Contenu chiffré côté client : déchiffré et authentifié bloc par bloc pendant l'écriture
+		var body io.Reader = resp.Body
+		total := resp.ContentLength
+		enveloped := isEnveloped(resp.Header)
+		if enveloped {
+			if opts.rangeHeader != "" {
+				return fmt.Errorf("'%s' is encrypted client-side: a byte range cannot be decrypted, download the whole object", fileName)
+			}
+			masterKey, err := loadMasterKey(opts.masterKeyFile, client.profile)
+			if err != nil {
+				return fmt.Errorf("'%s' is encrypted client-side: %w", fileName, err)
+			}
+			env, length, err := openEnvelope(masterKey, resp.Header)
+			if err != nil {
+				return fmt.Errorf("cannot decrypt '%s': %w", fileName, err)
+			}
+			body, total = env.decrypt(resp.Body), length
+		}
+
+		// Contenu compressé à l'envoi (upload-file --compress) : décompressé, sauf avec --raw
+		// où il est conservé avec l'extension de l'algorithme
+		finalPath := filepath.Join(destPath, fileName)
+		compression := resp.Header.Get(metaCompression)
+		decompress := compression != "" && !opts.raw
+		if decompress {
+			if opts.rangeHeader != "" {
+				return fmt.Errorf("'%s' is compressed (%s): a byte range cannot be decompressed, download the whole object or use --raw", fileName, compression)
+			}
+			reader, err := decompressStream(compression, body)
+			if errors.Is(err, errEnvelopeAuth) {
+				return fmt.Errorf("cannot decrypt '%s': %w", fileName, err)
+			}
+			if err != nil {
+				return fmt.Errorf("cannot decompress '%s': %w", fileName, err)
+			}
+			defer reader.Close()
+			body, total = reader, -1
+			if size, err := strconv.ParseInt(resp.Header.Get(metaOriginalSize), 10, 64); err == nil {
+				total = size
+			}
+		} else if compression != "" && !strings.HasSuffix(finalPath, compressedExtension(compression)) {
+			finalPath += compressedExtension(compression)
+		}
 		fmt.Printf("File '%s' is being downloaded...\n", fileName)
 
-		// Ouvrir le fichier de destination
-		out, err := os.Create(filepath.Join(destPath, fileName))
+		// Écrire dans un fichier temporaire, renommé une fois le téléchargement complet :
+		// une interruption ne laisse pas de fichier partiel à destination
+		partPath := finalPath + ".part"
+		out, err := os.Create(partPath)
 		if err != nil {
 			return fmt.Errorf("failed to create destination file: %w", err)
 		}
-		defer out.Close()
-
-		// Créer un buffer pour lire le contenu
-		buffer := make([]byte, 256) // Taille de buffer
-		totalSize := resp.ContentLength
-		var downloadedSize int64 = 0
-
-		// Canal pour gérer la progression
-		progressChan := make(chan struct{})
-
-		// Goroutine pour afficher la barre de progression
-		go func() {
-			for {
-				select {
-				case <-progressChan:
-					return
-				default:
-					printProgress(downloadedSize, totalSize)
-					time.Sleep(100 * time.Millisecond)
-				}
+		completed := false
+		defer func() {
+			if !completed {
+				out.Close()
+				os.Remove(partPath)
 			}
 		}()
 
+		bar.setTotal(total)
+		display.start()
+
 		// Lire et copier le contenu
+		buffer := make([]byte, 32*1024)
+		var written int64
 		for {
-			n, err := resp.Body.Read(buffer)
-			if err != nil && err != io.EOF {
-				progressChan <- struct{}{} // Arrêter la progression
-				return fmt.Errorf("failed to read response body: %w", err)
+			n, err := body.Read(buffer)
+			if n > 0 {
+				// Écrire dans le fichier de destination
+				if _, err := out.Write(buffer[:n]); err != nil {
+					return fmt.Errorf("failed to write to file: %w", err)
+				}
+				bar.add(int64(n))
+				written += int64(n)
 			}
-			if n == 0 {
+			if err == io.EOF {
 				break
 			}
-
-			// Écrire dans le fichier de destination
-			if _, err := out.Write(buffer[:n]); err != nil {
-				progressChan <- struct{}{} // Arrêter la progression
-				return fmt.Errorf("failed to write to file: %w", err)
+			if err != nil {
+				if ctx.Err() != nil {
+					return fmt.Errorf("download of '%s' interrupted after %s, partial file removed", fileName, bar.summary())
+				}
+				if errors.Is(err, errEnvelopeAuth) {
+					return fmt.Errorf("cannot decrypt '%s', partial file removed: %w", fileName, err)
+				}
+				if decompress {
+					return fmt.Errorf("cannot decompress '%s', partial file removed: %w", fileName, err)
+				}
+				return fmt.Errorf("failed to read response body: %w", err)
 			}
-			downloadedSize += int64(n)
+		}
+		if decompress && total >= 0 && written != total {
+			return fmt.Errorf("cannot decompress '%s', partial file removed: got %d bytes, expected %d", fileName, written, total)
 		}
 
-		// Mettre à jour une dernière fois la barre de progression
-		printProgress(downloadedSize, totalSize)
-		progressChan <- struct{}{} // Arrêter l'animation de progression
-		fmt.Println("\nDownload completed successfully.")
+		if err := out.Close(); err != nil {
+			return fmt.Errorf("failed to write to file: %w", err)
+		}
+		if err := os.Rename(partPath, finalPath); err != nil {
+			return fmt.Errorf("failed to move downloaded file into place: %w", err)
+		}
+		completed = true
+		bar.finish()
+		display.finish()
+		fmt.Println("Download completed successfully.")
+		switch {
+		case decompress:
+			fmt.Printf("Content decompressed (%s).\n", compression)
+		case compression != "":
+			fmt.Printf("Content kept compressed (%s) in '%s'.\n", compression, finalPath)
+		}
+		if enveloped {
+			fmt.Println("Content decrypted and authenticated (client-side encryption).")
+		}
 
 	case http.StatusNotFound:
 		// Statut 404 Not Found - Fichier introuvable
+		if opts.versionID != "" {
+			return fmt.Errorf("version '%s' of '%s' not found (404 Not Found)", opts.versionID, fileName)
+		}
 		return fmt.Errorf("the system cannot find the file specified (404 Not Found)")
 
+	case http.StatusMethodNotAllowed:
+		// Statut 405 - La version demandée est un marqueur de suppression
+		return fmt.Errorf("version '%s' of '%s' is a delete marker", opts.versionID, fileName)
+
+	case http.StatusBadRequest, http.StatusForbidden:
+		// Statut 400/403 - Souvent une clé SSE-C absente ou différente de celle du chiffrement
+		return sseErrorHint(resp, opts.sse, fileName)
+
+	case http.StatusRequestedRangeNotSatisfiable:
+		// Statut 416 - Plage au-delà de la fin de l'objet
+		return fmt.Errorf("range '%s' is not satisfiable for '%s'", strings.TrimPrefix(opts.rangeHeader, "bytes="), fileName)
+
 	case http.StatusInternalServerError:
 		// Statut 500 Internal Server Error
 		return fmt.Errorf("internal server error (500)")
@@ -127,17 +265,11 @@ func downloadFile(url, destPath, fileName string) error {
 	return nil
 }
 
-// Affiche une barre de progression simple
-func printProgress(downloaded, total int64) {
-	if total == -1 {
-		fmt.Printf("\rDownloading... %d bytes", downloaded)
-		return
-	}
-	percentage := float64(downloaded) / float64(total) * 100
-	progressBar := int(percentage / 2) // barre de 50 caractères
-	fmt.Printf("\r[%-50s] %3.2f%%", strings.Repeat("#", progressBar), percentage)
-}
-
 func init() {
+	DownloadFileCmd.Flags().StringVar(&downloadRange, "range", "", "download only a byte range: start-end, start- or -n (last n bytes)")
+	DownloadFileCmd.Flags().StringVar(&downloadVersionID, "version-id", "", "download a specific version of the object")
+	addSSEFlags(DownloadFileCmd, &downloadSSE, false)
+	DownloadFileCmd.Flags().BoolVar(&downloadRaw, "raw", false, "keep compressed content as stored, with a .gz or .zst extension")
+	DownloadFileCmd.Flags().StringVar(&downloadMasterKey, "master-key-file", "", "master key of client-side encrypted objects (default: master_key_file of the profile)")
 	RootCmd.AddCommand(DownloadFileCmd)
 }
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+)
+
+// ServerSideEncryptionByDefault représente le chiffrement appliqué par défaut aux nouveaux objets
+type ServerSideEncryptionByDefault struct {
+	SSEAlgorithm   string `xml:"SSEAlgorithm"`
+	KMSMasterKeyID string `xml:"KMSMasterKeyID,omitempty"`
+}
+
+// ServerSideEncryptionRule représente une règle de la configuration de chiffrement
+type ServerSideEncryptionRule struct {
+	ApplyServerSideEncryptionByDefault ServerSideEncryptionByDefault `xml:"ApplyServerSideEncryptionByDefault"`
+	BucketKeyEnabled                   bool                          `xml:"BucketKeyEnabled,omitempty"`
+}
+
+// ServerSideEncryptionConfiguration représente le document XML de la sous-ressource ?encryption
+type ServerSideEncryptionConfiguration struct {
+	XMLName xml.Name                   `xml:"ServerSideEncryptionConfiguration"`
+	Xmlns   string                     `xml:"xmlns,attr,omitempty"`
+	Rules   []ServerSideEncryptionRule `xml:"Rule"`
+}
+
+var (
+	encryptionSSE       sseFlags
+	encryptionBucketKey bool
+)
+
+// EncryptionCmd regroupe les sous-commandes du chiffrement par défaut d'un bucket
+var EncryptionCmd = &cobra.Command{
+	Use:   "encryption",
+	Short: "Reads or sets the default server-side encryption of a bucket",
+}
+
+var encryptionGetCmd = &cobra.Command{
+	Use:   "get <bucket-name>",
+	Short: "Shows the default encryption applied to new objects of a bucket",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 {
+			log.Println("Usage: encryption get <bucket-name>")
+			return
+		}
+		bucketName := args[0]
+		client, err := newConfiguredClient()
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+
+		config, err := getEncryption(cmd.Context(), client, bucketName)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		if config == nil || len(config.Rules) == 0 {
+			fmt.Printf("Bucket '%s' has no default encryption.\n", bucketName)
+			return
+		}
+		rule := config.Rules[0]
+		fmt.Printf("Default encryption of bucket '%s': %s\n", bucketName, describeDefaultEncryption(rule))
+	},
+}
+
+var encryptionPutCmd = &cobra.Command{
+	Use:   "put <bucket-name> --sse <AES256|aws:kms>",
+	Short: "Sets the default encryption applied to new objects of a bucket",
+	Long: `Sets the encryption S3 applies to objects written without encryption headers.
+For example:
+
+bs3 encryption put archives --sse aws:kms --sse-kms-key-id alias/archives --bucket-key`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 || encryptionSSE.algorithm == "" {
+			log.Println("Usage: encryption put <bucket-name> --sse <AES256|aws:kms> [--sse-kms-key-id <key>] [--bucket-key]")
+			return
+		}
+		bucketName := args[0]
+		sse, err := encryptionSSE.options()
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		if encryptionBucketKey && sse.algorithm != sseKMS {
+			log.Println("Error: --bucket-key only applies to --sse aws:kms")
+			return
+		}
+
+		rule := ServerSideEncryptionRule{
+			ApplyServerSideEncryptionByDefault: ServerSideEncryptionByDefault{SSEAlgorithm: sse.algorithm, KMSMasterKeyID: sse.kmsKeyID},
+			BucketKeyEnabled:                   encryptionBucketKey,
+		}
+		body, err := xml.Marshal(ServerSideEncryptionConfiguration{Xmlns: s3XMLNamespace, Rules: []ServerSideEncryptionRule{rule}})
+		if err != nil {
+			log.Printf("Error marshalling XML: %v", err)
+			return
+		}
+
+		client, err := newConfiguredClient()
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		if err := client.putSubresource(cmd.Context(), bucketName, "", "encryption", nil, body, nil); err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		fmt.Printf("Default encryption of bucket '%s' set to %s.\n", bucketName, describeDefaultEncryption(rule))
+	},
+}
+
+// getEncryption lit le chiffrement par défaut du bucket ; nil (sans erreur) s'il n'en a pas
+func getEncryption(ctx context.Context, client *s3Client, bucketName string) (*ServerSideEncryptionConfiguration, error) {
+	data, err := client.getSubresource(ctx, bucketName, "", "encryption", nil)
+	if s3Err, ok := err.(*S3Error); ok && s3Err.Code == "ServerSideEncryptionConfigurationNotFoundError" {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var config ServerSideEncryptionConfiguration
+	if err := xml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse encryption configuration: %w", err)
+	}
+	return &config, nil
+}
+
+func describeDefaultEncryption(rule ServerSideEncryptionRule) string {
+	byDefault := rule.ApplyServerSideEncryptionByDefault
+	switch byDefault.SSEAlgorithm {
+	case sseS3:
+		return "SSE-S3 (AES256)"
+	case sseKMS:
+		text := "SSE-KMS"
+		if byDefault.KMSMasterKeyID != "" {
+			text += fmt.Sprintf(" (key %s)", byDefault.KMSMasterKeyID)
+		}
+		if rule.BucketKeyEnabled {
+			text += ", bucket key enabled"
+		}
+		return text
+	default:
+		return byDefault.SSEAlgorithm
+	}
+}
+
+func init() {
+	encryptionPutCmd.Flags().StringVar(&encryptionSSE.algorithm, "sse", "", "default encryption: AES256 (SSE-S3) or aws:kms (SSE-KMS)")
+	encryptionPutCmd.Flags().StringVar(&encryptionSSE.kmsKeyID, "sse-kms-key-id", "", "KMS key used with --sse aws:kms (default: the account's S3 key)")
+	encryptionPutCmd.Flags().BoolVar(&encryptionBucketKey, "bucket-key", false, "enable an S3 bucket key to reduce KMS requests (aws:kms only)")
+	EncryptionCmd.AddCommand(encryptionGetCmd, encryptionPutCmd)
+	RootCmd.AddCommand(EncryptionCmd)
+}
@@ -0,0 +1,271 @@
+package cmd
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+)
+
+// Chiffrement côté client « par enveloppe » : chaque objet est chiffré avec sa propre clé de données,
+// elle-même chiffrée (enveloppée) par la clé maîtresse locale et stockée dans les métadonnées de l'objet.
+// Le contenu est découpé en blocs scellés séparément par AES-256-GCM ; le nonce d'un bloc est formé
+// d'un préfixe aléatoire, du numéro du bloc et d'un indicateur de dernier bloc, ce qui rend détectables
+// la réorganisation et la troncature des blocs.
+const (
+	envelopeAlgorithm = "AES-256-GCM-CHUNKED"
+	envelopeChunkSize = 64 << 10
+
+	// envelopeNoncePrefixLen + 4 octets de compteur + 1 octet de fin = taille du nonce GCM (12)
+	envelopeNoncePrefixLen = 7
+)
+
+// En-têtes de métadonnées portant les paramètres de l'enveloppe
+const (
+	metaEnvelopeAlgorithm = "X-Amz-Meta-Bs3-Cse-Algorithm"
+	metaEnvelopeKey       = "X-Amz-Meta-Bs3-Cse-Wrapped-Key"
+	metaEnvelopeKeyID     = "X-Amz-Meta-Bs3-Cse-Master-Key-Id"
+	metaEnvelopeNonce     = "X-Amz-Meta-Bs3-Cse-Nonce"
+	metaEnvelopeChunkSize = "X-Amz-Meta-Bs3-Cse-Chunk-Size"
+	metaEnvelopeLength    = "X-Amz-Meta-Bs3-Cse-Plaintext-Length"
+)
+
+// errEnvelopeAuth signale un contenu chiffré qui ne s'authentifie pas
+var errEnvelopeAuth = errors.New("authentication failed: the encrypted content was modified or truncated")
+
+// envelope décrit le chiffrement d'un objet : clé de données en clair, préfixe des nonces
+// et taille du contenu en clair (-1 si inconnue)
+type envelope struct {
+	aead        cipher.AEAD
+	noncePrefix []byte
+	chunkSize   int
+	length      int64
+}
+
+// loadMasterKey lit la clé maîtresse : --master-key-file, sinon master_key_file dans le profil
+func loadMasterKey(path string, profile *Profile) ([]byte, error) {
+	if path == "" {
+		path = profile.MasterKeyFile
+	}
+	if path == "" {
+		return nil, fmt.Errorf("no master key: pass --master-key-file or set master_key_file in the profile")
+	}
+	return readKeyFile(path)
+}
+
+// masterKeyID identifie une clé maîtresse sans la révéler (début de son empreinte SHA-256)
+func masterKeyID(masterKey []byte) string {
+	sum := sha256.Sum256(masterKey)
+	return hex.EncodeToString(sum[:8])
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealEnvelope tire une clé de données et un préfixe de nonce, puis ajoute aux en-têtes
+// les métadonnées de l'enveloppe (clé de données enveloppée par la clé maîtresse).
+// plaintextLength vaut -1 pour un flux de taille inconnue (contenu compressé à la volée).
+func sealEnvelope(masterKey []byte, plaintextLength int64, header http.Header) (*envelope, error) {
+	dataKey := make([]byte, 32)
+	noncePrefix := make([]byte, envelopeNoncePrefixLen)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, err
+	}
+	if _, err := rand.Read(noncePrefix); err != nil {
+		return nil, err
+	}
+
+	master, err := newGCM(masterKey)
+	if err != nil {
+		return nil, err
+	}
+	wrapNonce := make([]byte, master.NonceSize())
+	if _, err := rand.Read(wrapNonce); err != nil {
+		return nil, err
+	}
+	wrapped := master.Seal(wrapNonce, wrapNonce, dataKey, []byte(envelopeAlgorithm))
+
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	header.Set(metaEnvelopeAlgorithm, envelopeAlgorithm)
+	header.Set(metaEnvelopeKey, base64.StdEncoding.EncodeToString(wrapped))
+	header.Set(metaEnvelopeKeyID, masterKeyID(masterKey))
+	header.Set(metaEnvelopeNonce, base64.StdEncoding.EncodeToString(noncePrefix))
+	header.Set(metaEnvelopeChunkSize, strconv.Itoa(envelopeChunkSize))
+	if plaintextLength >= 0 {
+		header.Set(metaEnvelopeLength, strconv.FormatInt(plaintextLength, 10))
+	}
+	return &envelope{aead: aead, noncePrefix: noncePrefix, chunkSize: envelopeChunkSize, length: plaintextLength}, nil
+}
+
+// isEnveloped indique si les métadonnées d'un objet décrivent un chiffrement côté client
+func isEnveloped(header http.Header) bool {
+	return header.Get(metaEnvelopeAlgorithm) != ""
+}
+
+// openEnvelope retrouve la clé de données d'un objet à partir de ses métadonnées et de la clé maîtresse ;
+// retourne aussi la taille du contenu en clair (-1 si elle n'a pas été enregistrée)
+func openEnvelope(masterKey []byte, header http.Header) (*envelope, int64, error) {
+	if algorithm := header.Get(metaEnvelopeAlgorithm); algorithm != envelopeAlgorithm {
+		return nil, 0, fmt.Errorf("unsupported client-side encryption algorithm '%s'", algorithm)
+	}
+	if keyID := header.Get(metaEnvelopeKeyID); keyID != "" && keyID != masterKeyID(masterKey) {
+		return nil, 0, fmt.Errorf("the object was encrypted with another master key (key id %s, this key is %s)", keyID, masterKeyID(masterKey))
+	}
+	wrapped, err := base64.StdEncoding.DecodeString(header.Get(metaEnvelopeKey))
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid wrapped key in the object metadata")
+	}
+	noncePrefix, err := base64.StdEncoding.DecodeString(header.Get(metaEnvelopeNonce))
+	if err != nil || len(noncePrefix) != envelopeNoncePrefixLen {
+		return nil, 0, fmt.Errorf("invalid nonce in the object metadata")
+	}
+	chunkSize, err := strconv.Atoi(header.Get(metaEnvelopeChunkSize))
+	if err != nil || chunkSize <= 0 || chunkSize > 16<<20 {
+		return nil, 0, fmt.Errorf("invalid chunk size in the object metadata")
+	}
+	length := int64(-1)
+	if value := header.Get(metaEnvelopeLength); value != "" {
+		if length, err = strconv.ParseInt(value, 10, 64); err != nil || length < 0 {
+			return nil, 0, fmt.Errorf("invalid plaintext length in the object metadata")
+		}
+	}
+
+	master, err := newGCM(masterKey)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(wrapped) < master.NonceSize() {
+		return nil, 0, fmt.Errorf("invalid wrapped key in the object metadata")
+	}
+	dataKey, err := master.Open(nil, wrapped[:master.NonceSize()], wrapped[master.NonceSize():], []byte(envelopeAlgorithm))
+	if err != nil {
+		return nil, 0, fmt.Errorf("cannot unwrap the data key: wrong master key or altered metadata")
+	}
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return nil, 0, err
+	}
+	return &envelope{aead: aead, noncePrefix: noncePrefix, chunkSize: chunkSize, length: length}, length, nil
+}
+
+// encryptedSize calcule la taille chiffrée d'un contenu : un bloc d'au moins zéro octet, plus
+// le code d'authentification de chaque bloc
+func (e *envelope) encryptedSize(plaintextLength int64) int64 {
+	chunks := (plaintextLength + int64(e.chunkSize) - 1) / int64(e.chunkSize)
+	return plaintextLength + max(chunks, 1)*int64(e.aead.Overhead())
+}
+
+func (e *envelope) nonce(counter uint32, last bool) []byte {
+	nonce := make([]byte, 0, e.aead.NonceSize())
+	nonce = append(nonce, e.noncePrefix...)
+	nonce = binary.BigEndian.AppendUint32(nonce, counter)
+	if last {
+		return append(nonce, 1)
+	}
+	return append(nonce, 0)
+}
+
+// chunkReader découpe un flux en blocs et applique transform à chacun. Un octet est lu d'avance
+// pour savoir si le bloc courant est le dernier.
+type chunkReader struct {
+	src       io.Reader
+	buf       []byte
+	out       []byte
+	lookahead []byte
+	counter   uint32
+	done      bool
+	transform func(chunk []byte, counter uint32, last bool) ([]byte, error)
+}
+
+func newChunkReader(src io.Reader, chunkSize int, transform func([]byte, uint32, bool) ([]byte, error)) *chunkReader {
+	return &chunkReader{src: src, buf: make([]byte, chunkSize+1), transform: transform}
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	return n, nil
+}
+
+func (r *chunkReader) next() error {
+	start := copy(r.buf, r.lookahead)
+	n, err := io.ReadFull(r.src, r.buf[start:])
+	total := start + n
+	last := false
+	switch {
+	case err == io.EOF || err == io.ErrUnexpectedEOF:
+		last = true
+	case err != nil:
+		return err
+	default:
+		// Le bloc est complet et au moins un octet suit : il est conservé pour le bloc suivant
+		total--
+		r.lookahead = append(r.lookahead[:0], r.buf[total])
+	}
+	if last {
+		r.lookahead = nil
+	}
+	if r.counter == math.MaxUint32 {
+		return fmt.Errorf("content too large for client-side encryption")
+	}
+
+	out, err := r.transform(r.buf[:total], r.counter, last)
+	if err != nil {
+		return err
+	}
+	r.out, r.done = out, last
+	r.counter++
+	return nil
+}
+
+// encrypt retourne un flux chiffré bloc par bloc à partir du contenu en clair
+func (e *envelope) encrypt(src io.Reader) io.Reader {
+	var sealed []byte
+	return newChunkReader(src, e.chunkSize, func(chunk []byte, counter uint32, last bool) ([]byte, error) {
+		sealed = e.aead.Seal(sealed[:0], e.nonce(counter, last), chunk, nil)
+		return sealed, nil
+	})
+}
+
+// decrypt retourne le contenu en clair d'un flux chiffré ; un bloc modifié, déplacé ou manquant
+// produit errEnvelopeAuth, de même qu'une taille différente de celle des métadonnées
+func (e *envelope) decrypt(src io.Reader) io.Reader {
+	var opened []byte
+	var total int64
+	return newChunkReader(src, e.chunkSize+e.aead.Overhead(), func(chunk []byte, counter uint32, last bool) ([]byte, error) {
+		var err error
+		if opened, err = e.aead.Open(opened[:0], e.nonce(counter, last), chunk, nil); err != nil {
+			return nil, errEnvelopeAuth
+		}
+		total += int64(len(opened))
+		if last && e.length >= 0 && total != e.length {
+			return nil, errEnvelopeAuth
+		}
+		return opened, nil
+	})
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	headLines int
+	headBytes int64
+)
+
+// HeadCmd représente la commande head
+var HeadCmd = &cobra.Command{
+	Use:   "head <bucket-name> <object-key> | head s3://bucket/key",
+	Short: "Prints the first lines or bytes of an object using Range requests",
+	Long: `Prints the beginning of an object without downloading it: only the needed byte ranges are read.
+For example:
+
+bs3 head -n 20 s3://bucket/logs/app.log
+bs3 head -c 512 s3://bucket/data.bin`,
+	Run: func(cmd *cobra.Command, args []string) {
+		bucketName, key, _, err := objectArgs(args)
+		if err != nil {
+			log.Printf("Usage: head [-n lines | -c bytes] <bucket-name> <object-key> (%v)", err)
+			return
+		}
+		if headLines < 0 || headBytes < 0 {
+			log.Println("Error: -n and -c must not be negative")
+			return
+		}
+
+		// Récupérer l'URL de l'API depuis le profil actif
+		client, err := newConfiguredClient()
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+
+		var data []byte
+		if cmd.Flags().Changed("bytes") {
+			if headBytes > 0 {
+				var res *rangeResult
+				res, err = readRange(cmd.Context(), client, bucketName, key, fmt.Sprintf("bytes=0-%d", headBytes-1))
+				if res != nil {
+					data = res.data[:min(int64(len(res.data)), headBytes)]
+				}
+			}
+		} else {
+			data, err = readHead(cmd.Context(), client, bucketName, key, headLines)
+		}
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		os.Stdout.Write(data)
+	},
+}
+
+func init() {
+	HeadCmd.Flags().IntVarP(&headLines, "lines", "n", 10, "number of lines to print")
+	HeadCmd.Flags().Int64VarP(&headBytes, "bytes", "c", 0, "number of bytes to print (instead of lines)")
+	RootCmd.AddCommand(HeadCmd)
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"encoding/xml"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var legalHoldVersionID string
+
+// LegalHoldCmd regroupe les sous-commandes de suspension légale (legal hold) d'un objet
+var LegalHoldCmd = &cobra.Command{
+	Use:   "legal-hold",
+	Short: "Places or removes a legal hold on an object",
+	Long: `Places or removes an Object Lock legal hold. While the hold is on, the object version
+cannot be deleted or overwritten, whatever its retention; the hold has no expiry date.
+The bucket must have been created with create-bucket --object-lock.
+For example:
+
+bs3 legal-hold on s3://records/2024/ledger.csv
+bs3 legal-hold off records 2024/ledger.csv --version-id 3HL4kqtJlcpXroDTDmJ`,
+}
+
+var legalHoldOnCmd = &cobra.Command{
+	Use:   "on <bucket-name> <object-key>",
+	Short: "Places a legal hold on an object",
+	Run: func(cmd *cobra.Command, args []string) {
+		setLegalHold(cmd, args, "ON")
+	},
+}
+
+var legalHoldOffCmd = &cobra.Command{
+	Use:   "off <bucket-name> <object-key>",
+	Short: "Removes the legal hold of an object",
+	Run: func(cmd *cobra.Command, args []string) {
+		setLegalHold(cmd, args, "OFF")
+	},
+}
+
+// setLegalHold applique le statut ON ou OFF à l'objet (ou à la version --version-id)
+func setLegalHold(cmd *cobra.Command, args []string, status string) {
+	bucketName, key, _, err := objectArgs(args)
+	if err != nil {
+		log.Printf("Usage: legal-hold %s <bucket-name> <object-key> [--version-id <id>] (%v)", strings.ToLower(status), err)
+		return
+	}
+	client, err := newConfiguredClient()
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+
+	body, err := xml.Marshal(LegalHold{Xmlns: s3XMLNamespace, Status: status})
+	if err != nil {
+		log.Printf("Error marshalling XML: %v", err)
+		return
+	}
+	if err := client.putSubresource(cmd.Context(), bucketName, key, "legal-hold", versionQuery(legalHoldVersionID), body, nil); err != nil {
+		log.Printf("Error: %v", objectLockError(err, bucketName))
+		return
+	}
+	if status == "ON" {
+		fmt.Printf("Legal hold placed on object '%s' in bucket '%s'.\n", key, bucketName)
+	} else {
+		fmt.Printf("Legal hold removed from object '%s' in bucket '%s'.\n", key, bucketName)
+	}
+}
+
+func init() {
+	for _, c := range []*cobra.Command{legalHoldOnCmd, legalHoldOffCmd} {
+		c.Flags().StringVar(&legalHoldVersionID, "version-id", "", "target a specific version of the object")
+	}
+	LegalHoldCmd.AddCommand(legalHoldOnCmd, legalHoldOffCmd)
+	RootCmd.AddCommand(LegalHoldCmd)
+}
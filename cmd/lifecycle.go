@@ -0,0 +1,250 @@
+package cmd
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	lifecycleRawXML bool
+	simulateDate    string
+	simulatePrefix  string
+)
+
+// LifecycleCmd regroupe les sous-commandes de gestion du cycle de vie d'un bucket
+var LifecycleCmd = &cobra.Command{
+	Use:   "lifecycle",
+	Short: "Reads, replaces, deletes or simulates the lifecycle rules of a bucket",
+}
+
+var lifecycleGetCmd = &cobra.Command{
+	Use:   "get <bucket-name>",
+	Short: "Prints the lifecycle rules of a bucket (YAML, or the S3 XML with --xml)",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 {
+			log.Println("Usage: lifecycle get <bucket-name> [--xml]")
+			return
+		}
+		bucketName := args[0]
+		client, err := newConfiguredClient()
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+
+		config, raw, err := getLifecycle(cmd.Context(), client, bucketName)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		if config == nil {
+			fmt.Printf("No lifecycle configuration on bucket '%s'.\n", bucketName)
+			return
+		}
+		if lifecycleRawXML {
+			fmt.Println(string(raw))
+			return
+		}
+		text, err := lifecycleSpecYAML(config)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		fmt.Print(text)
+	},
+}
+
+var lifecyclePutCmd = &cobra.Command{
+	Use:   "put <bucket-name> <rules-file|->",
+	Short: "Replaces the lifecycle rules of a bucket from a YAML file",
+	Long: `Replaces the lifecycle rules of a bucket. Rules are written in YAML (or JSON):
+
+rules:
+  - id: expire-logs
+    prefix: logs/
+    expire_days: 30
+    transitions:
+      - days: 7
+        storage_class: STANDARD_IA
+    noncurrent_expire_days: 7
+    keep_newer_noncurrent: 2
+    abort_multipart_days: 3
+  - id: temp-files
+    tags: {retention: temporary}
+    expire_date: 2025-01-01
+    enabled: false
+
+Use - to read the rules from standard input.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 2 {
+			log.Println("Usage: lifecycle put <bucket-name> <rules-file|->")
+			return
+		}
+		bucketName := args[0]
+
+		var data []byte
+		var err error
+		if args[1] == "-" {
+			data, err = io.ReadAll(cmd.InOrStdin())
+		} else {
+			data, err = os.ReadFile(args[1])
+		}
+		if err != nil {
+			log.Printf("Error reading lifecycle rules: %v", err)
+			return
+		}
+		config, err := parseLifecycleSpec(data)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		body, err := xml.Marshal(config)
+		if err != nil {
+			log.Printf("Error marshalling XML: %v", err)
+			return
+		}
+
+		client, err := newConfiguredClient()
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		if err := client.putSubresource(cmd.Context(), bucketName, "", "lifecycle", nil, body, nil); err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		fmt.Printf("Lifecycle configuration of bucket '%s' replaced (%d rule(s)).\n", bucketName, len(config.Rules))
+	},
+}
+
+var lifecycleDeleteCmd = &cobra.Command{
+	Use:   "delete <bucket-name>",
+	Short: "Deletes the lifecycle configuration of a bucket",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 {
+			log.Println("Usage: lifecycle delete <bucket-name>")
+			return
+		}
+		bucketName := args[0]
+		client, err := newConfiguredClient()
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+
+		if err := client.deleteSubresource(cmd.Context(), bucketName, "", "lifecycle", nil); err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		fmt.Printf("Lifecycle configuration deleted from bucket '%s'.\n", bucketName)
+	},
+}
+
+var lifecycleSimulateCmd = &cobra.Command{
+	Use:   "simulate <bucket-name> --date <date>",
+	Short: "Lists the current objects that the lifecycle rules would expire or transition by a date",
+	Long: `Applies the bucket's enabled lifecycle rules to its current objects and lists those that
+would expire or change storage class by --date (default: now). Like S3, a rule based on a
+number of days applies at the first midnight UTC after that many days since the object was written.
+For example:
+
+bs3 lifecycle simulate logs --date 2025-06-30 --prefix app/`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 {
+			log.Println("Usage: lifecycle simulate <bucket-name> [--date <date>] [--prefix <prefix>]")
+			return
+		}
+		bucketName := args[0]
+		until := time.Now()
+		if day, err := time.Parse("2006-01-02", simulateDate); err == nil {
+			// Une date seule couvre toute la journée (UTC), comme les actions de S3 datées à minuit UTC
+			until = day.Add(24*time.Hour - time.Second)
+		} else if simulateDate != "" {
+			if until, err = parseTimestamp(simulateDate, time.Now()); err != nil {
+				log.Printf("Error: %v", err)
+				return
+			}
+		}
+		client, err := newConfiguredClient()
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+
+		config, _, err := getLifecycle(cmd.Context(), client, bucketName)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		if config == nil {
+			fmt.Printf("Bucket '%s' has no lifecycle configuration: no object would expire.\n", bucketName)
+			return
+		}
+		if err := config.parseDates(); err != nil {
+			log.Printf("Error: invalid lifecycle configuration on bucket '%s': %v", bucketName, err)
+			return
+		}
+		objects, err := listObjects(cmd.Context(), client, bucketName, simulatePrefix)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+
+		// Les tags ne sont lus que si une règle active filtre dessus
+		tags := map[string]map[string]string{}
+		if config.usesTags() {
+			keys := make([]string, len(objects))
+			for i, obj := range objects {
+				keys[i] = obj.Key
+			}
+			if tags, err = fetchTags(cmd.Context(), client, bucketName, keys); err != nil {
+				log.Printf("Error: %v", err)
+				return
+			}
+		}
+
+		events := simulateLifecycle(config, objects, tags, until)
+		fmt.Printf("Lifecycle of bucket '%s' as of %s:\n", bucketName, until.UTC().Format("2006-01-02 15:04:05 MST"))
+		expiring := 0
+		for _, event := range events {
+			if event.Action == "expire" {
+				expiring++
+				fmt.Printf("  expire      %s on %s (%s)\n", event.Key, event.Date.Format("2006-01-02"), event.Rule)
+			} else {
+				fmt.Printf("  transition  %s to %s on %s (%s)\n", event.Key, event.StorageClass, event.Date.Format("2006-01-02"), event.Rule)
+			}
+		}
+		fmt.Printf("%d object(s) would expire, %d would transition, %d unaffected.\n", expiring, len(events)-expiring, len(objects)-len(events))
+	},
+}
+
+// getLifecycle lit la configuration de cycle de vie ; nil (sans erreur) si le bucket n'en a pas
+func getLifecycle(ctx context.Context, client *s3Client, bucketName string) (*LifecycleConfiguration, []byte, error) {
+	data, err := client.getSubresource(ctx, bucketName, "", "lifecycle", nil)
+	if s3Err, ok := err.(*S3Error); ok && s3Err.Code == "NoSuchLifecycleConfiguration" {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	var config LifecycleConfiguration
+	if err := xml.Unmarshal(data, &config); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse lifecycle configuration: %w", err)
+	}
+	return &config, data, nil
+}
+
+func init() {
+	lifecycleGetCmd.Flags().BoolVar(&lifecycleRawXML, "xml", false, "print the S3 LifecycleConfiguration XML as returned by the server")
+	lifecycleSimulateCmd.Flags().StringVar(&simulateDate, "date", "", "date to simulate (YYYY-MM-DD for the whole day in UTC, or RFC 3339; default: now)")
+	lifecycleSimulateCmd.Flags().StringVar(&simulatePrefix, "prefix", "", "only consider keys starting with this prefix")
+	LifecycleCmd.AddCommand(lifecycleGetCmd, lifecyclePutCmd, lifecycleDeleteCmd, lifecycleSimulateCmd)
+	RootCmd.AddCommand(LifecycleCmd)
+}
@@ -0,0 +1,423 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxLifecycleRules est la limite de S3 sur le nombre de règles
+const maxLifecycleRules = 1000
+
+// LifecycleFilter sélectionne les objets visés par une règle : préfixe, tags, ou les deux (And)
+type LifecycleFilter struct {
+	Prefix *string         `xml:"Prefix,omitempty"`
+	Tag    *Tag            `xml:"Tag,omitempty"`
+	And    *LifecycleAndOp `xml:"And,omitempty"`
+}
+
+// LifecycleAndOp combine un préfixe et plusieurs tags
+type LifecycleAndOp struct {
+	Prefix string `xml:"Prefix,omitempty"`
+	Tags   []Tag  `xml:"Tag"`
+}
+
+// LifecycleExpiration expire les versions courantes après un nombre de jours ou à une date
+type LifecycleExpiration struct {
+	Days                      int    `xml:"Days,omitempty"`
+	Date                      string `xml:"Date,omitempty"`
+	ExpiredObjectDeleteMarker bool   `xml:"ExpiredObjectDeleteMarker,omitempty"`
+
+	at time.Time // Date lue par parseDates
+}
+
+// LifecycleTransition change la classe de stockage des versions courantes
+type LifecycleTransition struct {
+	Days         int    `xml:"Days,omitempty"`
+	Date         string `xml:"Date,omitempty"`
+	StorageClass string `xml:"StorageClass"`
+
+	at time.Time // Date lue par parseDates
+}
+
+// NoncurrentVersionExpiration supprime les anciennes versions
+type NoncurrentVersionExpiration struct {
+	NoncurrentDays          int `xml:"NoncurrentDays"`
+	NewerNoncurrentVersions int `xml:"NewerNoncurrentVersions,omitempty"`
+}
+
+// AbortIncompleteMultipartUpload abandonne les uploads multipart inachevés
+type AbortIncompleteMultipartUpload struct {
+	DaysAfterInitiation int `xml:"DaysAfterInitiation"`
+}
+
+// LifecycleRule représente une règle de cycle de vie S3. Prefix est la forme historique du filtre,
+// encore renvoyée pour les règles créées sans Filter.
+type LifecycleRule struct {
+	ID                             string                          `xml:"ID,omitempty"`
+	Prefix                         *string                         `xml:"Prefix,omitempty"`
+	Filter                         LifecycleFilter                 `xml:"Filter"`
+	Status                         string                          `xml:"Status"`
+	Expiration                     *LifecycleExpiration            `xml:"Expiration,omitempty"`
+	Transitions                    []LifecycleTransition           `xml:"Transition,omitempty"`
+	NoncurrentVersionExpiration    *NoncurrentVersionExpiration    `xml:"NoncurrentVersionExpiration,omitempty"`
+	AbortIncompleteMultipartUpload *AbortIncompleteMultipartUpload `xml:"AbortIncompleteMultipartUpload,omitempty"`
+}
+
+// LifecycleConfiguration représente le document XML de la sous-ressource ?lifecycle
+type LifecycleConfiguration struct {
+	XMLName xml.Name        `xml:"LifecycleConfiguration"`
+	Xmlns   string          `xml:"xmlns,attr,omitempty"`
+	Rules   []LifecycleRule `xml:"Rule"`
+}
+
+// lifecycleRuleSpec est la forme YAML (ou JSON) d'une règle
+type lifecycleRuleSpec struct {
+	ID                   string               `yaml:"id,omitempty" json:"id,omitempty"`
+	Prefix               string               `yaml:"prefix,omitempty" json:"prefix,omitempty"`
+	Tags                 map[string]string    `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Enabled              *bool                `yaml:"enabled,omitempty" json:"enabled,omitempty"`
+	ExpireDays           int                  `yaml:"expire_days,omitempty" json:"expire_days,omitempty"`
+	ExpireDate           string               `yaml:"expire_date,omitempty" json:"expire_date,omitempty"`
+	ExpireDeleteMarkers  bool                 `yaml:"expire_delete_markers,omitempty" json:"expire_delete_markers,omitempty"`
+	Transitions          []lifecycleTransSpec `yaml:"transitions,omitempty" json:"transitions,omitempty"`
+	NoncurrentExpireDays int                  `yaml:"noncurrent_expire_days,omitempty" json:"noncurrent_expire_days,omitempty"`
+	KeepNewerNoncurrent  int                  `yaml:"keep_newer_noncurrent,omitempty" json:"keep_newer_noncurrent,omitempty"`
+	AbortMultipartDays   int                  `yaml:"abort_multipart_days,omitempty" json:"abort_multipart_days,omitempty"`
+}
+
+type lifecycleTransSpec struct {
+	Days         int    `yaml:"days,omitempty" json:"days,omitempty"`
+	Date         string `yaml:"date,omitempty" json:"date,omitempty"`
+	StorageClass string `yaml:"storage_class" json:"storage_class"`
+}
+
+type lifecycleSpec struct {
+	Rules []lifecycleRuleSpec `yaml:"rules" json:"rules"`
+}
+
+// parseLifecycleSpec lit des règles de cycle de vie écrites en YAML (ou JSON) et les convertit en XML S3
+func parseLifecycleSpec(data []byte) (*LifecycleConfiguration, error) {
+	var spec lifecycleSpec
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&spec); err != nil {
+		return nil, fmt.Errorf("invalid lifecycle rules: %w", err)
+	}
+	return spec.configuration()
+}
+
+// configuration convertit les règles lisibles en configuration S3 et la valide
+func (spec lifecycleSpec) configuration() (*LifecycleConfiguration, error) {
+	config := &LifecycleConfiguration{Xmlns: s3XMLNamespace}
+	for _, r := range spec.Rules {
+		rule := LifecycleRule{ID: r.ID, Status: "Enabled"}
+		if r.Enabled != nil && !*r.Enabled {
+			rule.Status = "Disabled"
+		}
+
+		switch {
+		case len(r.Tags) == 0:
+			prefix := r.Prefix
+			rule.Filter.Prefix = &prefix
+		case len(r.Tags) == 1 && r.Prefix == "":
+			for name, value := range r.Tags {
+				rule.Filter.Tag = &Tag{Key: name, Value: value}
+			}
+		default:
+			and := &LifecycleAndOp{Prefix: r.Prefix}
+			for _, name := range sortedTagKeys(r.Tags) {
+				and.Tags = append(and.Tags, Tag{Key: name, Value: r.Tags[name]})
+			}
+			rule.Filter.And = and
+		}
+
+		if r.ExpireDays > 0 || r.ExpireDate != "" || r.ExpireDeleteMarkers {
+			rule.Expiration = &LifecycleExpiration{Days: r.ExpireDays, ExpiredObjectDeleteMarker: r.ExpireDeleteMarkers}
+			if r.ExpireDate != "" {
+				date, err := lifecycleDate(r.ExpireDate)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", lifecycleRuleName(len(config.Rules), r.ID), err)
+				}
+				rule.Expiration.Date = date
+			}
+		}
+		for _, t := range r.Transitions {
+			transition := LifecycleTransition{Days: t.Days, StorageClass: t.StorageClass}
+			if t.Date != "" {
+				date, err := lifecycleDate(t.Date)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", lifecycleRuleName(len(config.Rules), r.ID), err)
+				}
+				transition.Date = date
+			}
+			rule.Transitions = append(rule.Transitions, transition)
+		}
+		if r.NoncurrentExpireDays > 0 || r.KeepNewerNoncurrent > 0 {
+			rule.NoncurrentVersionExpiration = &NoncurrentVersionExpiration{NoncurrentDays: r.NoncurrentExpireDays, NewerNoncurrentVersions: r.KeepNewerNoncurrent}
+		}
+		if r.AbortMultipartDays > 0 {
+			rule.AbortIncompleteMultipartUpload = &AbortIncompleteMultipartUpload{DaysAfterInitiation: r.AbortMultipartDays}
+		}
+		config.Rules = append(config.Rules, rule)
+	}
+
+	if err := validateLifecycle(config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// lifecycleDate convertit une date AAAA-MM-JJ en minuit UTC, seule forme acceptée par S3
+func lifecycleDate(value string) (string, error) {
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return "", fmt.Errorf("invalid date '%s' (expected YYYY-MM-DD)", value)
+	}
+	return date.Format("2006-01-02T15:04:05.000Z"), nil
+}
+
+// validateLifecycle vérifie que chaque règle a au moins une action et des durées cohérentes
+func validateLifecycle(config *LifecycleConfiguration) error {
+	if len(config.Rules) == 0 {
+		return fmt.Errorf("the lifecycle configuration must contain at least one rule")
+	}
+	if len(config.Rules) > maxLifecycleRules {
+		return fmt.Errorf("the lifecycle configuration can contain at most %d rules (got %d)", maxLifecycleRules, len(config.Rules))
+	}
+	ids := map[string]bool{}
+	for i, rule := range config.Rules {
+		name := lifecycleRuleName(i, rule.ID)
+		if rule.ID != "" {
+			if ids[rule.ID] {
+				return fmt.Errorf("%s: duplicate id", name)
+			}
+			ids[rule.ID] = true
+		}
+		if len(rule.ID) > 255 {
+			return fmt.Errorf("%s: id is longer than 255 characters", name)
+		}
+		if rule.Expiration == nil && len(rule.Transitions) == 0 && rule.NoncurrentVersionExpiration == nil && rule.AbortIncompleteMultipartUpload == nil {
+			return fmt.Errorf("%s: at least one action is required (expire_days, expire_date, transitions, noncurrent_expire_days or abort_multipart_days)", name)
+		}
+		if e := rule.Expiration; e != nil {
+			if e.Days < 0 || (e.Days > 0 && e.Date != "") {
+				return fmt.Errorf("%s: use either expire_days (positive) or expire_date", name)
+			}
+			if e.ExpiredObjectDeleteMarker && (e.Days > 0 || e.Date != "") {
+				return fmt.Errorf("%s: expire_delete_markers cannot be combined with expire_days or expire_date", name)
+			}
+		}
+		for _, t := range rule.Transitions {
+			if t.StorageClass == "" {
+				return fmt.Errorf("%s: every transition needs a storage_class", name)
+			}
+			if (t.Days > 0) == (t.Date != "") {
+				return fmt.Errorf("%s: transition to %s needs either days or date", name, t.StorageClass)
+			}
+		}
+		if n := rule.NoncurrentVersionExpiration; n != nil && n.NoncurrentDays <= 0 {
+			return fmt.Errorf("%s: noncurrent_expire_days must be positive", name)
+		}
+		if rule.Filter.And != nil && rule.AbortIncompleteMultipartUpload != nil && len(rule.Filter.And.Tags) > 0 {
+			return fmt.Errorf("%s: abort_multipart_days cannot be used with a tag filter", name)
+		}
+	}
+	return nil
+}
+
+func lifecycleRuleName(i int, id string) string {
+	if id != "" {
+		return fmt.Sprintf("rule %d (%s)", i+1, id)
+	}
+	return fmt.Sprintf("rule %d", i+1)
+}
+
+// lifecycleSpecYAML convertit la configuration S3 dans sa forme YAML
+func lifecycleSpecYAML(config *LifecycleConfiguration) (string, error) {
+	return marshalYAML(lifecycleSpecOf(config))
+}
+
+// lifecycleSpecOf convertit la configuration S3 dans sa forme lisible (YAML ou JSON)
+func lifecycleSpecOf(config *LifecycleConfiguration) lifecycleSpec {
+	spec := lifecycleSpec{Rules: []lifecycleRuleSpec{}}
+	for _, rule := range config.Rules {
+		r := lifecycleRuleSpec{ID: rule.ID}
+		if rule.Status != "Enabled" {
+			disabled := false
+			r.Enabled = &disabled
+		}
+		r.Prefix, r.Tags = rule.filterPrefix(), rule.filterTags()
+		if e := rule.Expiration; e != nil {
+			r.ExpireDays, r.ExpireDeleteMarkers = e.Days, e.ExpiredObjectDeleteMarker
+			r.ExpireDate = shortDate(e.Date)
+		}
+		for _, t := range rule.Transitions {
+			r.Transitions = append(r.Transitions, lifecycleTransSpec{Days: t.Days, Date: shortDate(t.Date), StorageClass: t.StorageClass})
+		}
+		if n := rule.NoncurrentVersionExpiration; n != nil {
+			r.NoncurrentExpireDays, r.KeepNewerNoncurrent = n.NoncurrentDays, n.NewerNoncurrentVersions
+		}
+		if a := rule.AbortIncompleteMultipartUpload; a != nil {
+			r.AbortMultipartDays = a.DaysAfterInitiation
+		}
+		spec.Rules = append(spec.Rules, r)
+	}
+	return spec
+}
+
+func shortDate(value string) string {
+	if len(value) >= 10 {
+		return value[:10]
+	}
+	return value
+}
+
+func (r LifecycleRule) filterPrefix() string {
+	switch {
+	case r.Filter.And != nil:
+		return r.Filter.And.Prefix
+	case r.Filter.Prefix != nil:
+		return *r.Filter.Prefix
+	case r.Prefix != nil:
+		return *r.Prefix
+	}
+	return ""
+}
+
+func (r LifecycleRule) filterTags() map[string]string {
+	var tags []Tag
+	switch {
+	case r.Filter.And != nil:
+		tags = r.Filter.And.Tags
+	case r.Filter.Tag != nil:
+		tags = []Tag{*r.Filter.Tag}
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+	result := map[string]string{}
+	for _, tag := range tags {
+		result[tag.Key] = tag.Value
+	}
+	return result
+}
+
+// lifecycleEvent est une action qu'une règle appliquera à une version courante
+type lifecycleEvent struct {
+	Key          string
+	Rule         string
+	Action       string // "expire" ou "transition"
+	StorageClass string
+	Date         time.Time
+}
+
+// parseDates lit une fois les dates fixes des règles ; une date illisible est une erreur,
+// plutôt qu'une action échue depuis l'an 1
+func (c *LifecycleConfiguration) parseDates() error {
+	for i := range c.Rules {
+		rule := &c.Rules[i]
+		name := lifecycleRuleName(i, rule.ID)
+		if e := rule.Expiration; e != nil && e.Date != "" {
+			at, err := time.Parse(time.RFC3339, e.Date)
+			if err != nil {
+				return fmt.Errorf("%s: invalid expiration date '%s'", name, e.Date)
+			}
+			e.at = at
+		}
+		for j := range rule.Transitions {
+			t := &rule.Transitions[j]
+			if t.Date == "" {
+				continue
+			}
+			at, err := time.Parse(time.RFC3339, t.Date)
+			if err != nil {
+				return fmt.Errorf("%s: invalid date '%s' for the transition to %s", name, t.Date, t.StorageClass)
+			}
+			t.at = at
+		}
+	}
+	return nil
+}
+
+// lifecycleActionDate calcule la date d'une action : S3 compte les jours à partir de la création
+// et arrondit au minuit UTC suivant ; une date fixe (lue par parseDates) s'applique telle quelle
+func lifecycleActionDate(modified time.Time, days int, date time.Time) time.Time {
+	if !date.IsZero() {
+		return date
+	}
+	due := modified.UTC().AddDate(0, 0, days)
+	midnight := due.Truncate(24 * time.Hour)
+	if midnight.Before(due) {
+		midnight = midnight.Add(24 * time.Hour)
+	}
+	return midnight
+}
+
+// simulateLifecycle retourne, pour chaque objet, l'action la plus avancée prévue au plus tard à until :
+// l'expiration l'emporte sur les transitions. Seules les règles actives sont prises en compte ;
+// leurs dates fixes doivent avoir été lues par parseDates.
+func simulateLifecycle(config *LifecycleConfiguration, objects []Object, tags map[string]map[string]string, until time.Time) []lifecycleEvent {
+	var events []lifecycleEvent
+	for _, obj := range objects {
+		modified, err := time.Parse(time.RFC3339, obj.LastModified)
+		if err != nil {
+			continue
+		}
+		var expire, transition *lifecycleEvent
+		for i, rule := range config.Rules {
+			if rule.Status != "Enabled" || !rule.matches(obj.Key, tags[obj.Key]) {
+				continue
+			}
+			name := lifecycleRuleName(i, rule.ID)
+			if e := rule.Expiration; e != nil && (e.Days > 0 || e.Date != "") {
+				date := lifecycleActionDate(modified, e.Days, e.at)
+				if !date.After(until) && (expire == nil || date.Before(expire.Date)) {
+					expire = &lifecycleEvent{Key: obj.Key, Rule: name, Action: "expire", Date: date}
+				}
+			}
+			for _, t := range rule.Transitions {
+				date := lifecycleActionDate(modified, t.Days, t.at)
+				// La transition retenue est la dernière échue : c'est la classe de stockage à la date simulée
+				if !date.After(until) && (transition == nil || date.After(transition.Date)) {
+					transition = &lifecycleEvent{Key: obj.Key, Rule: name, Action: "transition", StorageClass: t.StorageClass, Date: date}
+				}
+			}
+		}
+		switch {
+		case expire != nil:
+			events = append(events, *expire)
+		case transition != nil:
+			events = append(events, *transition)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Date.Before(events[j].Date) })
+	return events
+}
+
+// usesTags indique si une règle active filtre sur des tags, auquel cas la simulation doit les lire
+func (c *LifecycleConfiguration) usesTags() bool {
+	for _, rule := range c.Rules {
+		if rule.Status == "Enabled" && len(rule.filterTags()) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (r LifecycleRule) matches(key string, tags map[string]string) bool {
+	if !strings.HasPrefix(key, r.filterPrefix()) {
+		return false
+	}
+	for name, value := range r.filterTags() {
+		if actual, ok := tags[name]; !ok || actual != value {
+			return false
+		}
+	}
+	return true
+}
@@ -1,15 +1,13 @@
 package cmd
 
 import (
+	"context"
 	"encoding/xml"
-	"errors"
 	"fmt"
-	"io/ioutil"
 	"log"
 	"net/http"
 	"time"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
 // Bucket représente un bucket dans la réponse XML
@@ -28,44 +26,22 @@ var ListBucketsCmd = &cobra.Command{
 	Use:   "list-buckets",
 	Short: "List all S3 buckets via the API",
 	Run: func(cmd *cobra.Command, args []string) {
-		// Récupérer l'URL de l'API depuis le fichier de configuration ou les variables d'environnement
-		apiURL := viper.GetString("s3.api_url")
-		if apiURL == "" {
-			handleError(errors.New("API URL is not configured. Please set it in the config file or environment variables"))
-			return
-		}
-
-		// Faire une requête GET pour obtenir la liste des buckets
-		resp, err := http.Get(apiURL)
-		if err != nil {
-			handleError(fmt.Errorf("failed to connect to S3 API at %s: %v", apiURL, err))
-			return
-		}
-		defer resp.Body.Close()
-
-		// Vérifier le statut de la réponse
-		if resp.StatusCode != http.StatusOK {
-			handleError(fmt.Errorf("S3 API returned an error: status code %d", resp.StatusCode))
-			return
-		}
-
-		// Lire la réponse
-		body, err := ioutil.ReadAll(resp.Body)
+		// Récupérer l'URL de l'API depuis le profil actif
+		client, err := newConfiguredClient()
 		if err != nil {
-			handleError(fmt.Errorf("failed to read response body: %v", err))
+			handleError(err)
 			return
 		}
 
-		// Parse la réponse XML
-		var result ListAllMyBucketsResult
-		err = xml.Unmarshal(body, &result)
+		// Obtenir la liste des buckets
+		buckets, err := listBuckets(cmd.Context(), client)
 		if err != nil {
-			handleError(fmt.Errorf("failed to parse XML response: %v", err))
+			handleError(fmt.Errorf("failed to list buckets from %s: %v", client.profile.APIURL, err))
 			return
 		}
 
 		// Afficher les buckets de manière lisible
-		if len(result.Buckets) == 0 {
+		if len(buckets) == 0 {
 			fmt.Println("No buckets found.")
 			return
 		}
@@ -75,7 +51,7 @@ var ListBucketsCmd = &cobra.Command{
 		const readableDateLayout = "2006-01-02 15:04:05"
 		const inputLayout = time.RFC3339 // Le format attendu : "2024-09-17T08:58:31Z")
 		
-		for _, bucket := range result.Buckets {
+		for _, bucket := range buckets {
 			// Convertir la chaîne de caractères CreationDate en time.Time
 			creationDateTime, err := time.Parse(inputLayout, bucket.CreationDate)
 			if err != nil {
@@ -90,6 +66,27 @@ var ListBucketsCmd = &cobra.Command{
 	},
 }
 
+// listBuckets retourne les buckets du compte
+func listBuckets(ctx context.Context, client *s3Client) ([]Bucket, error) {
+	req, err := client.newRequest(ctx, "GET", "", "", nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := client.do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp)
+	}
+	var result ListAllMyBucketsResult
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to parse XML response: %w", err)
+	}
+	return result.Buckets, nil
+}
+
 // handleError affiche un message d'erreur et continue l'exécution du programme sans l'arrêter brutalement
 func handleError(err error) {
 	fmt.Fprintf(log.Writer(), "Error: %v\n", err)
@@ -1,14 +1,15 @@
 package cmd
 
 import (
+	"context"
 	"encoding/xml"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"net/http"
+	"net/url"
 	"time"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 )
 
 // Object represents an object in the S3 bucket
@@ -20,9 +21,17 @@ type Object struct {
 
 // ListBucketResult represents the response structure from the S3 API
 type ListObjectResult struct {
-	Objects []Object `xml:"Contents"`
+	Objects     []Object `xml:"Contents"`
+	IsTruncated bool     `xml:"IsTruncated"`
+	NextMarker  string   `xml:"NextMarker"`
 }
 
+var (
+	listPrefix     string
+	listAsOf       string
+	listTagFilters []string
+)
+
 // listObjectCmd represents the list-object command
 var ListObjectCmd = &cobra.Command{
 	Use:   "list-object",
@@ -34,17 +43,48 @@ var ListObjectCmd = &cobra.Command{
 
 		bucketName := args[0]
 
-		// Récupérer l'URL de l'API via Viper
-		apiURL := viper.GetString("s3.api_url")
-		if apiURL == "" {
-			log.Fatal("API URL is not configured. Please set it in the config file or environment variables.")
+		// Récupérer l'URL de l'API depuis le profil actif
+		client, err := newConfiguredClient()
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+
+		// Les tags ne sont lus que sur les versions courantes : ils ne disent rien de l'état passé
+		if listAsOf != "" && len(listTagFilters) > 0 {
+			log.Println("Error: --tag-filter cannot be combined with --as-of")
+			return
 		}
 
-		// Construire l'URL pour lister les objets du bucket
-		url := fmt.Sprintf("%s/%s/", apiURL, bucketName)
+		// État du bucket à une date passée, reconstitué à partir des versions
+		if listAsOf != "" {
+			listObjectsAsOf(cmd, client, bucketName)
+			return
+		}
+
+		// Ne garder que les objets dont les tags correspondent à --tag-filter, sur toutes les pages du listage
+		if len(listTagFilters) > 0 {
+			objects, err := listObjects(cmd.Context(), client, bucketName, listPrefix)
+			if err == nil {
+				objects, err = filterObjectsByTags(cmd.Context(), client, bucketName, objects, listTagFilters)
+			}
+			if err != nil {
+				log.Printf("Error: %v", err)
+				return
+			}
+			printObjects(objects)
+			return
+		}
 
-		// Effectuer une requête GET
-		resp, err := http.Get(url)
+		// Effectuer une requête GET sur le bucket
+		var query url.Values
+		if listPrefix != "" {
+			query = url.Values{"prefix": {listPrefix}}
+		}
+		req, err := client.newRequest(cmd.Context(), "GET", bucketName, "", query, nil)
+		if err != nil {
+			log.Fatalf("Error creating request: %v", err)
+		}
+		resp, err := client.do(req)
 		if err != nil {
 			log.Fatalf("Error making request to list objects: %v", err)
 		}
@@ -69,31 +109,128 @@ var ListObjectCmd = &cobra.Command{
 			log.Fatalf("Error parsing XML response: %v", err)
 		}
 		
-		if len(result.Objects) == 0 {
-			fmt.Println("No objects found.")
-			return
+		printObjects(result.Objects)
+	},
+}
+
+// printObjects affiche les objets avec leur date de modification et leur taille
+func printObjects(objects []Object) {
+	if len(objects) == 0 {
+		fmt.Println("No objects found.")
+		return
+	}
+
+	// Afficher les objets
+	fmt.Println("Objects:")
+	const readableDateLayout = "2006-01-02 15:04:05"
+	const inputLayout = time.RFC3339 // Le format attendu
+
+	for _, obj := range objects {
+		// Convertir la chaîne de caractères LastModified en time.Time
+		lastModifiedTime, err := time.Parse(inputLayout, obj.LastModified)
+		if err != nil {
+			log.Printf("Error parsing date: %v", err)
+			continue
 		}
 
-		// Afficher les objets
-		fmt.Println("Objects:")
-		const readableDateLayout = "2006-01-02 15:04:05"
-		const inputLayout = time.RFC3339 // Le format attendu
+		// Formater la date pour un affichage lisible
+		readableDate := lastModifiedTime.Format(readableDateLayout)
+		fmt.Printf("- [%s] %dB %s\n", readableDate, obj.Size, obj.Key)
+	}
+}
 
-		for _, obj := range result.Objects {
-			// Convertir la chaîne de caractères LastModified en time.Time
-			lastModifiedTime, err := time.Parse(inputLayout, obj.LastModified)
-			if err != nil {
-				log.Printf("Error parsing date: %v", err)
-				continue
-			}
+// filterObjectsByTags ne conserve que les objets correspondant aux filtres de tags
+func filterObjectsByTags(ctx context.Context, client *s3Client, bucketName string, objects []Object, values []string) ([]Object, error) {
+	filters, err := parseTagFilters(values)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, len(objects))
+	byKey := map[string]Object{}
+	for i, obj := range objects {
+		keys[i] = obj.Key
+		byKey[obj.Key] = obj
+	}
+	selected, err := filterByTags(ctx, client, bucketName, keys, filters)
+	if err != nil {
+		return nil, err
+	}
+	filtered := []Object{}
+	for _, key := range selected {
+		filtered = append(filtered, byKey[key])
+	}
+	return filtered, nil
+}
 
-			// Formater la date pour un affichage lisible
-			readableDate := lastModifiedTime.Format(readableDateLayout)
-			fmt.Printf("- [%s] %dB %s\n", readableDate, obj.Size, obj.Key)
+// listObjects parcourt toutes les pages de la liste des objets (reprise par marker)
+func listObjects(ctx context.Context, client *s3Client, bucketName, prefix string) ([]Object, error) {
+	var objects []Object
+	query := url.Values{}
+	if prefix != "" {
+		query.Set("prefix", prefix)
+	}
+	for {
+		req, err := client.newRequest(ctx, "GET", bucketName, "", query, nil)
+		if err != nil {
+			return nil, err
 		}
-	},
+		resp, err := client.do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			err := responseError(resp)
+			resp.Body.Close()
+			return nil, err
+		}
+		var page ListObjectResult
+		err = xml.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse object listing: %w", err)
+		}
+
+		objects = append(objects, page.Objects...)
+		if !page.IsTruncated || len(page.Objects) == 0 {
+			return objects, nil
+		}
+		marker := page.NextMarker
+		if marker == "" {
+			marker = page.Objects[len(page.Objects)-1].Key
+		}
+		query.Set("marker", marker)
+	}
+}
+
+// listObjectsAsOf affiche les objets tels qu'ils étaient à la date --as-of
+func listObjectsAsOf(cmd *cobra.Command, client *s3Client, bucketName string) {
+	asOf, err := parseTimestamp(listAsOf, time.Now())
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	versions, err := listObjectVersions(cmd.Context(), client, bucketName, listPrefix, 0)
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+
+	state := versionsAsOf(versions, asOf)
+	if len(state) == 0 {
+		fmt.Printf("No objects found as of %s.\n", asOf.Format(time.RFC3339))
+		return
+	}
+
+	fmt.Printf("Objects as of %s:\n", asOf.Format(time.RFC3339))
+	const readableDateLayout = "2006-01-02 15:04:05"
+	for _, v := range state {
+		fmt.Printf("- [%s] %dB %s (version: %s)\n", v.LastModified.Format(readableDateLayout), v.Size, v.Key, v.VersionID)
+	}
 }
 
 func init() {
+	ListObjectCmd.Flags().StringArrayVar(&listTagFilters, "tag-filter", nil, "only list objects with this tag (key=value, or key for any value); repeat to require several tags")
+	ListObjectCmd.Flags().StringVar(&listPrefix, "prefix", "", "only list keys starting with this prefix")
+	ListObjectCmd.Flags().StringVar(&listAsOf, "as-of", "", "list the objects as they were at this time (RFC 3339, 'YYYY-MM-DD HH:MM' or a duration such as 24h), using object versions")
 	RootCmd.AddCommand(ListObjectCmd)
 }
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"log"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// ObjectVersion représente une version d'objet dans la réponse ListObjectVersions
+type ObjectVersion struct {
+	Key          string `xml:"Key"`
+	VersionID    string `xml:"VersionId"`
+	IsLatest     bool   `xml:"IsLatest"`
+	LastModified string `xml:"LastModified"`
+	ETag         string `xml:"ETag"`
+	Size         int64  `xml:"Size"`
+	StorageClass string `xml:"StorageClass"`
+}
+
+// DeleteMarkerEntry représente un marqueur de suppression
+type DeleteMarkerEntry struct {
+	Key          string `xml:"Key"`
+	VersionID    string `xml:"VersionId"`
+	IsLatest     bool   `xml:"IsLatest"`
+	LastModified string `xml:"LastModified"`
+}
+
+// ListVersionsResult représente une page de la réponse ListObjectVersions
+type ListVersionsResult struct {
+	IsTruncated         bool                `xml:"IsTruncated"`
+	NextKeyMarker       string              `xml:"NextKeyMarker"`
+	NextVersionIDMarker string              `xml:"NextVersionIdMarker"`
+	Versions            []ObjectVersion     `xml:"Version"`
+	DeleteMarkers       []DeleteMarkerEntry `xml:"DeleteMarker"`
+}
+
+// versionEntry réunit versions et marqueurs de suppression dans une même liste
+type versionEntry struct {
+	Key          string
+	VersionID    string
+	IsLatest     bool
+	LastModified time.Time
+	ETag         string
+	Size         int64
+	DeleteMarker bool
+}
+
+var (
+	versionsPrefix   string
+	versionsPageSize int
+)
+
+// ListObjectVersionsCmd représente la commande list-object-versions
+var ListObjectVersionsCmd = &cobra.Command{
+	Use:   "list-object-versions <bucket-name>",
+	Short: "Lists every version of the objects in a bucket, including delete markers",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 {
+			log.Println("Usage: list-object-versions <bucket-name> [--prefix <prefix>]")
+			return
+		}
+		bucketName := args[0]
+
+		client, err := newConfiguredClient()
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+
+		versions, err := listObjectVersions(cmd.Context(), client, bucketName, versionsPrefix, versionsPageSize)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		if len(versions) == 0 {
+			fmt.Println("No versions found.")
+			return
+		}
+
+		fmt.Println("Versions:")
+		const readableDateLayout = "2006-01-02 15:04:05"
+		for _, v := range versions {
+			latest := ""
+			if v.IsLatest {
+				latest = ", latest"
+			}
+			if v.DeleteMarker {
+				fmt.Printf("- [%s] delete marker %s (version: %s%s)\n", v.LastModified.Format(readableDateLayout), v.Key, v.VersionID, latest)
+				continue
+			}
+			fmt.Printf("- [%s] %dB %s (version: %s%s)\n", v.LastModified.Format(readableDateLayout), v.Size, v.Key, v.VersionID, latest)
+		}
+	},
+}
+
+// listObjectVersions parcourt toutes les pages de ListObjectVersions. Les entrées sont triées
+// par clé puis de la plus récente à la plus ancienne, marqueurs de suppression compris.
+func listObjectVersions(ctx context.Context, client *s3Client, bucketName, prefix string, pageSize int) ([]versionEntry, error) {
+	var entries []versionEntry
+	query := url.Values{}
+	if prefix != "" {
+		query.Set("prefix", prefix)
+	}
+	if pageSize > 0 {
+		query.Set("max-keys", strconv.Itoa(pageSize))
+	}
+
+	for {
+		data, err := client.getSubresource(ctx, bucketName, "", "versions", query)
+		if err != nil {
+			return nil, err
+		}
+		var page ListVersionsResult
+		if err := xml.Unmarshal(data, &page); err != nil {
+			return nil, fmt.Errorf("failed to parse ListObjectVersions response: %w", err)
+		}
+
+		// Une date illisible fausserait l'ordre des versions (et les restaurations qui en dépendent)
+		for _, v := range page.Versions {
+			modified, err := time.Parse(time.RFC3339, v.LastModified)
+			if err != nil {
+				return nil, fmt.Errorf("invalid LastModified for version '%s' of '%s': %w", v.VersionID, v.Key, err)
+			}
+			entries = append(entries, versionEntry{Key: v.Key, VersionID: v.VersionID, IsLatest: v.IsLatest,
+				LastModified: modified, ETag: v.ETag, Size: v.Size})
+		}
+		for _, m := range page.DeleteMarkers {
+			modified, err := time.Parse(time.RFC3339, m.LastModified)
+			if err != nil {
+				return nil, fmt.Errorf("invalid LastModified for delete marker '%s' of '%s': %w", m.VersionID, m.Key, err)
+			}
+			entries = append(entries, versionEntry{Key: m.Key, VersionID: m.VersionID, IsLatest: m.IsLatest,
+				LastModified: modified, DeleteMarker: true})
+		}
+
+		if !page.IsTruncated {
+			break
+		}
+		if page.NextKeyMarker == "" && page.NextVersionIDMarker == "" {
+			return nil, fmt.Errorf("truncated ListObjectVersions response without a continuation marker")
+		}
+		query.Set("key-marker", page.NextKeyMarker)
+		query.Set("version-id-marker", page.NextVersionIDMarker)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Key != entries[j].Key {
+			return entries[i].Key < entries[j].Key
+		}
+		if !entries[i].LastModified.Equal(entries[j].LastModified) {
+			return entries[i].LastModified.After(entries[j].LastModified)
+		}
+		// Versions et marqueurs sont lus séparément : à date égale, la dernière version passe en tête
+		return entries[i].IsLatest && !entries[j].IsLatest
+	})
+	return entries, nil
+}
+
+func init() {
+	ListObjectVersionsCmd.Flags().StringVar(&versionsPrefix, "prefix", "", "only list keys starting with this prefix")
+	ListObjectVersionsCmd.Flags().IntVar(&versionsPageSize, "page-size", 0, "number of entries requested per page (default: server maximum)")
+	RootCmd.AddCommand(ListObjectVersionsCmd)
+}
@@ -0,0 +1,311 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Limites imposées par S3 aux uploads multipart
+const (
+	defaultPartSize          = 8 << 20
+	minPartSize              = 5 << 20
+	maxPartSize              = 5 << 30
+	maxParts                 = 10000
+	defaultUploadConcurrency = 4
+)
+
+// InitiateMultipartUploadResult représente la réponse de CreateMultipartUpload
+type InitiateMultipartUploadResult struct {
+	XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+	Bucket   string   `xml:"Bucket"`
+	Key      string   `xml:"Key"`
+	UploadID string   `xml:"UploadId"`
+}
+
+// CompletedPart identifie une partie envoyée
+type CompletedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+// CompleteMultipartUpload représente le corps XML de CompleteMultipartUpload
+type CompleteMultipartUpload struct {
+	XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+	Parts   []CompletedPart `xml:"Part"`
+}
+
+// multipartUpload envoie un flux, éventuellement de taille inconnue, par parties mises en mémoire tampon.
+// Au plus concurrency+1 parties sont en mémoire à un instant donné.
+type multipartUpload struct {
+	client      *s3Client
+	bucket      string
+	key         string
+	header      http.Header // en-têtes de l'objet (Content-Type, métadonnées...)
+	partSize    int64
+	concurrency int
+	transfer    *transfer
+}
+
+// upload envoie le flux : une seule requête PUT s'il tient dans une partie, sinon un upload multipart.
+// En cas d'échec ou d'interruption, l'upload multipart est abandonné côté serveur.
+// Retourne le nombre de parties envoyées.
+func (u *multipartUpload) upload(ctx context.Context, r io.Reader) (int, error) {
+	first := make([]byte, u.partSize)
+	n, err := io.ReadFull(r, first)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return 1, u.putObject(ctx, first[:n])
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read input: %w", err)
+	}
+
+	uploadID, err := u.create(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	parts, err := u.uploadParts(ctx, uploadID, first, r)
+	if err == nil {
+		err = u.complete(ctx, uploadID, parts)
+	}
+	if err != nil {
+		// Le contexte peut être annulé (Ctrl-C) : l'abandon utilise un contexte détaché
+		if abortErr := u.abort(context.WithoutCancel(ctx), uploadID); abortErr != nil {
+			return 0, fmt.Errorf("%w (failed to abort multipart upload %s: %v)", err, uploadID, abortErr)
+		}
+		return 0, fmt.Errorf("%w (multipart upload aborted)", err)
+	}
+	return len(parts), nil
+}
+
+// putObject envoie un flux tenant dans une seule partie
+func (u *multipartUpload) putObject(ctx context.Context, data []byte) error {
+	req, err := u.client.newRequest(withTransfer(ctx, u.transfer), "PUT", u.bucket, u.key, nil, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	u.setHeaders(req)
+	resp, err := u.client.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return responseError(resp)
+	}
+	u.transfer.add(int64(len(data)))
+	return nil
+}
+
+func (u *multipartUpload) setHeaders(req *http.Request) {
+	for name, values := range u.header {
+		req.Header[name] = values
+	}
+}
+
+// create démarre l'upload multipart et retourne son identifiant
+func (u *multipartUpload) create(ctx context.Context) (string, error) {
+	req, err := u.client.newRequest(withTransfer(ctx, u.transfer), "POST", u.bucket, u.key, url.Values{"uploads": {""}}, nil)
+	if err != nil {
+		return "", err
+	}
+	u.setHeaders(req)
+	resp, err := u.client.do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to start multipart upload: %w", responseError(resp))
+	}
+
+	var result InitiateMultipartUploadResult
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil || result.UploadID == "" {
+		return "", fmt.Errorf("invalid CreateMultipartUpload response")
+	}
+	return result.UploadID, nil
+}
+
+// uploadParts lit le flux partie par partie et les envoie en parallèle.
+// La première erreur annule les envois en cours.
+func (u *multipartUpload) uploadParts(ctx context.Context, uploadID string, first []byte, r io.Reader) ([]CompletedPart, error) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	type job struct {
+		number int
+		data   []byte
+	}
+	jobs := make(chan job)
+
+	var (
+		mu    sync.Mutex
+		parts []CompletedPart
+		wg    sync.WaitGroup
+	)
+	for i := 0; i < u.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				etag, err := u.uploadPart(ctx, uploadID, j.number, j.data)
+				if err != nil {
+					cancel(err)
+					continue
+				}
+				u.transfer.add(int64(len(j.data)))
+				u.transfer.partCompleted(j.number, int64(len(j.data)))
+
+				mu.Lock()
+				parts = append(parts, CompletedPart{PartNumber: j.number, ETag: etag})
+				mu.Unlock()
+			}
+		}()
+	}
+
+	readErr := func() error {
+		data, last := first, false
+		for number := 1; ; number++ {
+			if number > maxParts {
+				return fmt.Errorf("input exceeds %d parts of %s; increase --part-size", maxParts, formatBytes(u.partSize))
+			}
+			select {
+			case jobs <- job{number: number, data: data}:
+			case <-ctx.Done():
+				return nil
+			}
+			if last {
+				return nil
+			}
+
+			data = make([]byte, u.partSize)
+			n, err := io.ReadFull(r, data)
+			switch {
+			case err == io.EOF:
+				return nil
+			case err == io.ErrUnexpectedEOF:
+				// Dernière partie, plus petite que les autres
+				data, last = data[:n], true
+			case err != nil:
+				return fmt.Errorf("failed to read input: %w", err)
+			}
+		}
+	}()
+	close(jobs)
+	wg.Wait()
+
+	if readErr != nil {
+		return nil, readErr
+	}
+	if err := context.Cause(ctx); err != nil {
+		return nil, err
+	}
+	sort.Slice(parts, func(i, j int) bool { return parts[i].PartNumber < parts[j].PartNumber })
+	return parts, nil
+}
+
+// uploadPart envoie une partie et retourne son ETag
+func (u *multipartUpload) uploadPart(ctx context.Context, uploadID string, number int, data []byte) (string, error) {
+	query := url.Values{"partNumber": {strconv.Itoa(number)}, "uploadId": {uploadID}}
+	req, err := u.client.newRequest(withTransfer(ctx, u.transfer), "PUT", u.bucket, u.key, query, bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	// Avec SSE-C, la clé client accompagne chaque partie
+	for name, values := range u.header {
+		if strings.HasPrefix(name, "X-Amz-Server-Side-Encryption-Customer-") {
+			req.Header[name] = values
+		}
+	}
+	resp, err := u.client.do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload part %d: %w", number, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to upload part %d: %w", number, responseError(resp))
+	}
+	etag := resp.Header.Get("ETag")
+	if etag == "" {
+		return "", fmt.Errorf("failed to upload part %d: response has no ETag", number)
+	}
+	return etag, nil
+}
+
+// complete assemble les parties. S3 peut renvoyer une erreur dans une réponse 200.
+func (u *multipartUpload) complete(ctx context.Context, uploadID string, parts []CompletedPart) error {
+	body, err := xml.Marshal(CompleteMultipartUpload{Parts: parts})
+	if err != nil {
+		return err
+	}
+	req, err := u.client.newRequest(withTransfer(ctx, u.transfer), "POST", u.bucket, u.key, url.Values{"uploadId": {uploadID}}, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/xml")
+	resp, err := u.client.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read CompleteMultipartUpload response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK || strings.Contains(string(data), "<Error>") {
+		resp.Body = io.NopCloser(bytes.NewReader(data))
+		return fmt.Errorf("failed to complete multipart upload: %w", responseError(resp))
+	}
+	return nil
+}
+
+// abort abandonne l'upload multipart et libère les parties stockées
+func (u *multipartUpload) abort(ctx context.Context, uploadID string) error {
+	req, err := u.client.newRequest(ctx, "DELETE", u.bucket, u.key, url.Values{"uploadId": {uploadID}}, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := u.client.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return responseError(resp)
+	}
+	return nil
+}
+
+// parseSize lit une taille avec unité binaire facultative (ex. 8MiB, 512K, 1G)
+func parseSize(value string) (int64, error) {
+	units := []struct {
+		suffix     string
+		multiplier int64
+	}{
+		{"gib", 1 << 30}, {"mib", 1 << 20}, {"kib", 1 << 10},
+		{"gb", 1 << 30}, {"mb", 1 << 20}, {"kb", 1 << 10},
+		{"g", 1 << 30}, {"m", 1 << 20}, {"k", 1 << 10}, {"b", 1},
+	}
+	number, multiplier := strings.ToLower(strings.TrimSpace(value)), int64(1)
+	for _, unit := range units {
+		if trimmed, ok := strings.CutSuffix(number, unit.suffix); ok {
+			number, multiplier = strings.TrimSpace(trimmed), unit.multiplier
+			break
+		}
+	}
+	n, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size '%s' (expected a number of bytes, optionally with KiB, MiB or GiB)", value)
+	}
+	return n * multiplier, nil
+}
@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+)
+
+// Actions prévues sur un bucket
+const (
+	bucketCreate     = "create"
+	bucketUpdate     = "update"
+	bucketUnchanged  = "unchanged"
+	bucketDelete     = "delete"
+	bucketConflict   = "conflict"
+	bucketUndeclared = "undeclared"
+)
+
+// bucketStep est l'action prévue sur un bucket, avec les sections à modifier
+type bucketStep struct {
+	bucket  string
+	action  string
+	region  string
+	reason  string // cause d'un conflit
+	changes []settingChange
+}
+
+// settingChange est une section dont l'état actuel diffère de l'état déclaré
+type settingChange struct {
+	setting bucketSetting
+	actual  bucketSection
+}
+
+var planPrune bool
+
+// PlanCmd affiche les modifications nécessaires pour que les buckets correspondent au fichier déclaratif
+var PlanCmd = &cobra.Command{
+	Use:   "plan <config-file>",
+	Short: "Shows the changes apply would make to match a declarative bucket configuration",
+	Long: `Compares the buckets declared in a YAML file (region, versioning, encryption, policy, CORS,
+lifecycle and tags) with their current state, read as describe-bucket does, and prints the changes
+apply would make. Nothing is changed.
+
+A section left out of the file is not managed; an empty section ({} or []) must not be configured
+and is removed. With --prune, the buckets of the account that are not declared are planned for deletion.
+For example:
+
+bs3 plan buckets.yaml
+bs3 plan buckets.yaml --prune`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 {
+			log.Println("Usage: plan <config-file> [--prune]")
+			return
+		}
+		declared, err := loadBucketsFile(args[0])
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+
+		client, err := newConfiguredClient()
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		plan, err := buildBucketPlan(cmd.Context(), client, declared, planPrune)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		printBucketPlan(plan)
+	},
+}
+
+// buildBucketPlan compare les buckets déclarés à leur état actuel, dans l'ordre du fichier ;
+// les buckets du compte non déclarés suivent, à supprimer avec prune
+func buildBucketPlan(ctx context.Context, client *s3Client, declared []desiredBucket, prune bool) ([]bucketStep, error) {
+	existing, err := listBuckets(ctx, client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list buckets: %w", err)
+	}
+	exists := map[string]bool{}
+	for _, bucket := range existing {
+		exists[bucket.Name] = true
+	}
+
+	var plan []bucketStep
+	isDeclared := map[string]bool{}
+	for _, bucket := range declared {
+		isDeclared[bucket.name] = true
+		step := bucketStep{bucket: bucket.name, region: bucket.region}
+
+		// L'état actuel est lu comme par describe-bucket ; un bucket à créer n'a aucune section configurée
+		actual := newBucketDescription(bucket.name)
+		if !exists[bucket.name] {
+			step.action = bucketCreate
+		} else {
+			if actual, err = describeBucket(ctx, client, bucket.name); err != nil {
+				return nil, err
+			}
+			if location := actual.Location; bucket.region != "" && location.Status == sectionConfigured && location.Summary != bucket.region {
+				step.action = bucketConflict
+				step.reason = fmt.Sprintf("it is in region %s, not %s; a bucket cannot be moved to another region", location.Summary, bucket.region)
+			}
+		}
+
+		for _, setting := range bucket.settings {
+			current := *actual.section(setting.key)
+			if current.Status == sectionError && step.action != bucketConflict {
+				step.action = bucketConflict
+				step.reason = fmt.Sprintf("its %s cannot be read: %s", setting.key, current.Error)
+			}
+			if !setting.upToDate(current) {
+				step.changes = append(step.changes, settingChange{setting: setting, actual: current})
+			}
+		}
+		switch {
+		case step.action != "":
+		case len(step.changes) > 0:
+			step.action = bucketUpdate
+		default:
+			step.action = bucketUnchanged
+		}
+		plan = append(plan, step)
+	}
+
+	for _, bucket := range existing {
+		if isDeclared[bucket.Name] {
+			continue
+		}
+		step := bucketStep{bucket: bucket.Name, action: bucketUndeclared}
+		if prune {
+			step.action = bucketDelete
+		}
+		plan = append(plan, step)
+	}
+	return plan, nil
+}
+
+// printBucketPlan affiche le plan : une ligne par bucket, puis ses sections à modifier
+// (+ ajoutée, ~ modifiée, - supprimée) avec le détail des documents
+func printBucketPlan(plan []bucketStep) {
+	for _, step := range plan {
+		switch step.action {
+		case bucketCreate:
+			if step.region != "" {
+				fmt.Printf("+ bucket '%s' will be created in %s\n", step.bucket, step.region)
+			} else {
+				fmt.Printf("+ bucket '%s' will be created\n", step.bucket)
+			}
+		case bucketUpdate:
+			fmt.Printf("~ bucket '%s' will be updated\n", step.bucket)
+		case bucketUnchanged:
+			fmt.Printf("  bucket '%s' is up to date\n", step.bucket)
+		case bucketDelete:
+			fmt.Printf("- bucket '%s' will be deleted (not declared)\n", step.bucket)
+		case bucketUndeclared:
+			fmt.Printf("  bucket '%s' is not declared (kept; use --prune to delete it)\n", step.bucket)
+		case bucketConflict:
+			fmt.Printf("! bucket '%s' cannot be applied: %s\n", step.bucket, step.reason)
+			continue
+		}
+
+		for _, change := range step.changes {
+			desired := change.setting.desired
+			switch {
+			case desired.Status != sectionConfigured:
+				fmt.Printf("    - %s: %s (removed)\n", change.setting.key, change.actual.Summary)
+			case change.actual.Status != sectionConfigured:
+				fmt.Printf("    + %s: %s\n", change.setting.key, desired.Summary)
+			default:
+				fmt.Printf("    ~ %s: %s -> %s\n", change.setting.key, change.actual.Summary, desired.Summary)
+			}
+			if change.setting.showDiff {
+				for _, line := range lineDiff(sectionText(change.actual), sectionText(desired)) {
+					fmt.Printf("        %s\n", line)
+				}
+			}
+		}
+	}
+
+	fmt.Printf("Plan: %d to create, %d to update, %d to delete, %d up to date.\n",
+		countBucketSteps(plan, bucketCreate), countBucketSteps(plan, bucketUpdate), countBucketSteps(plan, bucketDelete), countBucketSteps(plan, bucketUnchanged))
+	if conflicts := countBucketSteps(plan, bucketConflict); conflicts > 0 {
+		fmt.Printf("%d bucket(s) in conflict must be fixed before apply.\n", conflicts)
+	}
+}
+
+func countBucketSteps(plan []bucketStep, action string) int {
+	n := 0
+	for _, step := range plan {
+		if step.action == action {
+			n++
+		}
+	}
+	return n
+}
+
+func init() {
+	PlanCmd.Flags().BoolVar(&planPrune, "prune", false, "plan the deletion of the buckets of the account that are not declared")
+	RootCmd.AddCommand(PlanCmd)
+}
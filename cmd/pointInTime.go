@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// timestampLayouts sont les formats acceptés par --as-of, interprétés en heure locale sans fuseau
+var timestampLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// parseTimestamp lit une date RFC 3339, une date locale (ex. "2024-05-02 18:00")
+// ou une durée écoulée (ex. "36h" pour il y a 36 heures)
+func parseTimestamp(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range timestampLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp '%s' (expected RFC 3339, 'YYYY-MM-DD[ HH:MM[:SS]]' or a duration such as 36h)", value)
+}
+
+// versionsAsOf reconstitue l'état à la date asOf : pour chaque clé, la version la plus récente
+// antérieure ou égale à asOf. Une clé dont cette version est un marqueur de suppression, ou créée
+// après asOf, n'existait pas. Les entrées doivent être triées comme par listObjectVersions.
+func versionsAsOf(entries []versionEntry, asOf time.Time) []versionEntry {
+	var state []versionEntry
+	for i := 0; i < len(entries); {
+		key := entries[i].Key
+		found := false
+		for ; i < len(entries) && entries[i].Key == key; i++ {
+			if found || entries[i].LastModified.After(asOf) {
+				continue
+			}
+			found = true
+			if !entries[i].DeleteMarker {
+				state = append(state, entries[i])
+			}
+		}
+	}
+	return state
+}
+
+// latestVersions retourne la version courante de chaque clé, marqueurs de suppression exclus
+func latestVersions(entries []versionEntry) map[string]versionEntry {
+	latest := map[string]versionEntry{}
+	for _, entry := range entries {
+		if entry.IsLatest && !entry.DeleteMarker {
+			latest[entry.Key] = entry
+		}
+	}
+	return latest
+}
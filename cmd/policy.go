@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var policyDryRun bool
+
+// PolicyCmd regroupe les sous-commandes de gestion de la politique d'un bucket
+var PolicyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "Reads, validates, replaces or deletes the policy of a bucket",
+}
+
+var policyGetCmd = &cobra.Command{
+	Use:   "get <bucket-name>",
+	Short: "Prints the policy of a bucket",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 {
+			log.Println("Usage: policy get <bucket-name>")
+			return
+		}
+		bucketName := args[0]
+		client, err := newConfiguredClient()
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+
+		policy, err := getPolicy(cmd.Context(), client, bucketName)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		if policy == "" {
+			fmt.Printf("No policy on bucket '%s'.\n", bucketName)
+			return
+		}
+		fmt.Print(policy)
+	},
+}
+
+var policyPutCmd = &cobra.Command{
+	Use:   "put <bucket-name> <policy-file|->",
+	Short: "Validates a policy locally, shows its diff with the current one and applies it",
+	Long: `Validates the policy (JSON structure, Effect, known S3 actions, ARNs targeting the bucket),
+prints the difference with the current policy, then replaces it. Nothing is sent if the
+validation fails. --dry-run stops after the diff. Use - to read the policy from standard input.
+For example:
+
+bs3 policy generate public-read photos --prefix public/ | bs3 policy put photos -`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 2 {
+			log.Println("Usage: policy put <bucket-name> <policy-file|->")
+			return
+		}
+		bucketName := args[0]
+
+		var data []byte
+		var err error
+		if args[1] == "-" {
+			data, err = io.ReadAll(cmd.InOrStdin())
+		} else {
+			data, err = os.ReadFile(args[1])
+		}
+		if err != nil {
+			log.Printf("Error reading policy: %v", err)
+			return
+		}
+
+		if problems := validatePolicy(data, bucketName); len(problems) > 0 {
+			log.Printf("Error: the policy is not valid for bucket '%s':", bucketName)
+			for _, problem := range problems {
+				log.Printf("  - %v", problem)
+			}
+			return
+		}
+		proposed, err := normalizeJSON(data)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+
+		client, err := newConfiguredClient()
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		current, err := getPolicy(cmd.Context(), client, bucketName)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+
+		diff := lineDiff(current, proposed)
+		if !hasChanges(diff) {
+			fmt.Printf("The policy of bucket '%s' is already up to date.\n", bucketName)
+			return
+		}
+		if current == "" {
+			fmt.Printf("Bucket '%s' has no policy; the new policy will be:\n", bucketName)
+		} else {
+			fmt.Printf("Changes to the policy of bucket '%s':\n", bucketName)
+		}
+		for _, line := range diff {
+			fmt.Println(line)
+		}
+		if policyDryRun {
+			fmt.Println("Dry run: the policy was not changed.")
+			return
+		}
+
+		header := http.Header{"Content-Type": {"application/json"}}
+		if err := client.putSubresource(cmd.Context(), bucketName, "", "policy", nil, data, header); err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		fmt.Printf("Policy applied to bucket '%s'.\n", bucketName)
+	},
+}
+
+var policyDeleteCmd = &cobra.Command{
+	Use:   "delete <bucket-name>",
+	Short: "Deletes the policy of a bucket",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 {
+			log.Println("Usage: policy delete <bucket-name>")
+			return
+		}
+		bucketName := args[0]
+		client, err := newConfiguredClient()
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+
+		if err := client.deleteSubresource(cmd.Context(), bucketName, "", "policy", nil); err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		fmt.Printf("Policy deleted from bucket '%s'.\n", bucketName)
+	},
+}
+
+// getPolicy retourne la politique du bucket, mise en forme, ou "" si le bucket n'en a pas
+func getPolicy(ctx context.Context, client *s3Client, bucketName string) (string, error) {
+	data, err := client.getSubresource(ctx, bucketName, "", "policy", nil)
+	if s3Err, ok := err.(*S3Error); ok && s3Err.Code == "NoSuchBucketPolicy" {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return normalizeJSON(data)
+}
+
+// normalizeJSON indente un document JSON, clés triées, pour le comparer ligne à ligne
+func normalizeJSON(data []byte) (string, error) {
+	var document any
+	if err := json.Unmarshal(data, &document); err != nil {
+		return "", fmt.Errorf("invalid JSON: %w", err)
+	}
+	return formatJSON(document)
+}
+
+// formatJSON indente un document sans échapper <, > et & (fréquents dans les conditions)
+func formatJSON(document any) (string, error) {
+	var out strings.Builder
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+func init() {
+	policyPutCmd.Flags().BoolVar(&policyDryRun, "dry-run", false, "validate and show the diff without applying the policy")
+	PolicyCmd.AddCommand(policyGetCmd, policyPutCmd, policyDeleteCmd)
+	RootCmd.AddCommand(PolicyCmd)
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	generatePrefix     string
+	generatePrincipals []string
+)
+
+// policyPatterns décrit les modèles proposés par policy generate
+var policyPatterns = map[string]string{
+	"public-read":             "anyone can read the objects under --prefix",
+	"read-only":               "the --principal accounts or roles can list and read the objects under --prefix",
+	"read-write":              "the --principal accounts or roles can list, read, write and delete the objects under --prefix",
+	"deny-insecure-transport": "every request not made over HTTPS is denied",
+}
+
+var policyGenerateCmd = &cobra.Command{
+	Use:   "generate <pattern> <bucket-name>",
+	Short: "Prints a policy for a common pattern: public-read, read-only, read-write or deny-insecure-transport",
+	Long: `Prints a bucket policy for a common pattern, ready to be reviewed and applied with policy put:
+
+  public-read              anyone can read the objects under --prefix
+  read-only                the --principal accounts or roles can list and read the objects under --prefix
+  read-write               the --principal accounts or roles can also write and delete them
+  deny-insecure-transport  every request not made over HTTPS is denied
+
+For example:
+
+bs3 policy generate read-only reports --principal arn:aws:iam::123456789012:role/analytics --prefix 2024/`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 2 {
+			log.Println("Usage: policy generate <pattern> <bucket-name> [--prefix <prefix>] [--principal <arn>]...")
+			return
+		}
+		policy, err := generatePolicy(args[0], args[1], generatePrefix, generatePrincipals)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		text, err := formatJSON(policy)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		fmt.Print(text)
+	},
+}
+
+// generatePolicy construit la politique du modèle demandé pour le bucket
+func generatePolicy(pattern, bucket, prefix string, principals []string) (*PolicyDocument, error) {
+	if _, ok := policyPatterns[pattern]; !ok {
+		return nil, fmt.Errorf("unknown policy pattern '%s' (expected public-read, read-only, read-write or deny-insecure-transport)", pattern)
+	}
+	needsPrincipal := pattern == "read-only" || pattern == "read-write"
+	if needsPrincipal && len(principals) == 0 {
+		return nil, fmt.Errorf("pattern '%s' requires at least one --principal", pattern)
+	}
+	if !needsPrincipal && len(principals) > 0 {
+		return nil, fmt.Errorf("pattern '%s' applies to everyone and does not take --principal", pattern)
+	}
+
+	bucketARN := "arn:aws:s3:::" + bucket
+	objectsARN := bucketARN + "/" + prefix + "*"
+	principal, _ := json.Marshal(map[string][]string{"AWS": principals})
+	everyone := json.RawMessage(`"*"`)
+
+	listStatement := func() PolicyStatement {
+		st := PolicyStatement{Sid: "ListObjects", Effect: "Allow", Principal: principal, Action: stringList{"s3:ListBucket"}, Resource: stringList{bucketARN}}
+		if prefix != "" {
+			condition, _ := json.Marshal(map[string]string{"s3:prefix": prefix + "*"})
+			st.Condition = map[string]json.RawMessage{"StringLike": condition}
+		}
+		return st
+	}
+
+	policy := &PolicyDocument{Version: "2012-10-17"}
+	switch pattern {
+	case "public-read":
+		policy.Statement = []PolicyStatement{
+			{Sid: "PublicRead", Effect: "Allow", Principal: everyone, Action: stringList{"s3:GetObject"}, Resource: stringList{objectsARN}},
+		}
+	case "read-only":
+		policy.Statement = []PolicyStatement{
+			listStatement(),
+			{Sid: "ReadObjects", Effect: "Allow", Principal: principal, Action: stringList{"s3:GetObject"}, Resource: stringList{objectsARN}},
+		}
+	case "read-write":
+		policy.Statement = []PolicyStatement{
+			listStatement(),
+			{Sid: "ReadWriteObjects", Effect: "Allow", Principal: principal,
+				Action: stringList{"s3:GetObject", "s3:PutObject", "s3:DeleteObject"}, Resource: stringList{objectsARN}},
+		}
+	case "deny-insecure-transport":
+		condition := json.RawMessage(`{"aws:SecureTransport":"false"}`)
+		policy.Statement = []PolicyStatement{
+			{Sid: "DenyInsecureTransport", Effect: "Deny", Principal: everyone, Action: stringList{"s3:*"},
+				Resource: stringList{bucketARN, bucketARN + "/*"}, Condition: map[string]json.RawMessage{"Bool": condition}},
+		}
+	}
+	return policy, nil
+}
+
+func init() {
+	policyGenerateCmd.Flags().StringVar(&generatePrefix, "prefix", "", "restrict the policy to keys starting with this prefix")
+	policyGenerateCmd.Flags().StringArrayVar(&generatePrincipals, "principal", nil, "ARN of an account, user or role granted access (read-only, read-write); repeatable")
+	PolicyCmd.AddCommand(policyGenerateCmd)
+}
@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Versions acceptées du langage de politique
+var policyVersions = []string{"2012-10-17", "2008-10-17"}
+
+// s3Actions sont les actions S3 reconnues dans une politique de bucket
+var s3Actions = []string{
+	"AbortMultipartUpload", "BypassGovernanceRetention", "CreateBucket", "DeleteBucket",
+	"DeleteBucketPolicy", "DeleteBucketWebsite", "DeleteObject", "DeleteObjectTagging",
+	"DeleteObjectVersion", "DeleteObjectVersionTagging", "GetBucketAcl", "GetBucketCORS",
+	"GetBucketLocation", "GetBucketLogging", "GetBucketNotification", "GetBucketObjectLockConfiguration",
+	"GetBucketPolicy", "GetBucketPolicyStatus", "GetBucketPublicAccessBlock", "GetBucketTagging",
+	"GetBucketVersioning", "GetBucketWebsite", "GetEncryptionConfiguration", "GetLifecycleConfiguration",
+	"GetObject", "GetObjectAcl", "GetObjectAttributes", "GetObjectLegalHold", "GetObjectRetention",
+	"GetObjectTagging", "GetObjectVersion", "GetObjectVersionAcl", "GetObjectVersionTagging",
+	"ListBucket", "ListBucketMultipartUploads", "ListBucketVersions", "ListMultipartUploadParts",
+	"PutBucketAcl", "PutBucketCORS", "PutBucketLogging", "PutBucketNotification",
+	"PutBucketObjectLockConfiguration", "PutBucketPolicy", "PutBucketPublicAccessBlock",
+	"PutBucketTagging", "PutBucketVersioning", "PutBucketWebsite", "PutEncryptionConfiguration",
+	"PutLifecycleConfiguration", "PutObject", "PutObjectAcl", "PutObjectLegalHold",
+	"PutObjectRetention", "PutObjectTagging", "PutObjectVersionAcl", "PutObjectVersionTagging",
+	"RestoreObject",
+}
+
+// s3ARNPattern reconnaît un ARN S3 : arn:<partition>:s3:::<bucket>[/<clé>]
+var s3ARNPattern = regexp.MustCompile(`^arn:aws[a-z-]*:s3:::([^/]+)(/.*)?$`)
+
+// stringList accepte une chaîne seule ou une liste de chaînes, comme le langage de politique
+type stringList []string
+
+func (l *stringList) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*l = stringList{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("expected a string or a list of strings")
+	}
+	*l = list
+	return nil
+}
+
+// PolicyDocument représente une politique de bucket
+type PolicyDocument struct {
+	Version   string            `json:"Version"`
+	ID        string            `json:"Id,omitempty"`
+	Statement []PolicyStatement `json:"Statement"`
+}
+
+// PolicyStatement représente une déclaration d'une politique de bucket
+type PolicyStatement struct {
+	Sid          string                     `json:"Sid,omitempty"`
+	Effect       string                     `json:"Effect"`
+	Principal    json.RawMessage            `json:"Principal,omitempty"`
+	NotPrincipal json.RawMessage            `json:"NotPrincipal,omitempty"`
+	Action       stringList                 `json:"Action,omitempty"`
+	NotAction    stringList                 `json:"NotAction,omitempty"`
+	Resource     stringList                 `json:"Resource,omitempty"`
+	NotResource  stringList                 `json:"NotResource,omitempty"`
+	Condition    map[string]json.RawMessage `json:"Condition,omitempty"`
+}
+
+// validatePolicy vérifie localement une politique destinée au bucket : structure, effets,
+// actions connues et ARN ciblant ce bucket. Tous les problèmes trouvés sont retournés.
+func validatePolicy(data []byte, bucket string) []error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	var policy PolicyDocument
+	if err := decoder.Decode(&policy); err != nil {
+		return []error{fmt.Errorf("invalid policy JSON: %v", err)}
+	}
+	if decoder.More() {
+		return []error{fmt.Errorf("invalid policy JSON: unexpected data after the policy document")}
+	}
+
+	var problems []error
+	addf := func(format string, a ...any) { problems = append(problems, fmt.Errorf(format, a...)) }
+
+	if !slices.Contains(policyVersions, policy.Version) {
+		addf("Version must be one of %s (got '%s')", strings.Join(policyVersions, ", "), policy.Version)
+	}
+	if len(policy.Statement) == 0 {
+		addf("Statement must contain at least one statement")
+	}
+
+	sids := map[string]bool{}
+	for i, st := range policy.Statement {
+		name := fmt.Sprintf("statement %d", i+1)
+		if st.Sid != "" {
+			name = fmt.Sprintf("statement '%s'", st.Sid)
+			if sids[st.Sid] {
+				addf("%s: duplicate Sid", name)
+			}
+			sids[st.Sid] = true
+		}
+
+		if st.Effect != "Allow" && st.Effect != "Deny" {
+			addf("%s: Effect must be Allow or Deny (got '%s')", name, st.Effect)
+		}
+		if (st.Principal == nil) == (st.NotPrincipal == nil) {
+			addf("%s: exactly one of Principal or NotPrincipal is required", name)
+		} else if err := validatePrincipal(st.Principal, st.NotPrincipal); err != nil {
+			addf("%s: %v", name, err)
+		}
+
+		if (len(st.Action) == 0) == (len(st.NotAction) == 0) {
+			addf("%s: exactly one of Action or NotAction is required", name)
+		}
+		for _, action := range append(append(stringList{}, st.Action...), st.NotAction...) {
+			if !knownAction(action) {
+				addf("%s: unknown action '%s'", name, action)
+			}
+		}
+
+		if (len(st.Resource) == 0) == (len(st.NotResource) == 0) {
+			addf("%s: exactly one of Resource or NotResource is required", name)
+		}
+		for _, resource := range append(append(stringList{}, st.Resource...), st.NotResource...) {
+			match := s3ARNPattern.FindStringSubmatch(resource)
+			switch {
+			case resource == "*":
+			case match == nil:
+				addf("%s: invalid resource '%s' (expected arn:aws:s3:::%s or arn:aws:s3:::%s/<key pattern>)", name, resource, bucket, bucket)
+			case match[1] != bucket:
+				addf("%s: resource '%s' does not target bucket '%s'", name, resource, bucket)
+			}
+		}
+	}
+	return problems
+}
+
+// validatePrincipal accepte "*" ou un objet {"AWS"|"Service"|"Federated"|"CanonicalUser": chaîne ou liste}
+func validatePrincipal(principal, notPrincipal json.RawMessage) error {
+	raw := principal
+	if raw == nil {
+		raw = notPrincipal
+	}
+	var wildcard string
+	if json.Unmarshal(raw, &wildcard) == nil {
+		if wildcard != "*" {
+			return fmt.Errorf("principal must be \"*\" or an object such as {\"AWS\": \"arn:...\"} (got '%s')", wildcard)
+		}
+		return nil
+	}
+	var principals map[string]stringList
+	if err := json.Unmarshal(raw, &principals); err != nil {
+		return fmt.Errorf("invalid principal: %v", err)
+	}
+	for kind := range principals {
+		switch kind {
+		case "AWS", "Service", "Federated", "CanonicalUser":
+		default:
+			return fmt.Errorf("unknown principal type '%s'", kind)
+		}
+	}
+	return nil
+}
+
+// knownAction accepte "*", "s3:*" et les actions S3 connues, jokers compris (ex. s3:Get*)
+func knownAction(action string) bool {
+	if action == "*" {
+		return true
+	}
+	prefix, name, found := strings.Cut(action, ":")
+	if !found || !strings.EqualFold(prefix, "s3") {
+		return false
+	}
+	for _, known := range s3Actions {
+		if ok, _ := path.Match(strings.ToLower(name), strings.ToLower(known)); ok {
+			return true
+		}
+	}
+	return false
+}
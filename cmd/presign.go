@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"crypto/hmac"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// maxPresignExpiry : durée maximale acceptée par SigV4 pour une URL présignée
+const maxPresignExpiry = 7 * 24 * time.Hour
+
+var presignMethods = []string{"GET", "PUT", "HEAD", "DELETE"}
+
+var (
+	presignMethod             string
+	presignExpires            time.Duration
+	presignContentDisposition string
+	presignVerifyURL          string
+)
+
+// PresignCmd représente la commande presign
+var PresignCmd = &cobra.Command{
+	Use:   "presign",
+	Short: "Generates or verifies a presigned URL for an object",
+	Long: `This command generates a SigV4 query-string presigned URL that grants temporary
+access to an object without credentials, or verifies an existing URL offline.
+For example:
+
+bs3 presign <bucket-name> <object-key> --method GET --expires 1h
+bs3 presign <bucket-name> <object-key> --response-content-disposition "attachment; filename=report.pdf"
+bs3 presign --verify "<presigned-url>"`,
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := newS3Client()
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		creds, err := resolveCredentials(cmd.Context(), client.profile)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		if creds == nil {
+			log.Fatalf("Error: profile '%s' has no credentials, cannot sign URLs", client.profile.Name)
+		}
+
+		if presignVerifyURL != "" {
+			verifyPresigned(presignVerifyURL, creds)
+			return
+		}
+
+		if len(args) < 2 {
+			log.Fatal("Usage: presign <bucket-name> <object-key>")
+		}
+		if client.profile.APIURL == "" {
+			log.Fatal("API URL is not configured. Please set it in the config file or environment variables.")
+		}
+
+		method := strings.ToUpper(presignMethod)
+		if !isPresignMethod(method) {
+			log.Fatalf("Error: unsupported method '%s' (expected one of %s)", presignMethod, strings.Join(presignMethods, ", "))
+		}
+		if presignExpires < time.Second || presignExpires > maxPresignExpiry {
+			log.Fatalf("Error: --expires must be between 1s and %s", maxPresignExpiry)
+		}
+
+		objectURL, err := client.objectURL(args[0], args[1])
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		if presignContentDisposition != "" {
+			if method != "GET" {
+				log.Fatal("Error: --response-content-disposition is only supported with GET")
+			}
+			query := objectURL.Query()
+			query.Set("response-content-disposition", presignContentDisposition)
+			objectURL.RawQuery = query.Encode()
+		}
+
+		signed := newSigner(creds, client.profile.Region, "s3").presign(method, objectURL, presignExpires, time.Now())
+		fmt.Println(signed.String())
+	},
+}
+
+// presignedURLInfo décrit une URL présignée dont la signature a été vérifiée
+type presignedURLInfo struct {
+	AccessKeyID string
+	Method      string
+	Region      string
+	SignedAt    time.Time
+	ExpiresAt   time.Time
+}
+
+// verifyPresignedURL recalcule hors ligne la signature d'une URL présignée.
+// La méthode HTTP ne figurant pas dans l'URL, chaque méthode supportée est essayée.
+func verifyPresignedURL(raw string, creds *Credentials) (*presignedURLInfo, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+	query := u.Query()
+
+	if algorithm := query.Get("X-Amz-Algorithm"); algorithm != sigV4Algorithm {
+		return nil, fmt.Errorf("unsupported or missing X-Amz-Algorithm '%s'", algorithm)
+	}
+	if signedHeaders := query.Get("X-Amz-SignedHeaders"); signedHeaders != "host" {
+		return nil, fmt.Errorf("cannot verify offline: URL signs headers '%s' in addition to host", signedHeaders)
+	}
+
+	// Credential = <clé>/<date>/<région>/<service>/aws4_request
+	credential := strings.Split(query.Get("X-Amz-Credential"), "/")
+	if len(credential) != 5 || credential[4] != "aws4_request" {
+		return nil, fmt.Errorf("malformed X-Amz-Credential '%s'", query.Get("X-Amz-Credential"))
+	}
+	accessKey, region, service := credential[0], credential[2], credential[3]
+	if accessKey != creds.AccessKeyID {
+		return nil, fmt.Errorf("URL was signed with access key '%s' but the profile uses '%s'", accessKey, creds.AccessKeyID)
+	}
+
+	signedAt, err := time.Parse(amzDateFormat, query.Get("X-Amz-Date"))
+	if err != nil {
+		return nil, fmt.Errorf("malformed X-Amz-Date '%s'", query.Get("X-Amz-Date"))
+	}
+	if signedAt.Format(shortDateFormat) != credential[1] {
+		return nil, fmt.Errorf("X-Amz-Date does not match the credential scope date")
+	}
+	expires, err := strconv.Atoi(query.Get("X-Amz-Expires"))
+	if err != nil || expires < 1 || time.Duration(expires)*time.Second > maxPresignExpiry {
+		return nil, fmt.Errorf("invalid X-Amz-Expires '%s'", query.Get("X-Amz-Expires"))
+	}
+
+	signature := query.Get("X-Amz-Signature")
+	query.Del("X-Amz-Signature")
+	unsigned := *u
+	unsigned.RawQuery = canonicalQuery(query)
+
+	signer := newSigner(creds, region, service)
+	for _, method := range presignMethods {
+		canonical := canonicalRequest(method, &unsigned, unsigned.Host, nil, []string{"host"}, unsignedPayload)
+		expected := signer.signature(stringToSign(query.Get("X-Amz-Date"), signer.scope(signedAt), canonical), signedAt)
+		if hmac.Equal([]byte(expected), []byte(signature)) {
+			return &presignedURLInfo{
+				AccessKeyID: accessKey,
+				Method:      method,
+				Region:      region,
+				SignedAt:    signedAt,
+				ExpiresAt:   signedAt.Add(time.Duration(expires) * time.Second),
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("signature does not match for any of %s", strings.Join(presignMethods, ", "))
+}
+
+// verifyPresigned affiche le résultat de la vérification d'une URL présignée
+func verifyPresigned(raw string, creds *Credentials) {
+	info, err := verifyPresignedURL(raw, creds)
+	if err != nil {
+		fmt.Printf("Presigned URL is NOT valid: %v\n", err)
+		return
+	}
+
+	const readableDateLayout = "2006-01-02 15:04:05 MST"
+	fmt.Printf("Signed by:  %s (%s, region %s)\n", info.AccessKeyID, info.Method, info.Region)
+	fmt.Printf("Signed at:  %s\n", info.SignedAt.Format(readableDateLayout))
+	fmt.Printf("Expires at: %s\n", info.ExpiresAt.Format(readableDateLayout))
+
+	remaining := time.Until(info.ExpiresAt)
+	if remaining <= 0 {
+		fmt.Printf("Presigned URL signature is valid but the URL EXPIRED %s ago.\n", (-remaining).Truncate(time.Second))
+		return
+	}
+	fmt.Printf("Presigned URL is valid for %s more.\n", remaining.Truncate(time.Second))
+}
+
+func isPresignMethod(method string) bool {
+	for _, m := range presignMethods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	PresignCmd.Flags().StringVar(&presignMethod, "method", "GET", "HTTP method allowed by the URL (GET, PUT, HEAD, DELETE)")
+	PresignCmd.Flags().DurationVar(&presignExpires, "expires", 15*time.Minute, "validity duration of the URL (max 168h)")
+	PresignCmd.Flags().StringVar(&presignContentDisposition, "response-content-disposition", "", "Content-Disposition returned when downloading with the URL (GET only)")
+	PresignCmd.Flags().StringVar(&presignVerifyURL, "verify", "", "verify the signature and expiry of a presigned URL offline")
+	RootCmd.AddCommand(PresignCmd)
+}
@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	presignPostKeyPrefix    string
+	presignPostLengthRange  string
+	presignPostContentType  string
+	presignPostMetadata     []string
+	presignPostExpires      time.Duration
+	presignPostSuccessCode  int
+	presignPostOutputFormat string
+)
+
+// PostPolicyForm regroupe l'URL et les champs d'un formulaire d'upload présigné
+type PostPolicyForm struct {
+	URL    string            `json:"url"`
+	Fields map[string]string `json:"fields"`
+
+	// fieldOrder conserve l'ordre d'émission des champs pour le formulaire HTML
+	fieldOrder []string
+	// contentTypePrefix est renseigné quand le type MIME est libre mais doit commencer par ce préfixe
+	contentTypePrefix string
+}
+
+func (f *PostPolicyForm) addField(name, value string) {
+	f.Fields[name] = value
+	f.fieldOrder = append(f.fieldOrder, name)
+}
+
+// PresignPostCmd représente la commande presign-post
+var PresignPostCmd = &cobra.Command{
+	Use:   "presign-post",
+	Short: "Generates a presigned POST policy for browser uploads",
+	Long: `This command builds and signs a POST policy document so that browsers can upload
+directly to a bucket through an HTML form, without credentials.
+For example:
+
+bs3 presign-post <bucket-name> --key-prefix uploads/ --content-length-range 1:10485760
+bs3 presign-post <bucket-name> --content-type image/ --meta owner=alice --format html`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 {
+			log.Println("Usage: presign-post <bucket-name>")
+			return
+		}
+		bucketName := args[0]
+
+		client, err := newConfiguredClient()
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		creds, err := resolveCredentials(cmd.Context(), client.profile)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		if creds == nil {
+			log.Printf("Error: profile '%s' has no credentials, cannot sign a POST policy", client.profile.Name)
+			return
+		}
+		if presignPostExpires < time.Second || presignPostExpires > maxPresignExpiry {
+			log.Printf("Error: --expires must be between 1s and %s", maxPresignExpiry)
+			return
+		}
+
+		bucketURL, err := client.bucketURL(bucketName)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+
+		form, err := buildPostPolicy(bucketName, bucketURL.String(), newSigner(creds, client.profile.Region, "s3"), time.Now())
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+
+		switch presignPostOutputFormat {
+		case "json":
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			encoder.SetEscapeHTML(false)
+			if err := encoder.Encode(form); err != nil {
+				log.Printf("Error encoding JSON: %v", err)
+			}
+		case "html":
+			if err := postFormTemplate.Execute(os.Stdout, form.htmlFields()); err != nil {
+				log.Printf("Error rendering HTML form: %v", err)
+			}
+		default:
+			log.Printf("Error: unsupported format '%s' (expected json or html)", presignPostOutputFormat)
+		}
+	},
+}
+
+// buildPostPolicy construit le document de politique, le signe et retourne les champs du formulaire
+func buildPostPolicy(bucketName, bucketURL string, signer sigV4Signer, now time.Time) (*PostPolicyForm, error) {
+	now = now.UTC()
+	form := &PostPolicyForm{URL: bucketURL, Fields: map[string]string{}}
+
+	conditions := []interface{}{
+		map[string]string{"bucket": bucketName},
+		[]string{"starts-with", "$key", presignPostKeyPrefix},
+	}
+	form.addField("key", presignPostKeyPrefix+"${filename}")
+
+	if presignPostLengthRange != "" {
+		min, max, err := parseLengthRange(presignPostLengthRange)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, []interface{}{"content-length-range", min, max})
+	}
+
+	// Un type MIME terminé par "/" est un préfixe (ex. "image/"), sinon une valeur exacte
+	if presignPostContentType != "" {
+		if strings.HasSuffix(presignPostContentType, "/") {
+			conditions = append(conditions, []string{"starts-with", "$Content-Type", presignPostContentType})
+			form.contentTypePrefix = presignPostContentType
+		} else {
+			conditions = append(conditions, map[string]string{"Content-Type": presignPostContentType})
+			form.addField("Content-Type", presignPostContentType)
+		}
+	}
+
+	for _, meta := range presignPostMetadata {
+		name, value, ok := strings.Cut(meta, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid metadata '%s' (expected key=value)", meta)
+		}
+		field := "x-amz-meta-" + strings.ToLower(name)
+		conditions = append(conditions, map[string]string{field: value})
+		form.addField(field, value)
+	}
+
+	if presignPostSuccessCode != 0 {
+		if presignPostSuccessCode != 200 && presignPostSuccessCode != 201 && presignPostSuccessCode != 204 {
+			return nil, fmt.Errorf("invalid success status %d (expected 200, 201 or 204)", presignPostSuccessCode)
+		}
+		status := strconv.Itoa(presignPostSuccessCode)
+		conditions = append(conditions, map[string]string{"success_action_status": status})
+		form.addField("success_action_status", status)
+	}
+
+	credential := signer.creds.AccessKeyID + "/" + signer.scope(now)
+	amzDate := now.Format(amzDateFormat)
+	conditions = append(conditions,
+		map[string]string{"x-amz-algorithm": sigV4Algorithm},
+		map[string]string{"x-amz-credential": credential},
+		map[string]string{"x-amz-date": amzDate},
+	)
+	form.addField("x-amz-algorithm", sigV4Algorithm)
+	form.addField("x-amz-credential", credential)
+	form.addField("x-amz-date", amzDate)
+	if signer.creds.SessionToken != "" {
+		conditions = append(conditions, map[string]string{"x-amz-security-token": signer.creds.SessionToken})
+		form.addField("x-amz-security-token", signer.creds.SessionToken)
+	}
+
+	document, err := json.Marshal(map[string]interface{}{
+		"expiration": now.Add(presignPostExpires).Format("2006-01-02T15:04:05.000Z"),
+		"conditions": conditions,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode POST policy: %w", err)
+	}
+
+	// La signature porte sur le document encodé en base64
+	policy := base64.StdEncoding.EncodeToString(document)
+	form.addField("policy", policy)
+	form.addField("x-amz-signature", signer.signature(policy, now))
+	return form, nil
+}
+
+// parseLengthRange lit une plage "min:max" exprimée en octets
+func parseLengthRange(value string) (int64, int64, error) {
+	minText, maxText, ok := strings.Cut(value, ":")
+	min, errMin := strconv.ParseInt(minText, 10, 64)
+	max, errMax := strconv.ParseInt(maxText, 10, 64)
+	if !ok || errMin != nil || errMax != nil || min < 0 || max < min {
+		return 0, 0, fmt.Errorf("invalid content length range '%s' (expected min:max in bytes)", value)
+	}
+	return min, max, nil
+}
+
+type htmlFormField struct {
+	Name  string
+	Value string
+}
+
+type htmlForm struct {
+	URL               string
+	Fields            []htmlFormField
+	ContentTypePrefix string
+}
+
+func (f *PostPolicyForm) htmlFields() htmlForm {
+	out := htmlForm{URL: f.URL, ContentTypePrefix: f.contentTypePrefix}
+	for _, name := range f.fieldOrder {
+		out.Fields = append(out.Fields, htmlFormField{Name: name, Value: f.Fields[name]})
+	}
+	return out
+}
+
+// Le champ "file" doit être le dernier du formulaire
+var postFormTemplate = template.Must(template.New("form").Parse(`<form action="{{.URL}}" method="post" enctype="multipart/form-data">
+{{- range .Fields}}
+  <input type="hidden" name="{{.Name}}" value="{{.Value}}" />
+{{- end}}
+{{- if .ContentTypePrefix}}
+  <input type="text" name="Content-Type" placeholder="{{.ContentTypePrefix}}..." />
+{{- end}}
+  <input type="file" name="file" />
+  <input type="submit" value="Upload" />
+</form>
+`))
+
+func init() {
+	PresignPostCmd.Flags().StringVar(&presignPostKeyPrefix, "key-prefix", "", "prefix that uploaded object keys must start with")
+	PresignPostCmd.Flags().StringVar(&presignPostLengthRange, "content-length-range", "", "allowed upload size as min:max in bytes")
+	PresignPostCmd.Flags().StringVar(&presignPostContentType, "content-type", "", "required Content-Type, or a prefix when ending with '/' (e.g. image/)")
+	PresignPostCmd.Flags().StringArrayVar(&presignPostMetadata, "meta", nil, "required metadata as key=value (repeatable)")
+	PresignPostCmd.Flags().DurationVar(&presignPostExpires, "expires", time.Hour, "validity duration of the policy (max 168h)")
+	PresignPostCmd.Flags().IntVar(&presignPostSuccessCode, "success-status", 0, "HTTP status returned on success (200, 201 or 204)")
+	PresignPostCmd.Flags().StringVar(&presignPostOutputFormat, "format", "json", "output format: json or html")
+	RootCmd.AddCommand(PresignPostCmd)
+}
@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// defaultProfile correspond à la section `s3` historique du fichier de configuration
+const defaultProfile = "default"
+
+// Profile regroupe les paramètres de connexion d'un profil bs3
+type Profile struct {
+	Name   string
+	APIURL string
+	Region string
+
+	// AddressingStyle vaut path, virtual ou auto ; EndpointTemplate contient {region} (ex. https://s3.{region}.example.com)
+	AddressingStyle  string
+	EndpointTemplate string
+
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+
+	// Paramètres AssumeRole : les identifiants du profil source servent à signer l'appel STS
+	RoleARN         string
+	SourceProfile   string
+	RoleSessionName string
+	ExternalID      string
+	STSEndpoint     string
+	DurationSeconds int
+
+	// Paramètres TLS appliqués au transport partagé
+	CABundle           string
+	ClientCert         string
+	ClientKey          string
+	TLSMinVersion      string
+	InsecureSkipVerify bool
+	SPKIPins           []string
+
+	// Paramètres du transport : pool de connexions, délais et nouvelles tentatives
+	MaxConnections int
+	ConnectTimeout time.Duration
+	Timeouts       map[string]time.Duration
+	MaxAttempts    int
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+
+	// MasterKeyFile est la clé maîtresse du chiffrement côté client (upload-file --encrypt)
+	MasterKeyFile string
+}
+
+// profileKey retourne la clé Viper d'un paramètre pour le profil donné
+func profileKey(name, key string) string {
+	if name == defaultProfile {
+		return "s3." + key
+	}
+	return "profiles." + name + "." + key
+}
+
+// loadProfile lit un profil depuis la configuration.
+// Les profils nommés héritent de l'URL et de la région de la section `s3`, jamais des identifiants.
+func loadProfile(name string) (*Profile, error) {
+	if name == "" {
+		name = defaultProfile
+	}
+	if name != defaultProfile && !viper.IsSet("profiles."+name) {
+		return nil, fmt.Errorf("profile '%s' is not defined in the configuration", name)
+	}
+
+	// inheritedKey retourne la clé du profil si elle est définie, sinon celle de la section s3
+	inheritedKey := func(key string) string {
+		if name != defaultProfile && !viper.IsSet(profileKey(name, key)) {
+			return profileKey(defaultProfile, key)
+		}
+		return profileKey(name, key)
+	}
+	inherited := func(key string) string {
+		return viper.GetString(inheritedKey(key))
+	}
+	own := func(key string) string {
+		return viper.GetString(profileKey(name, key))
+	}
+
+	profile := &Profile{
+		Name:             name,
+		APIURL:           inherited("api_url"),
+		Region:           inherited("region"),
+		AddressingStyle:  inherited("addressing_style"),
+		EndpointTemplate: inherited("endpoint_template"),
+		AccessKey:        own("access_key"),
+		SecretKey:        own("secret_key"),
+		SessionToken:     own("session_token"),
+		RoleARN:          own("role_arn"),
+		SourceProfile:    own("source_profile"),
+		RoleSessionName:  own("role_session_name"),
+		ExternalID:       own("external_id"),
+		STSEndpoint:      inherited("sts_endpoint"),
+		DurationSeconds:  viper.GetInt(profileKey(name, "duration_seconds")),
+
+		CABundle:           inherited("ca_bundle"),
+		ClientCert:         inherited("client_cert"),
+		ClientKey:          inherited("client_key"),
+		TLSMinVersion:      inherited("tls_min_version"),
+		InsecureSkipVerify: viper.GetBool(inheritedKey("insecure_skip_verify")),
+		SPKIPins:           viper.GetStringSlice(inheritedKey("spki_pins")),
+
+		MaxConnections: viper.GetInt(inheritedKey("max_connections")),
+		ConnectTimeout: viper.GetDuration(inheritedKey("connect_timeout")),
+		Timeouts:       map[string]time.Duration{},
+		MaxAttempts:    viper.GetInt(inheritedKey("max_attempts")),
+		RetryBaseDelay: viper.GetDuration(inheritedKey("retry_base_delay")),
+		RetryMaxDelay:  viper.GetDuration(inheritedKey("retry_max_delay")),
+
+		MasterKeyFile: inherited("master_key_file"),
+	}
+	if profile.Region == "" {
+		profile.Region = "us-east-1"
+	}
+	if profile.APIURL == "" && profile.EndpointTemplate != "" {
+		profile.APIURL = expandEndpointTemplate(profile.EndpointTemplate, profile.Region)
+	}
+
+	// timeouts.<Opération> (ex. timeouts.GetObject: 0s) ; les clés Viper sont en minuscules
+	for operation, value := range viper.GetStringMapString(inheritedKey("timeouts")) {
+		timeout, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("profile '%s' has an invalid timeout for '%s': %v", name, operation, err)
+		}
+		profile.Timeouts[strings.ToLower(operation)] = timeout
+	}
+	if profile.MaxConnections <= 0 {
+		profile.MaxConnections = defaultMaxConnections
+	}
+	if profile.ConnectTimeout <= 0 {
+		profile.ConnectTimeout = defaultConnectTimeout
+	}
+	if profile.MaxAttempts <= 0 {
+		profile.MaxAttempts = defaultMaxAttempts
+	}
+	if profile.RetryBaseDelay <= 0 {
+		profile.RetryBaseDelay = defaultRetryBaseDelay
+	}
+	if profile.RetryMaxDelay <= 0 {
+		profile.RetryMaxDelay = defaultRetryMaxDelay
+	}
+
+	switch profile.AddressingStyle {
+	case "":
+		profile.AddressingStyle = addressingAuto
+	case addressingPath, addressingVirtual, addressingAuto:
+	default:
+		return nil, fmt.Errorf("profile '%s' has an invalid addressing_style '%s' (expected path, virtual or auto)", name, profile.AddressingStyle)
+	}
+	return profile, nil
+}
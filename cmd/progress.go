@@ -0,0 +1,399 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Modes d'affichage de la progression (--progress)
+const (
+	progressAuto = "auto"
+	progressBar  = "bar"
+	progressNone = "none"
+	progressJSON = "json"
+)
+
+// Événements émis en mode --progress=json
+const (
+	eventStarted       = "started"
+	eventProgress      = "progress"
+	eventPartCompleted = "part-completed"
+	eventRetried       = "retried"
+	eventFinished      = "finished"
+	eventFailed        = "failed"
+)
+
+// ProgressEvent est une ligne du flux JSON de progression (--progress=json).
+// Bytes est le nombre d'octets transférés depuis le début, Total vaut -1 si la taille est inconnue.
+type ProgressEvent struct {
+	Event    string    `json:"event"`
+	Time     time.Time `json:"time"`
+	File     string    `json:"file"`
+	Bytes    int64     `json:"bytes"`
+	Total    int64     `json:"total"`
+	Part     int       `json:"part,omitempty"`
+	PartSize int64     `json:"part_size,omitempty"`
+	Attempt  int       `json:"attempt,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+const (
+	progressInterval  = 100 * time.Millisecond
+	progressBarWidth  = 30
+	progressNameWidth = 24
+)
+
+// progress affiche l'avancement d'un ou plusieurs transferts concurrents : une barre par fichier
+// (octets, débit, ETA) et une barre globale dès qu'il y a plusieurs transferts, ou un flux
+// d'événements JSON. Les compteurs sont atomiques : les goroutines de transfert les incrémentent
+// pendant que la goroutine d'affichage les lit.
+type progress struct {
+	out     io.Writer
+	enabled bool
+	started time.Time
+
+	// events reçoit les événements JSON, un par ligne (nil hors mode json)
+	eventsMu sync.Mutex
+	events   *json.Encoder
+
+	mu        sync.Mutex
+	transfers []*transfer
+	lines     int // nombre de lignes dessinées au dernier rafraîchissement
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// transfer suit un fichier ; un total négatif signifie une taille inconnue
+type transfer struct {
+	progress *progress
+	name     string
+	total    atomic.Int64
+	current  atomic.Int64
+	started  time.Time
+	ended    atomic.Int64 // date de fin en nanosecondes Unix, 0 tant que le transfert est en cours
+
+	reported int64 // octets au dernier événement progress, lu par la seule goroutine d'affichage
+}
+
+// progressFiles conserve les descripteurs ouverts par --progress-fd, pour qu'ils ne soient pas
+// fermés par le ramasse-miettes entre deux transferts
+var (
+	progressFilesMu sync.Mutex
+	progressFiles   = map[int]*os.File{}
+)
+
+// newProgress crée l'affichage selon --progress ; en mode auto, la barre n'est affichée
+// que si la sortie standard est un terminal. Le mode json écrit sur --progress-fd.
+func newProgress() (*progress, error) {
+	p := &progress{out: os.Stdout, started: time.Now()}
+	switch progressMode {
+	case progressAuto, "":
+		p.enabled = isTerminal(os.Stdout)
+	case progressBar:
+		p.enabled = true
+	case progressNone:
+	case progressJSON:
+		out, err := progressOutput(progressFD)
+		if err != nil {
+			return nil, err
+		}
+		p.events = json.NewEncoder(out)
+	default:
+		return nil, fmt.Errorf("invalid --progress '%s' (expected auto, bar, json or none)", progressMode)
+	}
+	return p, nil
+}
+
+// progressOutput retourne le fichier correspondant au descripteur --progress-fd
+func progressOutput(fd int) (*os.File, error) {
+	switch fd {
+	case 1:
+		return os.Stdout, nil
+	case 2:
+		return os.Stderr, nil
+	}
+
+	progressFilesMu.Lock()
+	defer progressFilesMu.Unlock()
+	if f, ok := progressFiles[fd]; ok {
+		return f, nil
+	}
+	f := os.NewFile(uintptr(fd), fmt.Sprintf("fd%d", fd))
+	if f == nil {
+		return nil, fmt.Errorf("invalid --progress-fd %d", fd)
+	}
+	if _, err := f.Stat(); err != nil {
+		return nil, fmt.Errorf("--progress-fd %d is not an open file descriptor: %w", fd, err)
+	}
+	progressFiles[fd] = f
+	return f, nil
+}
+
+// isTerminal indique si le fichier est un terminal interactif
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// track enregistre un nouveau transfert
+func (p *progress) track(name string, total int64) *transfer {
+	t := &transfer{progress: p, name: name, started: time.Now()}
+	t.total.Store(total)
+
+	p.mu.Lock()
+	p.transfers = append(p.transfers, t)
+	p.mu.Unlock()
+
+	t.emit(ProgressEvent{Event: eventStarted})
+	return t
+}
+
+// emit écrit un événement JSON pour le transfert, avec ses compteurs courants
+func (t *transfer) emit(event ProgressEvent) {
+	p := t.progress
+	if p.events == nil {
+		return
+	}
+	event.Time = time.Now().UTC()
+	event.File = t.name
+	event.Bytes = t.current.Load()
+	event.Total = t.total.Load()
+
+	p.eventsMu.Lock()
+	defer p.eventsMu.Unlock()
+	p.events.Encode(event)
+}
+
+// start lance le rafraîchissement périodique de l'affichage
+func (p *progress) start() {
+	if (!p.enabled && p.events == nil) || p.stop != nil {
+		return
+	}
+	p.stop, p.done = make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(p.done)
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.refresh()
+			case <-p.stop:
+				return
+			}
+		}
+	}()
+}
+
+// finish arrête le rafraîchissement après un dernier affichage et termine la ligne en cours
+func (p *progress) finish() {
+	if p.stop == nil {
+		return
+	}
+	close(p.stop)
+	<-p.done
+	p.stop = nil
+	if p.enabled {
+		p.render()
+		fmt.Fprintln(p.out)
+	}
+}
+
+// refresh redessine les barres ou émet un événement progress pour chaque transfert ayant avancé
+func (p *progress) refresh() {
+	if p.enabled {
+		p.render()
+	}
+	if p.events == nil {
+		return
+	}
+	p.mu.Lock()
+	transfers := append([]*transfer(nil), p.transfers...)
+	p.mu.Unlock()
+	for _, t := range transfers {
+		if current := t.current.Load(); current != t.reported && t.ended.Load() == 0 {
+			t.reported = current
+			t.emit(ProgressEvent{Event: eventProgress})
+		}
+	}
+}
+
+// render redessine toutes les barres à la place des précédentes
+func (p *progress) render() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	lines := make([]string, 0, len(p.transfers)+1)
+	var current, total int64
+	for _, t := range p.transfers {
+		done, size := t.current.Load(), t.total.Load()
+		lines = append(lines, progressLine(t.name, done, size, t.elapsed(now), t.ended.Load() != 0))
+
+		current += done
+		if size < 0 || total < 0 {
+			total = -1
+		} else {
+			total += size
+		}
+	}
+	if len(p.transfers) > 1 {
+		finished := true
+		for _, t := range p.transfers {
+			finished = finished && t.ended.Load() != 0
+		}
+		lines = append(lines, progressLine(fmt.Sprintf("Total (%d files)", len(p.transfers)), current, total, now.Sub(p.started), finished))
+	}
+
+	var b strings.Builder
+	if p.lines > 1 {
+		fmt.Fprintf(&b, "\033[%dA", p.lines-1)
+	}
+	for i, line := range lines {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString("\r\033[2K" + line)
+	}
+	p.lines = len(lines)
+	io.WriteString(p.out, b.String())
+}
+
+// add comptabilise n octets transférés
+func (t *transfer) add(n int64) {
+	t.current.Add(n)
+}
+
+// restart remet le compteur à zéro quand le corps du transfert est renvoyé depuis le début
+func (t *transfer) restart() {
+	t.current.Store(0)
+}
+
+// setTotal renseigne la taille une fois connue (par exemple à la réception des en-têtes)
+func (t *transfer) setTotal(total int64) {
+	t.total.Store(total)
+}
+
+// finish fige le débit du transfert et signale sa réussite
+func (t *transfer) finish() {
+	if t.ended.CompareAndSwap(0, time.Now().UnixNano()) {
+		t.emit(ProgressEvent{Event: eventFinished})
+	}
+}
+
+// fail termine le transfert en échec
+func (t *transfer) fail(err error) {
+	if t.ended.CompareAndSwap(0, time.Now().UnixNano()) {
+		t.emit(ProgressEvent{Event: eventFailed, Error: err.Error()})
+	}
+}
+
+// partCompleted signale la fin d'une partie d'un upload multipart
+func (t *transfer) partCompleted(part int, size int64) {
+	t.emit(ProgressEvent{Event: eventPartCompleted, Part: part, PartSize: size})
+}
+
+// retried signale qu'une requête du transfert va être rejouée
+func (t *transfer) retried(attempt int, reason string) {
+	t.emit(ProgressEvent{Event: eventRetried, Attempt: attempt, Error: reason})
+}
+
+type transferKey struct{}
+
+// withTransfer rattache un transfert au contexte des requêtes, pour y signaler les nouvelles tentatives
+func withTransfer(ctx context.Context, t *transfer) context.Context {
+	return context.WithValue(ctx, transferKey{}, t)
+}
+
+// transferFrom retourne le transfert rattaché au contexte, ou nil
+func transferFrom(ctx context.Context) *transfer {
+	t, _ := ctx.Value(transferKey{}).(*transfer)
+	return t
+}
+
+// elapsed retourne la durée du transfert, arrêtée à sa fin
+func (t *transfer) elapsed(now time.Time) time.Duration {
+	if ended := t.ended.Load(); ended != 0 {
+		return time.Unix(0, ended).Sub(t.started)
+	}
+	return now.Sub(t.started)
+}
+
+// reader comptabilise les octets lus au travers de r
+func (t *transfer) reader(r io.Reader) io.Reader {
+	return &progressReader{Reader: r, transfer: t}
+}
+
+// summary décrit la quantité de données transférées, par exemple après une interruption
+func (t *transfer) summary() string {
+	if total := t.total.Load(); total >= 0 {
+		return fmt.Sprintf("%d of %d bytes", t.current.Load(), total)
+	}
+	return fmt.Sprintf("%d bytes", t.current.Load())
+}
+
+type progressReader struct {
+	io.Reader
+	transfer *transfer
+}
+
+func (r *progressReader) Read(b []byte) (int, error) {
+	n, err := r.Reader.Read(b)
+	r.transfer.add(int64(n))
+	return n, err
+}
+
+// progressLine formate une ligne : nom, barre, pourcentage, octets, débit et temps restant
+func progressLine(name string, current, total int64, elapsed time.Duration, finished bool) string {
+	if len(name) > progressNameWidth {
+		name = "..." + name[len(name)-progressNameWidth+3:]
+	}
+
+	var rate float64
+	if elapsed > 0 {
+		rate = float64(current) / elapsed.Seconds()
+	}
+
+	eta := "ETA --"
+	switch {
+	case finished:
+		eta = "done"
+	case total >= 0 && rate > 0:
+		eta = "ETA " + (time.Duration(float64(total-current)/rate) * time.Second).String()
+	}
+
+	if total < 0 {
+		return fmt.Sprintf("%-*s %s  %s/s  %s", progressNameWidth, name, formatBytes(current), formatBytes(int64(rate)), eta)
+	}
+
+	ratio := 1.0
+	if total > 0 {
+		ratio = min(float64(current)/float64(total), 1)
+	}
+	filled := int(ratio * progressBarWidth)
+	return fmt.Sprintf("%-*s [%s%s] %5.1f%%  %s / %s  %s/s  %s", progressNameWidth, name,
+		strings.Repeat("#", filled), strings.Repeat("-", progressBarWidth-filled), ratio*100,
+		formatBytes(current), formatBytes(total), formatBytes(int64(rate)), eta)
+}
+
+// formatBytes affiche une taille en unités binaires (KiB, MiB...)
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	putPartSize    string
+	putConcurrency int
+	putContentType string
+)
+
+// PutCmd représente la commande put
+var PutCmd = &cobra.Command{
+	Use:   "put <file|-> s3://bucket/key",
+	Short: "Uploads a file or standard input to S3, in parts for large or unknown-length streams",
+	Long: `Uploads a file, or standard input when the source is "-", to an S3 object.
+Input that does not fit in a single part is sent with a multipart upload whose parts are
+buffered in memory and uploaded in parallel; the upload is aborted on failure or Ctrl-C.
+For example:
+
+tar cz data/ | bs3 put - s3://bucket/backups/data.tar.gz`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 2 {
+			log.Println("Usage: put <file|-> s3://bucket/key")
+			return
+		}
+		source := args[0]
+		bucketName, key, err := parseS3URI(args[1])
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+
+		// Sans clé (ou avec un préfixe), le nom du fichier est ajouté comme avec upload-file
+		if key == "" || strings.HasSuffix(key, "/") {
+			if source == "-" {
+				log.Println("Error: an object key is required when reading standard input (s3://bucket/key)")
+				return
+			}
+			key += filepath.Base(source)
+		}
+
+		partSize, err := parseSize(putPartSize)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		if partSize < minPartSize || partSize > maxPartSize {
+			log.Printf("Error: --part-size must be between %s and %s", formatBytes(minPartSize), formatBytes(maxPartSize))
+			return
+		}
+		if putConcurrency < 1 {
+			log.Println("Error: --concurrency must be at least 1")
+			return
+		}
+
+		// Récupérer l'URL de l'API depuis le profil actif
+		client, err := newConfiguredClient()
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+
+		// Ouvrir la source ; la taille de l'entrée standard est inconnue
+		var input io.Reader = cmd.InOrStdin()
+		total := int64(-1)
+		if source != "-" {
+			file, err := os.Open(source)
+			if err != nil {
+				log.Printf("Error opening file: %v", err)
+				return
+			}
+			defer file.Close()
+			info, err := file.Stat()
+			if err != nil {
+				log.Printf("Error getting file info: %v", err)
+				return
+			}
+			input, total = file, info.Size()
+
+			// Agrandir les parties si le fichier en dépasserait le nombre maximal
+			if minimum := (total + maxParts - 1) / maxParts; minimum > partSize {
+				partSize = minimum
+			}
+		}
+
+		display, err := newProgress()
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		bar := display.track(key, total)
+		upload := &multipartUpload{
+			client:      client,
+			bucket:      bucketName,
+			key:         key,
+			header:      http.Header{"Content-Type": {putContentType}},
+			partSize:    partSize,
+			concurrency: putConcurrency,
+			transfer:    bar,
+		}
+
+		display.start()
+		parts, err := upload.upload(cmd.Context(), input)
+		if err != nil {
+			bar.fail(err)
+			display.finish()
+			if cmd.Context().Err() != nil {
+				fmt.Printf("Upload to s3://%s/%s interrupted after %s sent: %v\n", bucketName, key, bar.summary(), err)
+				return
+			}
+			log.Printf("Error: %v", err)
+			return
+		}
+		bar.finish()
+		display.finish()
+		fmt.Printf("Uploaded %d bytes to s3://%s/%s (%d part(s)).\n", bar.current.Load(), bucketName, key, parts)
+	},
+}
+
+func init() {
+	PutCmd.Flags().StringVar(&putPartSize, "part-size", "8MiB", "size of each part buffered in memory (5MiB to 5GiB)")
+	PutCmd.Flags().IntVar(&putConcurrency, "concurrency", defaultUploadConcurrency, "number of parts uploaded in parallel")
+	PutCmd.Flags().StringVar(&putContentType, "content-type", "application/octet-stream", "Content-Type of the object")
+	RootCmd.AddCommand(PutCmd)
+}
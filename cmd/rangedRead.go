@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// head et tail lisent l'objet par plages croissantes, de rangeChunk jusqu'à maxRangeChunk
+const (
+	rangeChunk    = 64 << 10
+	maxRangeChunk = 8 << 20
+)
+
+// rangeResult est une portion d'objet : data commence à l'octet start d'un objet de total octets
+type rangeResult struct {
+	data  []byte
+	start int64
+	total int64
+}
+
+// readRange lit une plage d'octets de l'objet (valeur d'en-tête Range, ex. bytes=0-99 ou bytes=-100).
+// Un objet vide ou une plage au-delà de la fin donne une portion vide.
+// Si le serveur ignore Range, l'objet entier n'est accepté que s'il reste petit.
+func readRange(ctx context.Context, client *s3Client, bucket, key, rangeHeader string) (*rangeResult, error) {
+	req, err := client.newRequest(ctx, "GET", bucket, key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Range", rangeHeader)
+	resp, err := client.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		start, total, err := parseContentRange(resp.Header.Get("Content-Range"))
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+		return &rangeResult{data: data, start: start, total: total}, nil
+
+	case http.StatusOK:
+		data, err := io.ReadAll(io.LimitReader(resp.Body, maxRangeChunk+1))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+		if len(data) > maxRangeChunk {
+			return nil, fmt.Errorf("the server ignored the Range header and returned the whole object; ranged reads are not supported by this endpoint")
+		}
+		return &rangeResult{data: data, start: 0, total: int64(len(data))}, nil
+
+	case http.StatusRequestedRangeNotSatisfiable:
+		_, total, _ := parseContentRange(resp.Header.Get("Content-Range"))
+		total = max(total, 0)
+		return &rangeResult{start: total, total: total}, nil
+
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("object '%s' not found in bucket '%s'", key, bucket)
+
+	default:
+		return nil, responseError(resp)
+	}
+}
+
+// parseContentRange lit un en-tête Content-Range ("bytes 100-199/1000" ou "bytes */1000").
+// Une taille totale inconnue ("*") vaut -1.
+func parseContentRange(value string) (start, total int64, err error) {
+	spec, ok := strings.CutPrefix(value, "bytes ")
+	rangePart, totalPart, found := strings.Cut(spec, "/")
+	if !ok || !found {
+		return 0, 0, fmt.Errorf("invalid Content-Range '%s' in response", value)
+	}
+
+	total = -1
+	if totalPart != "*" {
+		if total, err = strconv.ParseInt(totalPart, 10, 64); err != nil {
+			return 0, 0, fmt.Errorf("invalid Content-Range '%s' in response", value)
+		}
+	}
+	if rangePart == "*" {
+		return total, total, nil
+	}
+	first, _, _ := strings.Cut(rangePart, "-")
+	if start, err = strconv.ParseInt(first, 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("invalid Content-Range '%s' in response", value)
+	}
+	return start, total, nil
+}
+
+// readHead retourne les n premières lignes de l'objet, en étendant la plage lue jusqu'à les trouver
+func readHead(ctx context.Context, client *s3Client, bucket, key string, lines int) ([]byte, error) {
+	var data []byte
+	chunk := int64(rangeChunk)
+	for {
+		offset := int64(len(data))
+		res, err := readRange(ctx, client, bucket, key, fmt.Sprintf("bytes=%d-%d", offset, offset+chunk-1))
+		if err != nil {
+			return nil, err
+		}
+		// Une réponse portant l'objet entier (start 0) remplace ce qui a déjà été lu
+		data = append(data[:min(res.start, offset)], res.data...)
+
+		if bytes.Count(data, []byte("\n")) >= lines || len(res.data) == 0 || int64(len(data)) >= res.total {
+			return firstLines(data, lines), nil
+		}
+		chunk = min(chunk*2, maxRangeChunk)
+	}
+}
+
+// readTail retourne les n dernières lignes de l'objet : la plage lue depuis la fin est étendue
+// vers le début jusqu'à contenir n retours à la ligne, ou jusqu'au début de l'objet
+func readTail(ctx context.Context, client *s3Client, bucket, key string, lines int) ([]byte, error) {
+	chunk := int64(rangeChunk)
+	res, err := readRange(ctx, client, bucket, key, fmt.Sprintf("bytes=-%d", chunk))
+	if err != nil {
+		return nil, err
+	}
+	data, start := res.data, res.start
+
+	for start > 0 && countLineBreaks(data) < lines {
+		chunk = min(chunk*2, maxRangeChunk)
+		from := max(start-chunk, 0)
+		res, err := readRange(ctx, client, bucket, key, fmt.Sprintf("bytes=%d-%d", from, start-1))
+		if err != nil {
+			return nil, err
+		}
+		if res.start == 0 && int64(len(res.data)) == res.total {
+			// Objet entier renvoyé
+			data, start = res.data, 0
+			break
+		}
+		data, start = append(res.data, data...), res.start
+	}
+	return lastLines(data, lines), nil
+}
+
+// countLineBreaks compte les retours à la ligne séparant des lignes, sans celui qui termine la dernière
+func countLineBreaks(data []byte) int {
+	return bytes.Count(bytes.TrimSuffix(data, []byte("\n")), []byte("\n"))
+}
+
+// firstLines retourne les n premières lignes de data
+func firstLines(data []byte, n int) []byte {
+	end := 0
+	for i := 0; i < n; i++ {
+		next := bytes.IndexByte(data[end:], '\n')
+		if next < 0 {
+			return data
+		}
+		end += next + 1
+	}
+	return data[:end]
+}
+
+// lastLines retourne les n dernières lignes de data
+func lastLines(data []byte, n int) []byte {
+	if n <= 0 {
+		return nil
+	}
+	body := bytes.TrimSuffix(data, []byte("\n"))
+	for i := 0; i < n; i++ {
+		prev := bytes.LastIndexByte(body, '\n')
+		if prev < 0 {
+			return data
+		}
+		body = body[:prev]
+	}
+	return data[len(body)+1:]
+}
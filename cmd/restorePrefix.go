@@ -0,0 +1,251 @@
+package cmd
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	restoreAsOf      string
+	restoreDryRun    bool
+	restoreDeleteNew bool
+)
+
+// restoreStep est une action du plan de restauration d'un préfixe
+type restoreStep struct {
+	Key     string
+	Action  string // "restore", "recreate" ou "delete"
+	Version string // version à recopier (restore, recreate)
+	Current string // version courante remplacée ou supprimée
+}
+
+// RestorePrefixCmd représente la commande restore-prefix
+var RestorePrefixCmd = &cobra.Command{
+	Use:   "restore-prefix <bucket-name> [prefix] --as-of <timestamp>",
+	Short: "Restores the objects under a prefix to the versions that were current at a given time",
+	Long: `Rebuilds the state of a prefix at --as-of from the object versions, then copies those
+versions back over the current ones. Keys deleted since then are recreated; keys created
+afterwards are kept unless --delete-new is given. --dry-run only prints the plan.
+For example:
+
+bs3 restore-prefix photos 2024/ --as-of "2024-05-02 18:00" --dry-run`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 || restoreAsOf == "" {
+			log.Println("Usage: restore-prefix <bucket-name> [prefix] --as-of <timestamp> [--dry-run]")
+			return
+		}
+		bucketName := args[0]
+		prefix := ""
+		if len(args) > 1 {
+			prefix = args[1]
+		}
+
+		asOf, err := parseTimestamp(restoreAsOf, time.Now())
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+
+		client, err := newConfiguredClient()
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+
+		versions, err := listObjectVersions(cmd.Context(), client, bucketName, prefix, 0)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		plan, unchanged := restorePlan(versions, asOf, restoreDeleteNew)
+
+		fmt.Printf("Restoring s3://%s/%s to its state as of %s:\n", bucketName, prefix, asOf.Format(time.RFC3339))
+		for _, step := range plan {
+			switch step.Action {
+			case "restore":
+				fmt.Printf("  restore   %s (version %s replaces %s)\n", step.Key, step.Version, step.Current)
+			case "recreate":
+				fmt.Printf("  recreate  %s (version %s, deleted since)\n", step.Key, step.Version)
+			case "delete":
+				fmt.Printf("  delete    %s (created after, current version %s)\n", step.Key, step.Current)
+			}
+		}
+		fmt.Printf("Plan: %d to restore, %d to delete, %d unchanged.\n", countSteps(plan, "restore")+countSteps(plan, "recreate"), countSteps(plan, "delete"), unchanged)
+
+		if restoreDryRun || len(plan) == 0 {
+			if restoreDryRun {
+				fmt.Println("Dry run: no changes made.")
+			}
+			return
+		}
+
+		failed := 0
+		var toDelete []string
+		for _, step := range plan {
+			if step.Action == "delete" {
+				toDelete = append(toDelete, step.Key)
+				continue
+			}
+			if _, err := copyObject(cmd.Context(), client, bucketName, step.Key, step.Version, bucketName, step.Key, nil); err != nil {
+				log.Printf("Error: failed to restore '%s': %v", step.Key, err)
+				failed++
+			}
+		}
+		if len(toDelete) > 0 {
+			if err := deleteKeys(cmd.Context(), client, bucketName, toDelete); err != nil {
+				log.Printf("Error: failed to delete keys created after %s: %v", asOf.Format(time.RFC3339), err)
+				failed += len(toDelete)
+				var partial *deleteKeysError
+				if errors.As(err, &partial) {
+					failed -= partial.deleted
+				}
+			}
+		}
+
+		if failed > 0 {
+			log.Printf("Error: %d of %d change(s) failed", failed, len(plan))
+			return
+		}
+		fmt.Printf("Restore completed: %d change(s) applied.\n", len(plan))
+	},
+}
+
+// restorePlan compare l'état à la date asOf à l'état courant et retourne les actions à mener,
+// ainsi que le nombre de clés déjà dans la bonne version
+func restorePlan(versions []versionEntry, asOf time.Time, deleteNew bool) ([]restoreStep, int) {
+	current := latestVersions(versions)
+	var plan []restoreStep
+	unchanged := 0
+
+	for _, past := range versionsAsOf(versions, asOf) {
+		now, exists := current[past.Key]
+		delete(current, past.Key)
+		switch {
+		case !exists:
+			plan = append(plan, restoreStep{Key: past.Key, Action: "recreate", Version: past.VersionID})
+		case !sameContent(now, past):
+			plan = append(plan, restoreStep{Key: past.Key, Action: "restore", Version: past.VersionID, Current: now.VersionID})
+		default:
+			unchanged++
+		}
+	}
+
+	// Les clés restantes n'existaient pas à la date demandée
+	if deleteNew {
+		for key, now := range current {
+			plan = append(plan, restoreStep{Key: key, Action: "delete", Current: now.VersionID})
+		}
+	} else {
+		unchanged += len(current)
+	}
+
+	sort.Slice(plan, func(i, j int) bool { return plan[i].Key < plan[j].Key })
+	return plan, unchanged
+}
+
+// sameContent indique si la version courante est la version passée ou une copie identique
+// (une restauration précédente crée une nouvelle version de même ETag)
+func sameContent(now, past versionEntry) bool {
+	if now.VersionID == past.VersionID {
+		return true
+	}
+	return past.ETag != "" && now.ETag == past.ETag && now.Size == past.Size
+}
+
+func countSteps(plan []restoreStep, action string) int {
+	n := 0
+	for _, step := range plan {
+		if step.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// deleteKeysError signale une suppression groupée incomplète : clés refusées par S3, ou lot en échec
+// (cause) après que les lots précédents ont été supprimés
+type deleteKeysError struct {
+	deleted int
+	total   int
+	cause   error
+}
+
+func (e *deleteKeysError) Error() string {
+	if e.cause != nil {
+		return fmt.Sprintf("%v (%d of %d key(s) already deleted)", e.cause, e.deleted, e.total)
+	}
+	return fmt.Sprintf("%d of %d key(s) could not be deleted", e.total-e.deleted, e.total)
+}
+
+func (e *deleteKeysError) Unwrap() error {
+	return e.cause
+}
+
+// deleteKeys supprime les clés par lots de 1000 (limite de DeleteObjects) ;
+// dans un bucket versionné, un marqueur de suppression est ajouté.
+// S3 répond 200 même si des clés sont refusées : chacune est signalée. En cas d'échec,
+// l'erreur (*deleteKeysError) indique combien de clés ont déjà été supprimées.
+func deleteKeys(ctx context.Context, client *s3Client, bucketName string, keys []string) error {
+	deleted := 0
+	for start := 0; start < len(keys); start += 1000 {
+		var objects []ObjectToDelete
+		for _, key := range keys[start:min(start+1000, len(keys))] {
+			objects = append(objects, ObjectToDelete{Key: key})
+		}
+		refused, err := deleteBatch(ctx, client, bucketName, objects)
+		if err != nil {
+			return &deleteKeysError{deleted: deleted, total: len(keys), cause: err}
+		}
+		for _, deleteErr := range refused {
+			log.Printf("Error: failed to delete '%s': %s: %s", deleteErr.Key, deleteErr.Code, deleteErr.Message)
+		}
+		deleted += len(objects) - len(refused)
+	}
+	if deleted < len(keys) {
+		return &deleteKeysError{deleted: deleted, total: len(keys)}
+	}
+	return nil
+}
+
+// deleteBatch envoie un lot de DeleteObjects et retourne les clés refusées par S3
+func deleteBatch(ctx context.Context, client *s3Client, bucketName string, objects []ObjectToDelete) ([]DeleteError, error) {
+	req, err := newDeleteObjectsRequest(ctx, client, bucketName, objects)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return nil, responseError(resp)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read delete response: %w", err)
+	}
+	var result DeleteResult
+	if len(data) > 0 {
+		if err := xml.Unmarshal(data, &result); err != nil {
+			return nil, fmt.Errorf("failed to parse delete response: %w", err)
+		}
+	}
+	return result.Errors, nil
+}
+
+func init() {
+	RestorePrefixCmd.Flags().StringVar(&restoreAsOf, "as-of", "", "point in time to restore (RFC 3339, 'YYYY-MM-DD HH:MM' or a duration such as 24h)")
+	RestorePrefixCmd.Flags().BoolVar(&restoreDryRun, "dry-run", false, "print the restore plan without changing anything")
+	RestorePrefixCmd.Flags().BoolVar(&restoreDeleteNew, "delete-new", false, "also delete keys created after the restore time")
+	RootCmd.AddCommand(RestorePrefixCmd)
+}
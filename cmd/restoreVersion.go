@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/spf13/cobra"
+)
+
+// RestoreVersionCmd représente la commande restore-version
+var RestoreVersionCmd = &cobra.Command{
+	Use:   "restore-version <bucket-name> <object-key> <version-id> | restore-version s3://bucket/key <version-id>",
+	Short: "Makes an older version of an object the current one",
+	Long: `Restores an older version by copying it onto the same key: the copy becomes the
+latest version and the history is kept. Use list-object-versions to find version ids.
+For example:
+
+bs3 restore-version s3://docs/report.pdf 3HL4kqtJlcpXroDTDmJ`,
+	Run: func(cmd *cobra.Command, args []string) {
+		bucketName, key, rest, err := objectArgs(args)
+		if err == nil && len(rest) < 1 {
+			err = fmt.Errorf("a version id is required")
+		}
+		if err != nil {
+			log.Printf("Usage: restore-version <bucket-name> <object-key> <version-id> (%v)", err)
+			return
+		}
+		versionID := rest[0]
+
+		client, err := newConfiguredClient()
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+
+		result, err := copyObject(cmd.Context(), client, bucketName, key, versionID, bucketName, key, nil)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		fmt.Printf("Restored version '%s' of '%s' in bucket '%s'.\n", versionID, key, bucketName)
+		if result.VersionID != "" {
+			fmt.Printf("New current version: %s\n", result.VersionID)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(RestoreVersionCmd)
+}
@@ -0,0 +1,396 @@
+package cmd
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Modes de rétention Object Lock
+const (
+	lockGovernance = "GOVERNANCE"
+	lockCompliance = "COMPLIANCE"
+)
+
+// bypassGovernanceHeader autorise à raccourcir ou lever une rétention GOVERNANCE (permission s3:BypassGovernanceRetention)
+const bypassGovernanceHeader = "X-Amz-Bypass-Governance-Retention"
+
+// Retention représente le document XML de la sous-ressource ?retention d'un objet
+type Retention struct {
+	XMLName         xml.Name `xml:"Retention"`
+	Xmlns           string   `xml:"xmlns,attr,omitempty"`
+	Mode            string   `xml:"Mode,omitempty"`
+	RetainUntilDate string   `xml:"RetainUntilDate,omitempty"`
+}
+
+// LegalHold représente le document XML de la sous-ressource ?legal-hold d'un objet
+type LegalHold struct {
+	XMLName xml.Name `xml:"LegalHold"`
+	Xmlns   string   `xml:"xmlns,attr,omitempty"`
+	Status  string   `xml:"Status"`
+}
+
+// DefaultRetention est la rétention appliquée aux nouvelles versions d'un bucket (en jours ou en années)
+type DefaultRetention struct {
+	Mode  string `xml:"Mode"`
+	Days  int    `xml:"Days,omitempty"`
+	Years int    `xml:"Years,omitempty"`
+}
+
+// ObjectLockRule porte la rétention par défaut d'un bucket
+type ObjectLockRule struct {
+	DefaultRetention DefaultRetention `xml:"DefaultRetention"`
+}
+
+// ObjectLockConfiguration représente le document XML de la sous-ressource ?object-lock d'un bucket
+type ObjectLockConfiguration struct {
+	XMLName           xml.Name        `xml:"ObjectLockConfiguration"`
+	Xmlns             string          `xml:"xmlns,attr,omitempty"`
+	ObjectLockEnabled string          `xml:"ObjectLockEnabled"`
+	Rule              *ObjectLockRule `xml:"Rule"`
+}
+
+var (
+	retentionMode      string
+	retentionUntil     string
+	retentionDays      int
+	retentionYears     int
+	retentionVersionID string
+	retentionBypass    bool
+)
+
+// RetentionCmd regroupe les sous-commandes de rétention Object Lock
+var RetentionCmd = &cobra.Command{
+	Use:   "retention",
+	Short: "Reads or sets the Object Lock retention of an object, or the default retention of a bucket",
+	Long: `Reads or sets Object Lock retention. The target is an object (<bucket-name> <object-key>
+or s3://bucket/key), whose version is then protected until a date, or a whole bucket
+(<bucket-name> or s3://bucket), whose default retention applies to new object versions.
+The bucket must have been created with create-bucket --object-lock.
+
+GOVERNANCE retention can be shortened or removed with --bypass-governance (permission
+s3:BypassGovernanceRetention); COMPLIANCE retention cannot be shortened by anyone.
+For example:
+
+bs3 retention set s3://records/2024/ledger.csv --mode COMPLIANCE --until 2031-12-31
+bs3 retention set records --mode GOVERNANCE --days 90`,
+}
+
+var retentionGetCmd = &cobra.Command{
+	Use:   "get <target>",
+	Short: "Shows the retention and legal hold of an object, or the default retention of a bucket",
+	Run: func(cmd *cobra.Command, args []string) {
+		bucketName, key, _, err := tagTarget(args)
+		if err != nil {
+			log.Printf("Usage: retention get <bucket-name> [object-key] [--version-id <id>] (%v)", err)
+			return
+		}
+		client, err := newConfiguredClient()
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+
+		if key == "" {
+			config, err := getObjectLockConfiguration(cmd.Context(), client, bucketName)
+			if err == nil && config == nil {
+				err = errObjectLockDisabled(bucketName)
+			}
+			if err != nil {
+				log.Printf("Error: %v", err)
+				return
+			}
+			if config.Rule == nil {
+				fmt.Printf("Object Lock is enabled on bucket '%s', without default retention.\n", bucketName)
+				return
+			}
+			fmt.Printf("Default retention of bucket '%s': %s\n", bucketName, describeDefaultRetention(config.Rule.DefaultRetention))
+			return
+		}
+
+		status, err := getLockStatus(cmd.Context(), client, bucketName, key, retentionVersionID)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		fmt.Printf("Retention:  %s\n", status.describeRetention())
+		fmt.Printf("Legal hold: %s\n", status.legalHold)
+	},
+}
+
+var retentionSetCmd = &cobra.Command{
+	Use:   "set <target> --mode <GOVERNANCE|COMPLIANCE> (--until <date> | --days <n> | --years <n>)",
+	Short: "Protects an object version until a date, or sets the default retention of a bucket",
+	Run: func(cmd *cobra.Command, args []string) {
+		bucketName, key, _, err := tagTarget(args)
+		if err != nil || retentionMode == "" {
+			log.Println("Usage: retention set <bucket-name> <object-key> --mode <GOVERNANCE|COMPLIANCE> --until <date>")
+			log.Println("       retention set <bucket-name> --mode <GOVERNANCE|COMPLIANCE> (--days <n> | --years <n>)")
+			return
+		}
+		mode, err := parseLockMode(retentionMode)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		client, err := newConfiguredClient()
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+
+		if key == "" {
+			if retentionUntil != "" || (retentionDays > 0) == (retentionYears > 0) {
+				log.Println("Error: the default retention of a bucket takes either --days or --years")
+				return
+			}
+			retention := DefaultRetention{Mode: mode, Days: retentionDays, Years: retentionYears}
+			if err := putDefaultRetention(cmd.Context(), client, bucketName, &retention); err != nil {
+				log.Printf("Error: %v", err)
+				return
+			}
+			fmt.Printf("Default retention of bucket '%s' set to %s.\n", bucketName, describeDefaultRetention(retention))
+			return
+		}
+
+		if retentionUntil == "" || retentionDays > 0 || retentionYears > 0 {
+			log.Println("Error: the retention of an object takes a retain-until date (--until)")
+			return
+		}
+		until, err := parseRetainUntil(retentionUntil, time.Now())
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		retention := Retention{Xmlns: s3XMLNamespace, Mode: mode, RetainUntilDate: until.UTC().Format(time.RFC3339)}
+		if err := putRetention(cmd.Context(), client, bucketName, key, retention); err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		fmt.Printf("Object '%s' in bucket '%s' retained in %s mode until %s.\n", key, bucketName, mode, retention.RetainUntilDate)
+	},
+}
+
+var retentionClearCmd = &cobra.Command{
+	Use:   "clear <target>",
+	Short: "Removes the GOVERNANCE retention of an object (with --bypass-governance), or the default retention of a bucket",
+	Run: func(cmd *cobra.Command, args []string) {
+		bucketName, key, _, err := tagTarget(args)
+		if err != nil {
+			log.Printf("Usage: retention clear <bucket-name> [object-key] [--bypass-governance] (%v)", err)
+			return
+		}
+		client, err := newConfiguredClient()
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+
+		if key == "" {
+			if err := putDefaultRetention(cmd.Context(), client, bucketName, nil); err != nil {
+				log.Printf("Error: %v", err)
+				return
+			}
+			fmt.Printf("Default retention of bucket '%s' removed; Object Lock stays enabled.\n", bucketName)
+			return
+		}
+		if err := putRetention(cmd.Context(), client, bucketName, key, Retention{Xmlns: s3XMLNamespace}); err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		fmt.Printf("Retention of object '%s' in bucket '%s' removed.\n", key, bucketName)
+	},
+}
+
+// parseLockMode valide la valeur de --mode
+func parseLockMode(value string) (string, error) {
+	switch mode := strings.ToUpper(value); mode {
+	case lockGovernance, lockCompliance:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unsupported retention mode '%s' (expected GOVERNANCE or COMPLIANCE)", value)
+	}
+}
+
+// parseRetainUntil lit une date de fin de rétention : une date (comme --as-of) ou une durée à partir
+// de maintenant (ex. 720h). Une date sans heure désigne la fin de ce jour.
+func parseRetainUntil(value string, now time.Time) (time.Time, error) {
+	var until time.Time
+	if d, err := time.ParseDuration(value); err == nil {
+		until = now.Add(d)
+	} else if day, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		until = day.Add(24*time.Hour - time.Second)
+	} else if until, err = parseTimestamp(value, now); err != nil {
+		return time.Time{}, err
+	}
+	if !until.After(now) {
+		return time.Time{}, fmt.Errorf("retain-until date %s is not in the future", until.UTC().Format(time.RFC3339))
+	}
+	return until, nil
+}
+
+func describeDefaultRetention(retention DefaultRetention) string {
+	if retention.Years > 0 {
+		return fmt.Sprintf("%s for %d year(s)", retention.Mode, retention.Years)
+	}
+	return fmt.Sprintf("%s for %d day(s)", retention.Mode, retention.Days)
+}
+
+// getObjectLockConfiguration lit la configuration Object Lock d'un bucket ; nil (sans erreur) si Object Lock n'est pas activé
+func getObjectLockConfiguration(ctx context.Context, client *s3Client, bucketName string) (*ObjectLockConfiguration, error) {
+	data, err := client.getSubresource(ctx, bucketName, "", "object-lock", nil)
+	if s3Err, ok := err.(*S3Error); ok && s3Err.Code == "ObjectLockConfigurationNotFoundError" {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, objectLockError(err, bucketName)
+	}
+	var config ObjectLockConfiguration
+	if err := xml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse Object Lock configuration: %w", err)
+	}
+	return &config, nil
+}
+
+// putDefaultRetention remplace la rétention par défaut du bucket (nil pour la retirer)
+func putDefaultRetention(ctx context.Context, client *s3Client, bucketName string, retention *DefaultRetention) error {
+	config := ObjectLockConfiguration{Xmlns: s3XMLNamespace, ObjectLockEnabled: "Enabled"}
+	if retention != nil {
+		config.Rule = &ObjectLockRule{DefaultRetention: *retention}
+	}
+	body, err := xml.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal Object Lock configuration: %w", err)
+	}
+	return objectLockError(client.putSubresource(ctx, bucketName, "", "object-lock", nil, body, nil), bucketName)
+}
+
+// putRetention remplace la rétention de l'objet (ou de la version --version-id) ; une rétention vide la lève
+func putRetention(ctx context.Context, client *s3Client, bucketName, key string, retention Retention) error {
+	body, err := xml.Marshal(retention)
+	if err != nil {
+		return fmt.Errorf("failed to marshal retention: %w", err)
+	}
+	header := http.Header{}
+	if retentionBypass {
+		header.Set(bypassGovernanceHeader, "true")
+	}
+	err = client.putSubresource(ctx, bucketName, key, "retention", versionQuery(retentionVersionID), body, header)
+	var s3Err *S3Error
+	if errors.As(err, &s3Err) && s3Err.Code == "AccessDenied" {
+		return fmt.Errorf("%w: a COMPLIANCE retention cannot be shortened or removed, and a GOVERNANCE retention only with --bypass-governance", err)
+	}
+	return objectLockError(err, bucketName)
+}
+
+// objectLockError explique les erreurs d'un bucket créé sans Object Lock
+func objectLockError(err error, bucketName string) error {
+	var s3Err *S3Error
+	if !errors.As(err, &s3Err) {
+		return err
+	}
+	if s3Err.Code == "ObjectLockConfigurationNotFoundError" || ((s3Err.Code == "InvalidRequest" || s3Err.Code == "InvalidBucketState") && strings.Contains(s3Err.Message, "Object Lock")) {
+		return errObjectLockDisabled(bucketName)
+	}
+	return err
+}
+
+func errObjectLockDisabled(bucketName string) error {
+	return fmt.Errorf("bucket '%s' does not have Object Lock enabled; it can only be enabled at creation (create-bucket --object-lock)", bucketName)
+}
+
+// lockStatus décrit ce qui protège une version d'objet : sa rétention et sa suspension légale
+type lockStatus struct {
+	mode      string
+	until     time.Time
+	legalHold string
+}
+
+// getLockStatus lit la rétention et la suspension légale d'un objet (ou d'une version)
+func getLockStatus(ctx context.Context, client *s3Client, bucketName, key, versionID string) (lockStatus, error) {
+	status := lockStatus{legalHold: "OFF"}
+
+	data, err := client.getSubresource(ctx, bucketName, key, "retention", versionQuery(versionID))
+	var s3Err *S3Error
+	switch {
+	case errors.As(err, &s3Err) && s3Err.Code == "NoSuchObjectLockConfiguration":
+	case err != nil:
+		return status, objectLockError(err, bucketName)
+	default:
+		var retention Retention
+		if err := xml.Unmarshal(data, &retention); err != nil {
+			return status, fmt.Errorf("failed to parse retention: %w", err)
+		}
+		status.mode = retention.Mode
+		status.until, _ = time.Parse(time.RFC3339, retention.RetainUntilDate)
+	}
+
+	data, err = client.getSubresource(ctx, bucketName, key, "legal-hold", versionQuery(versionID))
+	switch {
+	case errors.As(err, &s3Err) && s3Err.Code == "NoSuchObjectLockConfiguration":
+	case err != nil:
+		return status, objectLockError(err, bucketName)
+	default:
+		var hold LegalHold
+		if err := xml.Unmarshal(data, &hold); err != nil {
+			return status, fmt.Errorf("failed to parse legal hold: %w", err)
+		}
+		if hold.Status != "" {
+			status.legalHold = hold.Status
+		}
+	}
+	return status, nil
+}
+
+func (s lockStatus) retained(now time.Time) bool {
+	return s.mode != "" && s.until.After(now)
+}
+
+func (s lockStatus) describeRetention() string {
+	if s.mode == "" {
+		return "none"
+	}
+	text := fmt.Sprintf("%s until %s", s.mode, s.until.UTC().Format(time.RFC3339))
+	if !s.retained(time.Now()) {
+		text += " (expired)"
+	}
+	return text
+}
+
+// blockReason explique pourquoi la suppression d'une version est refusée, et comment la débloquer
+func (s lockStatus) blockReason(bypass bool) string {
+	switch {
+	case s.legalHold == "ON":
+		return "it is under legal hold; remove it with legal-hold off first"
+	case s.retained(time.Now()) && s.mode == lockCompliance:
+		return fmt.Sprintf("it is retained in COMPLIANCE mode until %s and cannot be deleted before", s.until.UTC().Format(time.RFC3339))
+	case s.retained(time.Now()) && !bypass:
+		return fmt.Sprintf("it is retained in GOVERNANCE mode until %s; retry with --bypass-governance if you have the s3:BypassGovernanceRetention permission", s.until.UTC().Format(time.RFC3339))
+	case s.retained(time.Now()):
+		return fmt.Sprintf("it is retained in GOVERNANCE mode until %s and the governance bypass was refused", s.until.UTC().Format(time.RFC3339))
+	default:
+		return "it is protected by Object Lock"
+	}
+}
+
+func init() {
+	retentionSetCmd.Flags().StringVar(&retentionMode, "mode", "", "retention mode: GOVERNANCE or COMPLIANCE")
+	retentionSetCmd.Flags().StringVar(&retentionUntil, "until", "", "retain an object until this date (RFC 3339, 'YYYY-MM-DD[ HH:MM[:SS]]') or for a duration such as 720h")
+	retentionSetCmd.Flags().IntVar(&retentionDays, "days", 0, "default retention of a bucket, in days")
+	retentionSetCmd.Flags().IntVar(&retentionYears, "years", 0, "default retention of a bucket, in years")
+	for _, c := range []*cobra.Command{retentionGetCmd, retentionSetCmd, retentionClearCmd} {
+		c.Flags().StringVar(&retentionVersionID, "version-id", "", "target a specific version of the object")
+	}
+	for _, c := range []*cobra.Command{retentionSetCmd, retentionClearCmd} {
+		c.Flags().BoolVar(&retentionBypass, "bypass-governance", false, "allow shortening or removing a GOVERNANCE retention")
+	}
+	RetentionCmd.AddCommand(retentionGetCmd, retentionSetCmd, retentionClearCmd)
+	RootCmd.AddCommand(RetentionCmd)
+}
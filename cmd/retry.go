@@ -0,0 +1,219 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultMaxAttempts    = 3
+	defaultRetryBaseDelay = 200 * time.Millisecond
+	defaultRetryMaxDelay  = 20 * time.Second
+
+	// defaultOperationTimeout s'applique aux opérations hors transferts de données
+	defaultOperationTimeout = 30 * time.Second
+)
+
+// transferOperations n'ont pas de délai global par défaut : leur durée dépend de la taille des données
+var transferOperations = map[string]bool{"GetObject": true, "PutObject": true, "UploadPart": true}
+
+// S3ErrorResponse représente le corps XML d'une erreur S3
+type S3ErrorResponse struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// cancelOnClose libère le contexte de la tentative une fois le corps de la réponse fermé
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
+// operationTimeout retourne le délai configuré pour l'opération (timeouts.<Opération>, puis timeouts.default)
+func (c *s3Client) operationTimeout(operation string) time.Duration {
+	if timeout, ok := c.profile.Timeouts[strings.ToLower(operation)]; ok {
+		return timeout
+	}
+	if transferOperations[operation] {
+		return 0
+	}
+	if timeout, ok := c.profile.Timeouts["default"]; ok {
+		return timeout
+	}
+	return defaultOperationTimeout
+}
+
+// sendWithRetry envoie la requête et la rejoue sur les erreurs transitoires,
+// avec un backoff exponentiel à jitter complet et le respect de Retry-After
+func (c *s3Client) sendWithRetry(req *http.Request) (*http.Response, error) {
+	target, _ := req.Context().Value(requestTargetKey{}).(requestTarget)
+	operation := operationName(req.Method, target, req.URL.Query(), req.Header)
+	timeout := c.operationTimeout(operation)
+
+	for attempt := 1; ; attempt++ {
+		attemptReq, cancel, err := c.attemptRequest(req, attempt, timeout)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := c.send(attemptReq)
+		if err == nil {
+			resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+		} else {
+			cancel()
+		}
+
+		retry := retryDecision(req, resp, err)
+		if !retry || attempt >= c.profile.MaxAttempts || !canReplay(req) {
+			return resp, err
+		}
+
+		// Signaler la nouvelle tentative au suivi de progression (--progress=json)
+		if t := transferFrom(req.Context()); t != nil {
+			if err != nil {
+				t.retried(attempt+1, err.Error())
+			} else {
+				t.retried(attempt+1, resp.Status)
+			}
+		}
+
+		// Le Retry-After du serveur est respecté, dans la limite du délai maximal du profil
+		delay := c.backoff(attempt)
+		if resp != nil {
+			if after := retryAfter(resp.Header.Get("Retry-After")); after > delay {
+				delay = min(after, c.profile.RetryMaxDelay)
+			}
+			resp.Body.Close()
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+// attemptRequest prépare une tentative : délai propre et corps rembobiné à partir de la deuxième
+func (c *s3Client) attemptRequest(req *http.Request, attempt int, timeout time.Duration) (*http.Request, context.CancelFunc, error) {
+	ctx, cancel := req.Context(), context.CancelFunc(func() {})
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+
+	attemptReq := req.Clone(ctx)
+	if attempt > 1 && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			cancel()
+			return nil, nil, err
+		}
+		attemptReq.Body = body
+	}
+	return attemptReq, cancel, nil
+}
+
+// canReplay indique si le corps de la requête peut être renvoyé
+func canReplay(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// isIdempotent indique si la requête peut être rejouée sans effet de bord.
+// Parmi les POST S3, seules la suppression groupée et la finalisation multipart le sont.
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case "GET", "HEAD", "PUT", "DELETE", "OPTIONS":
+		return true
+	case "POST":
+		query := req.URL.Query()
+		return query.Has("delete") || query.Has("uploadId")
+	}
+	return false
+}
+
+// retryDecision détermine si l'échec est transitoire.
+// Le corps des réponses d'erreur est relu puis restitué à l'appelant.
+func retryDecision(req *http.Request, resp *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		// Annulation demandée par l'appelant : on ne réessaie pas
+		return false
+	}
+	if err != nil {
+		return isIdempotent(req) && isTransientNetworkError(err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusBadRequest, http.StatusTooManyRequests, http.StatusInternalServerError,
+		http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+	default:
+		return false
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+
+	var s3Err S3ErrorResponse
+	xml.Unmarshal(body, &s3Err)
+
+	switch {
+	case s3Err.Code == "SlowDown" || resp.StatusCode == http.StatusTooManyRequests:
+		// Requête refusée avant traitement : rejouable même si elle n'est pas idempotente
+		return true
+	case resp.StatusCode == http.StatusBadRequest:
+		return s3Err.Code == "RequestTimeout" && isIdempotent(req)
+	default:
+		return isIdempotent(req)
+	}
+}
+
+// isTransientNetworkError reconnaît les coupures et délais réseau
+func isTransientNetworkError(err error) bool {
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// backoff retourne un délai aléatoire dans [0, min(max, base*2^(attempt-1))]
+func (c *s3Client) backoff(attempt int) time.Duration {
+	ceiling := c.profile.RetryBaseDelay << (attempt - 1)
+	if ceiling <= 0 || ceiling > c.profile.RetryMaxDelay {
+		ceiling = c.profile.RetryMaxDelay
+	}
+	return time.Duration(rand.Int63n(int64(ceiling) + 1))
+}
+
+// retryAfter lit l'en-tête Retry-After (secondes ou date HTTP)
+func retryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date)
+	}
+	return 0
+}
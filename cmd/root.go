@@ -10,6 +10,9 @@ import (
 )
 
 var cfgFile string
+var profileName string
+var progressMode string
+var progressFD int
 
 // rootCmd représente la commande de base
 var RootCmd = &cobra.Command{
@@ -17,9 +20,16 @@ var RootCmd = &cobra.Command{
 	Short: "CLI to interact with S3 API",
 }
 
-// Execute exécute la commande root et toutes ses sous-commandes
+// Execute exécute la commande root et toutes ses sous-commandes.
+// Les commandes s'exécutent sous un contexte annulé par Ctrl-C (SIGINT) ou SIGTERM.
 func Execute() {
-	if err := RootCmd.Execute(); err != nil {
+	ctx, interrupted, stop := signalContext()
+	err := RootCmd.ExecuteContext(ctx)
+	stop()
+	if interrupted() {
+		os.Exit(interruptExitCode)
+	}
+	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
@@ -31,6 +41,14 @@ func init() {
 
 	// Définir un flag pour permettre à l'utilisateur de spécifier un fichier de configuration
 	RootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.my-cli.yaml)")
+
+	// Profil de connexion à utiliser (section `profiles.<nom>` du fichier de configuration)
+	RootCmd.PersistentFlags().StringVar(&profileName, "profile", "", "configuration profile to use (default uses the s3 section, env MYCLI_PROFILE)")
+	viper.BindPFlag("profile", RootCmd.PersistentFlags().Lookup("profile"))
+
+	// Affichage de la progression des transferts
+	RootCmd.PersistentFlags().StringVar(&progressMode, "progress", progressAuto, "transfer progress display: auto (bar when stdout is a terminal), bar, json or none")
+	RootCmd.PersistentFlags().IntVar(&progressFD, "progress-fd", 2, "file descriptor receiving --progress=json events (default stderr)")
 }
 
 // initConfig configure Viper pour lire les fichiers de configuration et les variables d'environnement
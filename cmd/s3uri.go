@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+)
+
+// parseS3URI découpe une URI de la forme s3://bucket/clé
+func parseS3URI(uri string) (bucket, key string, err error) {
+	rest, ok := strings.CutPrefix(uri, "s3://")
+	if !ok {
+		return "", "", fmt.Errorf("invalid S3 URI '%s' (expected s3://bucket/key)", uri)
+	}
+	bucket, key, _ = strings.Cut(rest, "/")
+	if bucket == "" {
+		return "", "", fmt.Errorf("invalid S3 URI '%s': missing bucket name", uri)
+	}
+	return bucket, key, nil
+}
+
+// objectArgs lit un objet désigné par `<bucket> <clé>` ou par `s3://bucket/clé`,
+// et retourne les arguments restants
+func objectArgs(args []string) (bucket, key string, rest []string, err error) {
+	if len(args) > 0 && strings.HasPrefix(args[0], "s3://") {
+		bucket, key, err = parseS3URI(args[0])
+		rest = args[1:]
+	} else if len(args) >= 2 {
+		bucket, key, rest = args[0], args[1], args[2:]
+	} else {
+		return "", "", nil, fmt.Errorf("an object is required: <bucket-name> <object-key> or s3://bucket/key")
+	}
+	if err == nil && key == "" {
+		err = fmt.Errorf("an object key is required")
+	}
+	return bucket, key, rest, err
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+)
+
+// interruptExitCode est le code de sortie conventionnel après un SIGINT (128 + 2)
+const interruptExitCode = 130
+
+// signalContext retourne un contexte annulé au premier SIGINT/SIGTERM, afin que les commandes
+// interrompent leurs requêtes et nettoient (fichiers temporaires, uploads multipart).
+// Un second signal force l'arrêt immédiat. stop cesse la surveillance des signaux.
+func signalContext() (ctx context.Context, interrupted func() bool, stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	var received atomic.Bool
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-signals:
+		case <-done:
+			return
+		}
+		received.Store(true)
+		fmt.Fprintln(os.Stderr, "\nInterrupted: cancelling in-flight requests and cleaning up (press Ctrl-C again to force exit)...")
+		cancel()
+
+		select {
+		case <-signals:
+			fmt.Fprintln(os.Stderr, "Forced exit.")
+			os.Exit(interruptExitCode)
+		case <-done:
+		}
+	}()
+
+	return ctx, received.Load, func() {
+		signal.Stop(signals)
+		close(done)
+		cancel()
+	}
+}
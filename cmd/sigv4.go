@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	sigV4Algorithm   = "AWS4-HMAC-SHA256"
+	amzDateFormat    = "20060102T150405Z"
+	shortDateFormat  = "20060102"
+	unsignedPayload  = "UNSIGNED-PAYLOAD"
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// sigV4Signer signe les requêtes selon AWS Signature Version 4
+type sigV4Signer struct {
+	creds   *Credentials
+	region  string
+	service string
+}
+
+func newSigner(creds *Credentials, region, service string) sigV4Signer {
+	return sigV4Signer{creds: creds, region: region, service: service}
+}
+
+// sign ajoute les en-têtes X-Amz-* et Authorization à la requête
+func (s sigV4Signer) sign(req *http.Request, payloadHash string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format(amzDateFormat)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if s.creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.creds.SessionToken)
+	}
+
+	signedHeaders := signableHeaders(req.Header)
+	canonical := canonicalRequest(req.Method, req.URL, requestHost(req), req.Header, signedHeaders, payloadHash)
+	signature := s.signature(stringToSign(amzDate, s.scope(now), canonical), now)
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		sigV4Algorithm, s.creds.AccessKeyID, s.scope(now), strings.Join(signedHeaders, ";"), signature))
+}
+
+// presign retourne une copie de l'URL signée dans la query-string, valable pendant expires
+func (s sigV4Signer) presign(method string, u *url.URL, expires time.Duration, now time.Time) *url.URL {
+	now = now.UTC()
+	amzDate := now.Format(amzDateFormat)
+
+	signed := *u
+	query := signed.Query()
+	query.Set("X-Amz-Algorithm", sigV4Algorithm)
+	query.Set("X-Amz-Credential", s.creds.AccessKeyID+"/"+s.scope(now))
+	query.Set("X-Amz-Date", amzDate)
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expires/time.Second)))
+	query.Set("X-Amz-SignedHeaders", "host")
+	if s.creds.SessionToken != "" {
+		query.Set("X-Amz-Security-Token", s.creds.SessionToken)
+	}
+	signed.RawQuery = canonicalQuery(query)
+
+	canonical := canonicalRequest(method, &signed, signed.Host, nil, []string{"host"}, unsignedPayload)
+	query.Set("X-Amz-Signature", s.signature(stringToSign(amzDate, s.scope(now), canonical), now))
+	signed.RawQuery = canonicalQuery(query)
+	return &signed
+}
+
+// scope retourne la portée de la signature : date/région/service/aws4_request
+func (s sigV4Signer) scope(now time.Time) string {
+	return strings.Join([]string{now.UTC().Format(shortDateFormat), s.region, s.service, "aws4_request"}, "/")
+}
+
+// signature calcule la signature hexadécimale d'une chaîne à signer
+func (s sigV4Signer) signature(toSign string, now time.Time) string {
+	key := hmacSHA256([]byte("AWS4"+s.creds.SecretAccessKey), now.UTC().Format(shortDateFormat))
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, s.service)
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, toSign))
+}
+
+func stringToSign(amzDate, scope, canonical string) string {
+	return strings.Join([]string{sigV4Algorithm, amzDate, scope, hashSHA256([]byte(canonical))}, "\n")
+}
+
+// canonicalRequest construit la requête canonique décrite par la spécification SigV4
+func canonicalRequest(method string, u *url.URL, host string, header http.Header, signedHeaders []string, payloadHash string) string {
+	var headers strings.Builder
+	for _, name := range signedHeaders {
+		value := host
+		if name != "host" {
+			value = strings.Join(header.Values(name), ",")
+		}
+		headers.WriteString(name + ":" + strings.Join(strings.Fields(value), " ") + "\n")
+	}
+
+	return strings.Join([]string{
+		method,
+		canonicalURI(u),
+		canonicalQuery(u.Query()),
+		headers.String(),
+		strings.Join(signedHeaders, ";"),
+		payloadHash,
+	}, "\n")
+}
+
+// signableHeaders retourne, triés, les en-têtes couverts par la signature
+func signableHeaders(header http.Header) []string {
+	names := []string{"host"}
+	for name := range header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-amz-") || lower == "content-type" || lower == "content-md5" {
+			names = append(names, lower)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func requestHost(req *http.Request) string {
+	if req.Host != "" {
+		return req.Host
+	}
+	return req.URL.Host
+}
+
+func canonicalURI(u *url.URL) string {
+	if u.Path == "" {
+		return "/"
+	}
+	segments := strings.Split(u.Path, "/")
+	for i, segment := range segments {
+		segments[i] = uriEncode(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+func canonicalQuery(values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var pairs []string
+	for _, key := range keys {
+		vals := append([]string(nil), values[key]...)
+		sort.Strings(vals)
+		for _, v := range vals {
+			pairs = append(pairs, uriEncode(key)+"="+uriEncode(v))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode encode une chaîne selon les règles SigV4 (seuls A-Z a-z 0-9 - _ . ~ restent en clair)
+func uriEncode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func hashSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Algorithmes de chiffrement côté serveur acceptés par S3
+const (
+	sseS3  = "AES256"
+	sseKMS = "aws:kms"
+)
+
+// sseCustomerKeyLen est la taille d'une clé SSE-C (AES-256)
+const sseCustomerKeyLen = 32
+
+// sseFlags regroupe les options de chiffrement côté serveur d'une commande
+type sseFlags struct {
+	algorithm string // --sse : AES256 (SSE-S3) ou aws:kms (SSE-KMS)
+	kmsKeyID  string
+	keyFile   string // --sse-c-key-file : clé SSE-C fournie par l'utilisateur
+	keyEnv    string // --sse-c-key-env : variable d'environnement contenant la clé SSE-C
+}
+
+// sseOptions est la forme validée des options : algorithme côté serveur et/ou clé client (SSE-C)
+type sseOptions struct {
+	algorithm   string
+	kmsKeyID    string
+	customerKey []byte
+}
+
+// addSSEFlags déclare les options de chiffrement ; write ajoute celles qui ne servent qu'à l'écriture
+// (SSE-S3 et SSE-KMS sont transparents en lecture, seule la clé SSE-C doit être renvoyée)
+func addSSEFlags(cmd *cobra.Command, flags *sseFlags, write bool) {
+	if write {
+		cmd.Flags().StringVar(&flags.algorithm, "sse", "", "server-side encryption: AES256 (SSE-S3) or aws:kms (SSE-KMS)")
+		cmd.Flags().StringVar(&flags.kmsKeyID, "sse-kms-key-id", "", "KMS key used with --sse aws:kms (default: the account's S3 key)")
+	}
+	cmd.Flags().StringVar(&flags.keyFile, "sse-c-key-file", "", "file holding the SSE-C customer key (32 raw bytes, or base64)")
+	cmd.Flags().StringVar(&flags.keyEnv, "sse-c-key-env", "", "environment variable holding the SSE-C customer key (base64)")
+}
+
+// options valide les options et charge la clé SSE-C éventuelle
+func (f sseFlags) options() (sseOptions, error) {
+	opts := sseOptions{kmsKeyID: f.kmsKeyID}
+	switch strings.ToLower(f.algorithm) {
+	case "":
+	case "aes256", "s3", "sse-s3":
+		opts.algorithm = sseS3
+	case "aws:kms", "kms", "sse-kms":
+		opts.algorithm = sseKMS
+	default:
+		return opts, fmt.Errorf("unsupported --sse value '%s' (expected AES256 or aws:kms)", f.algorithm)
+	}
+	if opts.kmsKeyID != "" && opts.algorithm != sseKMS {
+		return opts, fmt.Errorf("--sse-kms-key-id requires --sse aws:kms")
+	}
+
+	key, err := loadCustomerKey(f.keyFile, f.keyEnv)
+	if err != nil {
+		return opts, err
+	}
+	if key != nil && opts.algorithm != "" {
+		return opts, fmt.Errorf("--sse cannot be combined with an SSE-C customer key")
+	}
+	opts.customerKey = key
+	return opts, nil
+}
+
+// loadCustomerKey lit la clé SSE-C depuis un fichier (32 octets bruts ou base64) ou une variable
+// d'environnement (base64) ; nil si aucune source n'est donnée
+func loadCustomerKey(file, env string) ([]byte, error) {
+	switch {
+	case file != "" && env != "":
+		return nil, fmt.Errorf("use either --sse-c-key-file or --sse-c-key-env, not both")
+	case file != "":
+		return readKeyFile(file)
+	case env != "":
+		value, ok := os.LookupEnv(env)
+		if !ok || value == "" {
+			return nil, fmt.Errorf("environment variable %s is not set", env)
+		}
+		return decodeKey([]byte(value), fmt.Sprintf("environment variable %s", env))
+	default:
+		return nil, nil
+	}
+}
+
+// readKeyFile lit une clé AES-256 stockée dans un fichier, brute ou encodée en base64
+func readKeyFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key: %w", err)
+	}
+	if len(data) == sseCustomerKeyLen {
+		return data, nil
+	}
+	return decodeKey(data, fmt.Sprintf("file '%s'", path))
+}
+
+func decodeKey(data []byte, source string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(key) != sseCustomerKeyLen {
+		return nil, fmt.Errorf("the key in %s must be %d bytes (raw, or base64 encoded)", source, sseCustomerKeyLen)
+	}
+	return key, nil
+}
+
+// customerKeyHeaders retourne l'algorithme, la clé et le MD5 de la clé encodés pour les en-têtes SSE-C
+func customerKeyHeaders(key []byte) (algorithm, encoded, keyMD5 string) {
+	sum := md5.Sum(key)
+	return sseS3, base64.StdEncoding.EncodeToString(key), base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// apply ajoute les en-têtes de chiffrement d'une écriture, ou la clé SSE-C d'une lecture
+func (o sseOptions) apply(header http.Header) {
+	if o.algorithm != "" {
+		header.Set("X-Amz-Server-Side-Encryption", o.algorithm)
+		if o.kmsKeyID != "" {
+			header.Set("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id", o.kmsKeyID)
+		}
+	}
+	if o.customerKey != nil {
+		algorithm, key, keyMD5 := customerKeyHeaders(o.customerKey)
+		header.Set("X-Amz-Server-Side-Encryption-Customer-Algorithm", algorithm)
+		header.Set("X-Amz-Server-Side-Encryption-Customer-Key", key)
+		header.Set("X-Amz-Server-Side-Encryption-Customer-Key-Md5", keyMD5)
+	}
+}
+
+// applyCopySource ajoute la clé SSE-C de l'objet source d'une copie
+func (o sseOptions) applyCopySource(header http.Header) {
+	if o.customerKey != nil {
+		algorithm, key, keyMD5 := customerKeyHeaders(o.customerKey)
+		header.Set("X-Amz-Copy-Source-Server-Side-Encryption-Customer-Algorithm", algorithm)
+		header.Set("X-Amz-Copy-Source-Server-Side-Encryption-Customer-Key", key)
+		header.Set("X-Amz-Copy-Source-Server-Side-Encryption-Customer-Key-Md5", keyMD5)
+	}
+}
+
+// warnPlainHTTP signale qu'une clé SSE-C partirait en clair (S3 refuse d'ailleurs SSE-C hors HTTPS)
+func (o sseOptions) warnPlainHTTP(client *s3Client) {
+	if o.customerKey != nil && strings.HasPrefix(strings.ToLower(client.profile.APIURL), "http://") {
+		log.Printf("Warning: the SSE-C key is sent over plain HTTP to %s", client.profile.APIURL)
+	}
+}
+
+// describeEncryption résume le chiffrement indiqué par les en-têtes d'une réponse ("" si aucun)
+func describeEncryption(header http.Header) string {
+	if algorithm := header.Get("X-Amz-Server-Side-Encryption-Customer-Algorithm"); algorithm != "" {
+		return fmt.Sprintf("SSE-C (%s, key MD5 %s)", algorithm, header.Get("X-Amz-Server-Side-Encryption-Customer-Key-Md5"))
+	}
+	switch algorithm := header.Get("X-Amz-Server-Side-Encryption"); algorithm {
+	case "":
+		return ""
+	case sseS3:
+		return "SSE-S3 (AES256)"
+	case sseKMS:
+		if keyID := header.Get("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id"); keyID != "" {
+			return fmt.Sprintf("SSE-KMS (key %s)", keyID)
+		}
+		return "SSE-KMS"
+	default:
+		return algorithm
+	}
+}
+
+// sseErrorHint explique un refus de lecture lié à SSE-C : clé absente (400) ou différente (403).
+// Elle retourne nil hors de ces statuts, et l'erreur S3 telle quelle quand rien n'indique SSE-C.
+func sseErrorHint(resp *http.Response, opts sseOptions, key string) error {
+	if resp.StatusCode != http.StatusBadRequest && resp.StatusCode != http.StatusForbidden {
+		return nil
+	}
+	err := responseError(resp)
+	s3Err, _ := err.(*S3Error)
+	switch {
+	case resp.StatusCode == http.StatusBadRequest && opts.customerKey == nil && customerKeyRequired(resp, s3Err):
+		return fmt.Errorf("object '%s' is encrypted with a customer key (SSE-C): pass --sse-c-key-file or --sse-c-key-env", key)
+	case resp.StatusCode == http.StatusForbidden && opts.customerKey != nil:
+		// S3 répond AccessDenied sans préciser la cause : l'erreur est gardée, la piste SSE-C ajoutée
+		return fmt.Errorf("access to '%s' denied (%w): the SSE-C key may not be the one used to encrypt the object", key, err)
+	}
+	return err
+}
+
+// customerKeyRequired indique si un refus 400 réclame la clé SSE-C de l'objet. Une réponse à HEAD
+// n'a pas de corps : S3 y signale une clé manquante par un 400 nu.
+func customerKeyRequired(resp *http.Response, s3Err *S3Error) bool {
+	if s3Err.Code == "" {
+		return resp.Request != nil && resp.Request.Method == http.MethodHead
+	}
+	return s3Err.Code == "InvalidRequest" && strings.Contains(strings.ToLower(s3Err.Message), "server side encryption")
+}
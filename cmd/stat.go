@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	statVersionID string
+	statSSE       sseFlags
+)
+
+// objectInfo regroupe les métadonnées d'un objet retournées par HEAD
+type objectInfo struct {
+	Key          string
+	Size         int64
+	LastModified string
+	ETag         string
+	ContentType  string
+	VersionID    string
+	StorageClass string
+	Encryption   string            // chiffrement côté serveur, voir describeEncryption
+	Metadata     map[string]string // métadonnées utilisateur (x-amz-meta-*)
+	Header       http.Header
+}
+
+// StatCmd représente la commande stat
+var StatCmd = &cobra.Command{
+	Use:   "stat <bucket-name> <object-key> | stat s3://bucket/key",
+	Short: "Shows the metadata of an object, or of one of its versions",
+	Run: func(cmd *cobra.Command, args []string) {
+		bucketName, key, _, err := objectArgs(args)
+		if err != nil {
+			log.Printf("Usage: stat <bucket-name> <object-key> or stat s3://bucket/key (%v)", err)
+			return
+		}
+
+		client, err := newConfiguredClient()
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+
+		sse, err := statSSE.options()
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		sse.warnPlainHTTP(client)
+
+		info, err := statObject(cmd.Context(), client, bucketName, key, statVersionID, sse)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+
+		fmt.Printf("Key:           %s\n", info.Key)
+		fmt.Printf("Size:          %d\n", info.Size)
+		fmt.Printf("Last-Modified: %s\n", info.LastModified)
+		fmt.Printf("ETag:          %s\n", info.ETag)
+		fmt.Printf("Content-Type:  %s\n", info.ContentType)
+		if info.VersionID != "" {
+			fmt.Printf("Version-Id:    %s\n", info.VersionID)
+		}
+		if info.StorageClass != "" {
+			fmt.Printf("Storage-Class: %s\n", info.StorageClass)
+		}
+		if info.Encryption != "" {
+			fmt.Printf("Encryption:    %s\n", info.Encryption)
+		}
+		if len(info.Metadata) > 0 {
+			names := make([]string, 0, len(info.Metadata))
+			for name := range info.Metadata {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			fmt.Println("Metadata:")
+			for _, name := range names {
+				fmt.Printf("  %s: %s\n", name, info.Metadata[name])
+			}
+		}
+	},
+}
+
+// statObject lit les métadonnées de l'objet (ou de la version versionID) par une requête HEAD ;
+// la clé SSE-C de sse est exigée pour un objet chiffré avec une clé client
+func statObject(ctx context.Context, client *s3Client, bucketName, key, versionID string, sse sseOptions) (*objectInfo, error) {
+	req, err := client.newRequest(ctx, "HEAD", bucketName, key, versionQuery(versionID), nil)
+	if err != nil {
+		return nil, err
+	}
+	sse.apply(req.Header)
+	resp, err := client.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if hint := sseErrorHint(resp, sse, key); hint != nil {
+		return nil, hint
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound && versionID != "":
+		return nil, fmt.Errorf("version '%s' of object '%s' not found in bucket '%s'", versionID, key, bucketName)
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("object '%s' not found in bucket '%s'", key, bucketName)
+	case resp.StatusCode == http.StatusMethodNotAllowed && resp.Header.Get("X-Amz-Delete-Marker") == "true":
+		return nil, fmt.Errorf("version '%s' of object '%s' is a delete marker", versionID, key)
+	case resp.StatusCode >= 300:
+		return nil, responseError(resp)
+	}
+
+	info := &objectInfo{
+		Key:          key,
+		Size:         resp.ContentLength,
+		LastModified: resp.Header.Get("Last-Modified"),
+		ETag:         resp.Header.Get("ETag"),
+		ContentType:  resp.Header.Get("Content-Type"),
+		VersionID:    resp.Header.Get("X-Amz-Version-Id"),
+		StorageClass: resp.Header.Get("X-Amz-Storage-Class"),
+		Encryption:   describeEncryption(resp.Header),
+		Metadata:     map[string]string{},
+		Header:       resp.Header,
+	}
+	for name, values := range resp.Header {
+		if meta, ok := strings.CutPrefix(strings.ToLower(name), "x-amz-meta-"); ok {
+			info.Metadata[meta] = strings.Join(values, ", ")
+		}
+	}
+	return info, nil
+}
+
+func init() {
+	StatCmd.Flags().StringVar(&statVersionID, "version-id", "", "show a specific version of the object")
+	addSSEFlags(StatCmd, &statSSE, false)
+	RootCmd.AddCommand(StatCmd)
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultSTSEndpoint     = "https://sts.amazonaws.com"
+	defaultRoleSessionName = "bs3-session"
+	defaultRoleDuration    = 3600
+)
+
+// AssumeRoleResponse représente la réponse XML de l'action STS AssumeRole
+type AssumeRoleResponse struct {
+	XMLName     xml.Name `xml:"AssumeRoleResponse"`
+	Credentials struct {
+		AccessKeyID     string `xml:"AccessKeyId"`
+		SecretAccessKey string `xml:"SecretAccessKey"`
+		SessionToken    string `xml:"SessionToken"`
+		Expiration      string `xml:"Expiration"`
+	} `xml:"AssumeRoleResult>Credentials"`
+}
+
+// STSErrorResponse représente une erreur renvoyée par le service STS
+type STSErrorResponse struct {
+	Code    string `xml:"Error>Code"`
+	Message string `xml:"Error>Message"`
+}
+
+func (p *Profile) sessionName() string {
+	if p.RoleSessionName != "" {
+		return p.RoleSessionName
+	}
+	return defaultRoleSessionName
+}
+
+// assumeRole appelle STS AssumeRole, signé avec les identifiants du profil source
+func assumeRole(ctx context.Context, p *Profile, source *Credentials) (*Credentials, error) {
+	endpoint := p.STSEndpoint
+	if endpoint == "" {
+		endpoint = defaultSTSEndpoint
+	}
+	duration := p.DurationSeconds
+	if duration == 0 {
+		duration = defaultRoleDuration
+	}
+
+	form := url.Values{
+		"Action":          {"AssumeRole"},
+		"Version":         {"2011-06-15"},
+		"RoleArn":         {p.RoleARN},
+		"RoleSessionName": {p.sessionName()},
+		"DurationSeconds": {strconv.Itoa(duration)},
+	}
+	if p.ExternalID != "" {
+		form.Set("ExternalId", p.ExternalID)
+	}
+	payload := form.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AssumeRole request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	newSigner(source, p.Region, "sts").sign(req, hashSHA256([]byte(payload)), time.Now())
+
+	transport, err := sharedTransport(p)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Transport: transport, Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("AssumeRole request to %s failed: %w", endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read AssumeRole response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var stsErr STSErrorResponse
+		if xml.Unmarshal(body, &stsErr) == nil && stsErr.Code != "" {
+			return nil, fmt.Errorf("AssumeRole on '%s' failed: %s: %s", p.RoleARN, stsErr.Code, stsErr.Message)
+		}
+		return nil, fmt.Errorf("AssumeRole on '%s' failed: status code %d", p.RoleARN, resp.StatusCode)
+	}
+
+	var result AssumeRoleResponse
+	if err := xml.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse AssumeRole response: %w", err)
+	}
+	expiration, err := time.Parse(time.RFC3339, result.Credentials.Expiration)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expiration in AssumeRole response: %w", err)
+	}
+	if result.Credentials.AccessKeyID == "" || result.Credentials.SecretAccessKey == "" {
+		return nil, fmt.Errorf("AssumeRole response for '%s' contains no credentials", p.RoleARN)
+	}
+
+	return &Credentials{
+		AccessKeyID:     result.Credentials.AccessKeyID,
+		SecretAccessKey: result.Credentials.SecretAccessKey,
+		SessionToken:    result.Credentials.SessionToken,
+		Expiration:      expiration,
+	}, nil
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// s3XMLNamespace est l'espace de noms des documents XML de configuration S3
+const s3XMLNamespace = "http://s3.amazonaws.com/doc/2006-03-01/"
+
+// getSubresource lit une sous-ressource d'un bucket ou d'un objet (ex. ?versioning, ?tagging).
+// Une réponse en échec est retournée sous forme de *S3Error.
+func (c *s3Client) getSubresource(ctx context.Context, bucket, key, name string, query url.Values) ([]byte, error) {
+	return c.subresource(ctx, "GET", bucket, key, name, query, nil, nil)
+}
+
+// putSubresource remplace une sous-ressource ; le corps est accompagné de son Content-MD5,
+// exigé par S3 pour plusieurs configurations (CORS, cycle de vie, tags...)
+func (c *s3Client) putSubresource(ctx context.Context, bucket, key, name string, query url.Values, body []byte, header http.Header) error {
+	_, err := c.subresource(ctx, "PUT", bucket, key, name, query, body, header)
+	return err
+}
+
+// deleteSubresource supprime une sous-ressource
+func (c *s3Client) deleteSubresource(ctx context.Context, bucket, key, name string, query url.Values) error {
+	_, err := c.subresource(ctx, "DELETE", bucket, key, name, query, nil, nil)
+	return err
+}
+
+func (c *s3Client) subresource(ctx context.Context, method, bucket, key, name string, query url.Values, body []byte, header http.Header) ([]byte, error) {
+	values := url.Values{name: {""}}
+	for k, v := range query {
+		values[k] = v
+	}
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := c.newRequest(ctx, method, bucket, key, values, reader)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if body != nil {
+		sum := md5.Sum(body)
+		req.Header.Set("Content-MD5", base64.StdEncoding.EncodeToString(sum[:]))
+		if req.Header.Get("Content-Type") == "" {
+			req.Header.Set("Content-Type", "application/xml")
+		}
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return nil, responseError(resp)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,251 @@
+package cmd
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"log"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Limites de S3 sur les tags
+const (
+	maxObjectTags  = 10
+	maxBucketTags  = 50
+	maxTagKeyLen   = 128
+	maxTagValueLen = 256
+)
+
+// Tag représente un tag (clé/valeur) d'un objet ou d'un bucket
+type Tag struct {
+	Key   string `xml:"Key"`
+	Value string `xml:"Value"`
+}
+
+// Tagging représente le document XML des sous-ressources ?tagging
+type Tagging struct {
+	XMLName xml.Name `xml:"Tagging"`
+	Xmlns   string   `xml:"xmlns,attr,omitempty"`
+	TagSet  []Tag    `xml:"TagSet>Tag"`
+}
+
+var tagMerge bool
+
+// TagCmd regroupe les sous-commandes de gestion des tags
+var TagCmd = &cobra.Command{
+	Use:   "tag",
+	Short: "Reads, sets or deletes the tags of an object or a bucket",
+	Long: `Reads, sets or deletes tags. The target is an object (<bucket-name> <object-key> or
+s3://bucket/key) or a whole bucket (<bucket-name> or s3://bucket).
+For example:
+
+bs3 tag set s3://reports/2024/q1.pdf team=finance retention=7y
+bs3 tag get reports`,
+}
+
+var tagGetCmd = &cobra.Command{
+	Use:   "get <target>",
+	Short: "Shows the tags of an object or a bucket",
+	Run: func(cmd *cobra.Command, args []string) {
+		bucketName, key, _, err := tagTarget(args)
+		if err != nil {
+			log.Printf("Usage: tag get <bucket-name> [object-key] (%v)", err)
+			return
+		}
+		client, err := newConfiguredClient()
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+
+		tags, err := getTags(cmd.Context(), client, bucketName, key)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		if len(tags) == 0 {
+			fmt.Printf("No tags on %s.\n", tagTargetName(bucketName, key))
+			return
+		}
+		for _, name := range sortedTagKeys(tags) {
+			fmt.Printf("%s=%s\n", name, tags[name])
+		}
+	},
+}
+
+var tagSetCmd = &cobra.Command{
+	Use:   "set <target> <key=value>...",
+	Short: "Replaces the tags of an object or a bucket (--merge keeps the other tags)",
+	Run: func(cmd *cobra.Command, args []string) {
+		bucketName, key, rest, err := tagTarget(args)
+		if err == nil && len(rest) == 0 {
+			err = fmt.Errorf("at least one key=value tag is required")
+		}
+		if err != nil {
+			log.Printf("Usage: tag set <bucket-name> [object-key] <key=value>... (%v)", err)
+			return
+		}
+		tags, err := parseTags(rest)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		client, err := newConfiguredClient()
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+
+		if tagMerge {
+			existing, err := getTags(cmd.Context(), client, bucketName, key)
+			if err != nil {
+				log.Printf("Error: %v", err)
+				return
+			}
+			for name, value := range tags {
+				existing[name] = value
+			}
+			tags = existing
+		}
+		limit := maxObjectTags
+		if key == "" {
+			limit = maxBucketTags
+		}
+		if len(tags) > limit {
+			log.Printf("Error: %s can have at most %d tags (got %d)", tagTargetName(bucketName, key), limit, len(tags))
+			return
+		}
+
+		if err := putTags(cmd.Context(), client, bucketName, key, tags); err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		fmt.Printf("Tags updated on %s (%d tag(s)).\n", tagTargetName(bucketName, key), len(tags))
+	},
+}
+
+var tagDeleteCmd = &cobra.Command{
+	Use:   "delete <target>",
+	Short: "Removes all the tags of an object or a bucket",
+	Run: func(cmd *cobra.Command, args []string) {
+		bucketName, key, _, err := tagTarget(args)
+		if err != nil {
+			log.Printf("Usage: tag delete <bucket-name> [object-key] (%v)", err)
+			return
+		}
+		client, err := newConfiguredClient()
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+
+		if err := client.deleteSubresource(cmd.Context(), bucketName, key, "tagging", nil); err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		fmt.Printf("Tags deleted from %s.\n", tagTargetName(bucketName, key))
+	},
+}
+
+// tagTarget lit la cible d'une commande tag : s3://bucket[/clé] ou <bucket> [clé].
+// Sans URI, le second argument est une clé d'objet s'il ne contient pas de "=".
+func tagTarget(args []string) (bucket, key string, rest []string, err error) {
+	if len(args) == 0 {
+		return "", "", nil, fmt.Errorf("a bucket or an object is required")
+	}
+	if strings.HasPrefix(args[0], "s3://") {
+		bucket, key, err = parseS3URI(args[0])
+		return bucket, key, args[1:], err
+	}
+	if len(args) > 1 && !strings.Contains(args[1], "=") {
+		return args[0], args[1], args[2:], nil
+	}
+	return args[0], "", args[1:], nil
+}
+
+func tagTargetName(bucket, key string) string {
+	if key == "" {
+		return fmt.Sprintf("bucket '%s'", bucket)
+	}
+	return fmt.Sprintf("object '%s' in bucket '%s'", key, bucket)
+}
+
+// parseTags lit des tags de la forme clé=valeur (la valeur peut être vide)
+func parseTags(values []string) (map[string]string, error) {
+	tags := map[string]string{}
+	for _, value := range values {
+		name, tagValue, found := strings.Cut(value, "=")
+		switch {
+		case !found || name == "":
+			return nil, fmt.Errorf("invalid tag '%s' (expected key=value)", value)
+		case len(name) > maxTagKeyLen:
+			return nil, fmt.Errorf("tag key '%s' is longer than %d characters", name, maxTagKeyLen)
+		case len(tagValue) > maxTagValueLen:
+			return nil, fmt.Errorf("value of tag '%s' is longer than %d characters", name, maxTagValueLen)
+		}
+		tags[name] = tagValue
+	}
+	return tags, nil
+}
+
+// taggingHeader encode les tags pour l'en-tête X-Amz-Tagging d'un upload
+func taggingHeader(tags map[string]string) string {
+	values := url.Values{}
+	for name, value := range tags {
+		values.Set(name, value)
+	}
+	return values.Encode()
+}
+
+func sortedTagKeys(tags map[string]string) []string {
+	names := make([]string, 0, len(tags))
+	for name := range tags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// getTags lit les tags d'un objet, ou du bucket si key est vide. Un bucket sans tags
+// répond NoSuchTagSet, traité comme une liste vide.
+func getTags(ctx context.Context, client *s3Client, bucket, key string) (map[string]string, error) {
+	data, err := client.getSubresource(ctx, bucket, key, "tagging", nil)
+	if s3Err, ok := err.(*S3Error); ok && s3Err.Code == "NoSuchTagSet" {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var tagging Tagging
+	if err := xml.Unmarshal(data, &tagging); err != nil {
+		return nil, fmt.Errorf("failed to parse tags: %w", err)
+	}
+	tags := map[string]string{}
+	for _, tag := range tagging.TagSet {
+		tags[tag.Key] = tag.Value
+	}
+	return tags, nil
+}
+
+// putTags remplace les tags d'un objet, ou du bucket si key est vide
+func putTags(ctx context.Context, client *s3Client, bucket, key string, tags map[string]string) error {
+	tagging := Tagging{Xmlns: s3XMLNamespace, TagSet: []Tag{}}
+	for _, name := range sortedTagKeys(tags) {
+		tagging.TagSet = append(tagging.TagSet, Tag{Key: name, Value: tags[name]})
+	}
+	body, err := xml.Marshal(tagging)
+	if err != nil {
+		return fmt.Errorf("failed to marshal tags: %w", err)
+	}
+	return client.putSubresource(ctx, bucket, key, "tagging", nil, body, nil)
+}
+
+func init() {
+	tagSetCmd.Flags().BoolVar(&tagMerge, "merge", false, "keep the existing tags and only add or update the given ones")
+	TagCmd.AddCommand(tagGetCmd, tagSetCmd, tagDeleteCmd)
+	RootCmd.AddCommand(TagCmd)
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// tagFetchConcurrency est le nombre de lectures de tags menées en parallèle par --tag-filter
+const tagFetchConcurrency = 8
+
+// tagFilter sélectionne les objets portant un tag : "clé=valeur" exige la valeur,
+// "clé" seule la simple présence du tag
+type tagFilter struct {
+	key, value string
+	anyValue   bool
+}
+
+// parseTagFilters lit les valeurs de --tag-filter ; tous les filtres doivent correspondre
+func parseTagFilters(values []string) ([]tagFilter, error) {
+	var filters []tagFilter
+	for _, value := range values {
+		name, tagValue, found := strings.Cut(value, "=")
+		if name == "" {
+			return nil, fmt.Errorf("invalid tag filter '%s' (expected key=value or key)", value)
+		}
+		filters = append(filters, tagFilter{key: name, value: tagValue, anyValue: !found})
+	}
+	return filters, nil
+}
+
+func matchTags(tags map[string]string, filters []tagFilter) bool {
+	for _, filter := range filters {
+		value, ok := tags[filter.key]
+		if !ok || (!filter.anyValue && value != filter.value) {
+			return false
+		}
+	}
+	return true
+}
+
+// filterByTags lit en parallèle les tags des objets et retourne les clés (dans l'ordre reçu)
+// dont les tags correspondent aux filtres
+func filterByTags(ctx context.Context, client *s3Client, bucket string, keys []string, filters []tagFilter) ([]string, error) {
+	tags, err := fetchTags(ctx, client, bucket, keys)
+	if err != nil {
+		return nil, err
+	}
+	var selected []string
+	for _, key := range keys {
+		if matchTags(tags[key], filters) {
+			selected = append(selected, key)
+		}
+	}
+	return selected, nil
+}
+
+// fetchTags lit les tags des objets avec tagFetchConcurrency requêtes en parallèle.
+// La première erreur interrompt les lectures restantes.
+func fetchTags(ctx context.Context, client *s3Client, bucket string, keys []string) (map[string]map[string]string, error) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	results := make([]map[string]string, len(keys))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(tagFetchConcurrency, len(keys)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				tags, err := getTags(ctx, client, bucket, keys[i])
+				if err != nil {
+					cancel(fmt.Errorf("failed to read tags of '%s': %w", keys[i], err))
+					continue
+				}
+				results[i] = tags
+			}
+		}()
+	}
+
+feed:
+	for i := range keys {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	if err := context.Cause(ctx); err != nil {
+		return nil, err
+	}
+	tags := make(map[string]map[string]string, len(keys))
+	for i, key := range keys {
+		tags[key] = results[i]
+	}
+	return tags, nil
+}
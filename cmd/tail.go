@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	tailLines int
+	tailBytes int64
+)
+
+// TailCmd représente la commande tail
+var TailCmd = &cobra.Command{
+	Use:   "tail <bucket-name> <object-key> | tail s3://bucket/key",
+	Short: "Prints the last lines or bytes of an object using Range requests",
+	Long: `Prints the end of an object without downloading it. In line mode, the range read from the
+end of the object is extended towards its beginning until enough lines are found.
+For example:
+
+bs3 tail -n 100 s3://bucket/logs/app.log`,
+	Run: func(cmd *cobra.Command, args []string) {
+		bucketName, key, _, err := objectArgs(args)
+		if err != nil {
+			log.Printf("Usage: tail [-n lines | -c bytes] <bucket-name> <object-key> (%v)", err)
+			return
+		}
+		if tailLines < 0 || tailBytes < 0 {
+			log.Println("Error: -n and -c must not be negative")
+			return
+		}
+
+		// Récupérer l'URL de l'API depuis le profil actif
+		client, err := newConfiguredClient()
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+
+		var data []byte
+		if cmd.Flags().Changed("bytes") {
+			if tailBytes > 0 {
+				var res *rangeResult
+				res, err = readRange(cmd.Context(), client, bucketName, key, fmt.Sprintf("bytes=-%d", tailBytes))
+				if res != nil {
+					data = res.data[int64(len(res.data))-min(int64(len(res.data)), tailBytes):]
+				}
+			}
+		} else {
+			data, err = readTail(cmd.Context(), client, bucketName, key, tailLines)
+		}
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		os.Stdout.Write(data)
+	},
+}
+
+func init() {
+	TailCmd.Flags().IntVarP(&tailLines, "lines", "n", 10, "number of lines to print")
+	TailCmd.Flags().Int64VarP(&tailBytes, "bytes", "c", 0, "number of bytes to print (instead of lines)")
+	RootCmd.AddCommand(TailCmd)
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMaxConnections = 16
+	defaultConnectTimeout = 10 * time.Second
+)
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// Les transports sont partagés entre les clients ayant la même configuration TLS,
+// ce qui permet de réutiliser les connexions (S3 comme STS)
+var (
+	transportsMu sync.Mutex
+	transports   = map[string]*http.Transport{}
+)
+
+// sharedTransport retourne le transport HTTP (pool de connexions et TLS) correspondant au profil
+func sharedTransport(p *Profile) (*http.Transport, error) {
+	if p.InsecureSkipVerify {
+		log.Printf("WARNING: TLS certificate verification is DISABLED for profile '%s' (insecure_skip_verify). "+
+			"Connections can be intercepted; use ca_bundle instead.", p.Name)
+	}
+
+	key := fmt.Sprintf("%q|%q|%q|%q|%t|%q|%d|%s", p.CABundle, p.ClientCert, p.ClientKey, p.TLSMinVersion,
+		p.InsecureSkipVerify, p.SPKIPins, p.MaxConnections, p.ConnectTimeout)
+
+	transportsMu.Lock()
+	defer transportsMu.Unlock()
+	if transport, ok := transports[key]; ok {
+		return transport, nil
+	}
+
+	tlsConfig, err := newTLSConfig(p)
+	if err != nil {
+		return nil, err
+	}
+	dialer := &net.Dialer{Timeout: p.ConnectTimeout, KeepAlive: 30 * time.Second}
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		TLSClientConfig:       tlsConfig,
+		TLSHandshakeTimeout:   p.ConnectTimeout,
+		MaxIdleConns:          p.MaxConnections * 4,
+		MaxIdleConnsPerHost:   p.MaxConnections,
+		MaxConnsPerHost:       p.MaxConnections,
+		IdleConnTimeout:       90 * time.Second,
+		ExpectContinueTimeout: time.Second,
+		ForceAttemptHTTP2:     true,
+		// Les objets compressés (Content-Encoding) sont décodés explicitement par download-file
+		DisableCompression: true,
+	}
+	transports[key] = transport
+	return transport, nil
+}
+
+// newTLSConfig construit la configuration TLS du profil : CA, certificat client, version minimale et pinning
+func newTLSConfig(p *Profile) (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if p.TLSMinVersion != "" {
+		version, ok := tlsVersions[p.TLSMinVersion]
+		if !ok {
+			return nil, fmt.Errorf("profile '%s' has an invalid tls_min_version '%s' (expected 1.0, 1.1, 1.2 or 1.3)", p.Name, p.TLSMinVersion)
+		}
+		config.MinVersion = version
+	}
+
+	// Le bundle remplace les autorités du système
+	if p.CABundle != "" {
+		pem, err := os.ReadFile(p.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ca_bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("ca_bundle '%s' contains no PEM certificate", p.CABundle)
+		}
+		config.RootCAs = pool
+	}
+
+	if p.ClientCert != "" || p.ClientKey != "" {
+		if p.ClientCert == "" || p.ClientKey == "" {
+			return nil, fmt.Errorf("profile '%s' must set both client_cert and client_key", p.Name)
+		}
+		cert, err := tls.LoadX509KeyPair(p.ClientCert, p.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	config.InsecureSkipVerify = p.InsecureSkipVerify
+
+	if len(p.SPKIPins) > 0 {
+		pins := make(map[string]bool, len(p.SPKIPins))
+		for _, pin := range p.SPKIPins {
+			pins[strings.TrimPrefix(pin, "sha256/")] = true
+		}
+		// VerifyConnection s'exécute même avec InsecureSkipVerify : le pinning reste appliqué
+		config.VerifyConnection = func(state tls.ConnectionState) error {
+			for _, cert := range state.PeerCertificates {
+				if pins[spkiFingerprint(cert)] {
+					return nil
+				}
+			}
+			return errors.New("no certificate in the server chain matches the configured spki_pins")
+		}
+	}
+
+	return config, nil
+}
+
+// spkiFingerprint retourne le SHA-256 (base64) de la clé publique du certificat
+func spkiFingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
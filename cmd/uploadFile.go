@@ -1,16 +1,25 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"os"
 	"path/filepath"
-	"strings"
-	"time"
+	"strconv"
+
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
+)
+
+var (
+	uploadTags []string
+	uploadSSE  sseFlags
+
+	uploadEncrypt       bool
+	uploadMasterKeyFile string
+	uploadCompress      string
 )
 
 // uploadFileCmd représente la commande upload-file
@@ -25,10 +34,42 @@ var UploadFileCmd = &cobra.Command{
 		bucketName := args[0]
 		filePath := args[1]
 
-		// Récupérer l'URL de l'API via Viper
-		apiURL := viper.GetString("s3.api_url")
-		if apiURL == "" {
-			log.Fatal("API URL is not configured. Please set it in the config file or environment variables.")
+		// Tags éventuels (--tag clé=valeur), envoyés dans l'en-tête X-Amz-Tagging
+		tags, err := parseTags(uploadTags)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		if len(tags) > maxObjectTags {
+			log.Fatalf("Error: an object can have at most %d tags (got %d)", maxObjectTags, len(tags))
+		}
+
+		// Compression éventuelle (--compress gzip|zstd)
+		var compression string
+		if uploadCompress != "" {
+			if compression, err = parseCompression(uploadCompress); err != nil {
+				log.Fatalf("Error: %v", err)
+			}
+		}
+
+		// Chiffrement côté serveur éventuel (--sse, --sse-kms-key-id, clé SSE-C)
+		sse, err := uploadSSE.options()
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+
+		// Récupérer l'URL de l'API depuis le profil actif
+		client, err := newConfiguredClient()
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		sse.warnPlainHTTP(client)
+
+		// Chiffrement côté client éventuel (--encrypt) : la clé maîtresse est lue avant tout envoi
+		var masterKey []byte
+		if uploadEncrypt {
+			if masterKey, err = loadMasterKey(uploadMasterKeyFile, client.profile); err != nil {
+				log.Fatalf("Error: %v", err)
+			}
 		}
 
 		// Lire le fichier à uploader
@@ -41,9 +82,6 @@ var UploadFileCmd = &cobra.Command{
 		// Extraire le nom du fichier depuis le chemin
 		fileName := filepath.Base(filePath)
 
-		// URL de l'API pour l'upload du fichier
-		url := fmt.Sprintf("%s/%s/%s", apiURL, bucketName, fileName)
-
 		// Obtenir la taille du fichier pour la barre de progression
 		fileInfo, err := file.Stat()
 		if err != nil {
@@ -51,79 +89,109 @@ var UploadFileCmd = &cobra.Command{
 		}
 		totalSize := fileInfo.Size()
 
-		// Créer le client HTTP
-		client := &http.Client{}
-		pipeReader, pipeWriter := io.Pipe()
+		// En-têtes de l'objet : type, tags, chiffrement côté serveur
+		header := http.Header{"Content-Type": {"application/octet-stream"}}
+		if len(tags) > 0 {
+			header.Set("X-Amz-Tagging", taggingHeader(tags))
+		}
+		sse.apply(header)
+
+		// Avec --compress, le contenu est compressé au fil de l'envoi
+		if compression != "" {
+			sent, err := uploadCompressed(cmd.Context(), client, bucketName, fileName, file, header, compression, totalSize, masterKey)
+			if err != nil {
+				if cmd.Context().Err() != nil {
+					fmt.Printf("Upload of '%s' interrupted; the object was not stored.\n", fileName)
+					return
+				}
+				log.Fatalf("Error uploading file: %v", err)
+			}
+			fmt.Printf("File '%s' uploaded successfully to bucket '%s'.\n", fileName, bucketName)
+			fmt.Printf("Compressed with %s: %s -> %s stored (%.1f%%)\n", compression, formatBytes(totalSize), formatBytes(sent), 100*float64(sent)/float64(max(totalSize, 1)))
+			if uploadEncrypt {
+				fmt.Printf("Client-side encryption: %s (master key id %s)\n", envelopeAlgorithm, masterKeyID(masterKey))
+			}
+			return
+		}
+
+		// Suivre la progression : le corps de la requête comptabilise les octets lus par le transport
+		display, err := newProgress()
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		bar := display.track(fileName, totalSize)
+
+		// Avec --encrypt, le contenu est chiffré à la volée ; la progression compte les octets en clair
+		body := bar.reader(file)
+		contentLength := totalSize
+		if uploadEncrypt {
+			env, err := sealEnvelope(masterKey, totalSize, header)
+			if err != nil {
+				log.Fatalf("Error preparing client-side encryption: %v", err)
+			}
+			body = env.encrypt(body)
+			contentLength = env.encryptedSize(totalSize)
+		}
 
 		// Préparer la requête HTTP
-		req, err := http.NewRequest("PUT", url, pipeReader)
+		req, err := client.newRequest(withTransfer(cmd.Context(), bar), "PUT", bucketName, fileName, nil, body)
 		if err != nil {
 			log.Fatalf("Error creating request: %v", err)
 		}
-		req.Header.Set("Content-Type", "application/octet-stream")
-		req.ContentLength = totalSize
-		req.Header.Set("X-Amz-Decoded-Content-Length", fmt.Sprintf("%d", totalSize))
-
-		// Lancer l'upload et mettre à jour la barre de progression
-		progressChan := make(chan struct{})
-		var uploadedSize int64 = 0
-
-		// Fonction pour démarrer la barre de progression
-		go func() {
-			for {
-				select {
-				case <-progressChan:
-					return
-				default:
-					printProgressUpload(uploadedSize, totalSize)
-					time.Sleep(100 * time.Millisecond) // Rafraîchir toutes les 100ms
-				}
-			}
-		}()
-
-		// Lire et écrire le fichier en streaming
-		go func() {
-			defer pipeWriter.Close() // Fermer l'écriture après avoir fini l'upload
-			buffer := make([]byte, 256)
-			for {
-				n, err := file.Read(buffer)
-				if err != nil && err != io.EOF {
-					progressChan <- struct{}{} // Arrêter l'animation
-					pipeWriter.CloseWithError(fmt.Errorf("failed to read file: %w", err))
-					return
-				}
-				if n == 0 {
-					break
-				}
-				_, err = pipeWriter.Write(buffer[:n])
-				if err != nil {
-					progressChan <- struct{}{} // Arrêter l'animation
-					pipeWriter.CloseWithError(fmt.Errorf("failed to write to pipe: %w", err))
-					return
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		req.ContentLength = contentLength
+		req.Header.Set("X-Amz-Decoded-Content-Length", fmt.Sprintf("%d", contentLength))
+
+		// Une nouvelle tentative relit le fichier depuis le début ; le flux chiffré ne peut pas être rejoué
+		if !uploadEncrypt {
+			req.GetBody = func() (io.ReadCloser, error) {
+				if _, err := file.Seek(0, io.SeekStart); err != nil {
+					return nil, err
 				}
-				uploadedSize += int64(n)
+				bar.restart()
+				return io.NopCloser(bar.reader(file)), nil
 			}
-		}()
+			req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+		}
 
 		// Envoyer la requête
-		resp, err := client.Do(req)
+		display.start()
+		resp, err := client.do(req)
 		if err != nil {
-			progressChan <- struct{}{} // Arrêter l'animation
+			bar.fail(err)
+			display.finish()
+			if cmd.Context().Err() != nil {
+				fmt.Printf("Upload of '%s' interrupted after %s sent; the object was not stored.\n", fileName, bar.summary())
+				return
+			}
 			log.Fatalf("Error uploading file: %v", err)
 		}
 		defer resp.Body.Close()
 
-		// Mettre à jour la barre de progression pour indiquer 100%
-		printProgressUpload(uploadedSize, totalSize)
-		progressChan <- struct{}{} // Arrêter la barre de progression
+		if resp.StatusCode == http.StatusOK {
+			bar.finish()
+		} else {
+			bar.fail(fmt.Errorf("status code %d", resp.StatusCode))
+		}
+		display.finish()
 
 		switch resp.StatusCode {
 		case http.StatusOK :
-			fmt.Printf("\nFile '%s' uploaded successfully to bucket '%s'.\n", fileName, bucketName)
+			fmt.Printf("File '%s' uploaded successfully to bucket '%s'.\n", fileName, bucketName)
+			if encryption := describeEncryption(resp.Header); encryption != "" {
+				fmt.Printf("Encryption: %s\n", encryption)
+			}
+			if uploadEncrypt {
+				fmt.Printf("Client-side encryption: %s (master key id %s)\n", envelopeAlgorithm, masterKeyID(masterKey))
+			}
 		case http.StatusInternalServerError: 
 			fmt.Printf("Internal server error : Status code: %d\n", resp.StatusCode)
 		case http.StatusNotFound:
 			fmt.Printf("The system cannot find the file specified")
+		case http.StatusBadRequest, http.StatusForbidden:
+			fmt.Printf("Failed to upload file: %v\n", responseError(resp))
 		default:
 			fmt.Printf("Failed to upload file. Status code: %d\n", resp.StatusCode)
 		}
@@ -131,19 +199,57 @@ var UploadFileCmd = &cobra.Command{
 	},
 }
 
-func init() {
-	RootCmd.AddCommand(UploadFileCmd)
-}
+// uploadCompressed envoie le fichier compressé (puis chiffré avec une clé maîtresse) au fil de la lecture.
+// La taille envoyée n'étant pas connue d'avance, le flux passe par un upload multipart s'il dépasse
+// une partie. Retourne le nombre d'octets stockés.
+func uploadCompressed(ctx context.Context, client *s3Client, bucketName, fileName string, file io.Reader, header http.Header, algorithm string, originalSize int64, masterKey []byte) (int64, error) {
+	header.Set(metaCompression, algorithm)
+	header.Set(metaOriginalSize, strconv.FormatInt(originalSize, 10))
+
+	compressed := compressStream(algorithm, file)
+	defer compressed.Close()
+	var stream io.Reader = compressed
+	if masterKey != nil {
+		// Le contenu chiffré n'est plus décodable par un client HTTP : pas de Content-Encoding
+		env, err := sealEnvelope(masterKey, -1, header)
+		if err != nil {
+			return 0, fmt.Errorf("failed to prepare client-side encryption: %w", err)
+		}
+		stream = env.encrypt(stream)
+	} else {
+		header.Set("Content-Encoding", algorithm)
+	}
 
-// Fonction pour afficher la barre de progression
-func printProgressUpload(uploaded, total int64) {
-	if total == -1 {
-		fmt.Printf("\rUploading... %d bytes", uploaded)
-		return
+	display, err := newProgress()
+	if err != nil {
+		return 0, err
+	}
+	bar := display.track(fileName, -1)
+	upload := &multipartUpload{
+		client:      client,
+		bucket:      bucketName,
+		key:         fileName,
+		header:      header,
+		partSize:    defaultPartSize,
+		concurrency: defaultUploadConcurrency,
+		transfer:    bar,
 	}
-	percentage := float64(uploaded) / float64(total) * 100
-	progressBar := int(percentage / 2) // barre de 50 caractères
-	fmt.Printf("\r[%-50s] %3.2f%%", strings.Repeat("#", progressBar), percentage)
+	display.start()
+	if _, err := upload.upload(ctx, stream); err != nil {
+		bar.fail(err)
+		display.finish()
+		return 0, err
+	}
+	bar.finish()
+	display.finish()
+	return bar.current.Load(), nil
 }
 
-
+func init() {
+	UploadFileCmd.Flags().StringArrayVar(&uploadTags, "tag", nil, "tag the uploaded object (key=value); repeat for several tags")
+	addSSEFlags(UploadFileCmd, &uploadSSE, true)
+	UploadFileCmd.Flags().BoolVar(&uploadEncrypt, "encrypt", false, "encrypt the content locally (AES-256-GCM) before sending it")
+	UploadFileCmd.Flags().StringVar(&uploadMasterKeyFile, "master-key-file", "", "master key wrapping the object's data key (default: master_key_file of the profile)")
+	UploadFileCmd.Flags().StringVar(&uploadCompress, "compress", "", "compress the content while uploading: gzip or zstd")
+	RootCmd.AddCommand(UploadFileCmd)
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// VersioningConfiguration représente la configuration de versioning d'un bucket
+type VersioningConfiguration struct {
+	XMLName   xml.Name `xml:"VersioningConfiguration"`
+	Xmlns     string   `xml:"xmlns,attr,omitempty"`
+	Status    string   `xml:"Status,omitempty"`
+	MFADelete string   `xml:"MfaDelete,omitempty"`
+}
+
+// VersioningCmd regroupe les sous-commandes de versioning
+var VersioningCmd = &cobra.Command{
+	Use:   "versioning",
+	Short: "Enables, suspends or shows the versioning state of a bucket",
+}
+
+var versioningEnableCmd = &cobra.Command{
+	Use:   "enable <bucket-name>",
+	Short: "Enables versioning on a bucket",
+	Run: func(cmd *cobra.Command, args []string) {
+		setVersioning(cmd, args, "Enabled")
+	},
+}
+
+var versioningSuspendCmd = &cobra.Command{
+	Use:   "suspend <bucket-name>",
+	Short: "Suspends versioning on a bucket (existing versions are kept)",
+	Run: func(cmd *cobra.Command, args []string) {
+		setVersioning(cmd, args, "Suspended")
+	},
+}
+
+var versioningStatusCmd = &cobra.Command{
+	Use:   "status <bucket-name>",
+	Short: "Shows the versioning state of a bucket",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 {
+			log.Println("Usage: versioning status <bucket-name>")
+			return
+		}
+		bucketName := args[0]
+
+		client, err := newConfiguredClient()
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+
+		config, err := getVersioning(cmd.Context(), client, bucketName)
+		if err != nil {
+			log.Printf("Error: %v", err)
+			return
+		}
+		fmt.Printf("Versioning for bucket '%s': %s\n", bucketName, versioningStatus(config))
+		if config.MFADelete != "" {
+			fmt.Printf("MFA delete: %s\n", config.MFADelete)
+		}
+	},
+}
+
+// getVersioning lit la configuration de versioning du bucket
+func getVersioning(ctx context.Context, client *s3Client, bucketName string) (*VersioningConfiguration, error) {
+	data, err := client.getSubresource(ctx, bucketName, "", "versioning", nil)
+	if err != nil {
+		return nil, err
+	}
+	var config VersioningConfiguration
+	if err := xml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse versioning configuration: %w", err)
+	}
+	return &config, nil
+}
+
+// versioningStatus décrit l'état du versioning ; un bucket jamais versionné n'a pas de statut
+func versioningStatus(config *VersioningConfiguration) string {
+	if config.Status == "" {
+		return "Unversioned (never enabled)"
+	}
+	return config.Status
+}
+
+// setVersioning active ou suspend le versioning du bucket
+func setVersioning(cmd *cobra.Command, args []string, status string) {
+	if len(args) < 1 {
+		log.Printf("Usage: versioning %s <bucket-name>", cmd.Name())
+		return
+	}
+	bucketName := args[0]
+
+	client, err := newConfiguredClient()
+	if err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+
+	body, err := xml.Marshal(VersioningConfiguration{Xmlns: s3XMLNamespace, Status: status})
+	if err != nil {
+		log.Printf("Error marshalling XML: %v", err)
+		return
+	}
+	if err := client.putSubresource(cmd.Context(), bucketName, "", "versioning", nil, body, nil); err != nil {
+		log.Printf("Error: %v", err)
+		return
+	}
+	fmt.Printf("Versioning %s for bucket '%s'.\n", strings.ToLower(status), bucketName)
+}
+
+func init() {
+	VersioningCmd.AddCommand(versioningEnableCmd, versioningSuspendCmd, versioningStatusCmd)
+	RootCmd.AddCommand(VersioningCmd)
+}
+
+// versionQuery retourne le paramètre versionId d'une requête ciblant une version précise
+func versionQuery(versionID string) url.Values {
+	if versionID == "" {
+		return nil
+	}
+	return url.Values{"versionId": {versionID}}
+}
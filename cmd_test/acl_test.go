@@ -0,0 +1,100 @@
+package cmd_test
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestACL(t *testing.T) {
+	standIn, server := newS3StandIn(t)
+	useRetryProfile(t, "acl", server.URL)
+
+	dir := t.TempDir()
+	for _, name := range []string{"logo.png", "banner.png"} {
+		file := filepath.Join(dir, name)
+		os.WriteFile(file, []byte("PNG"), 0o644)
+		runCommand(t, "upload-file", "press", file, "--progress", "none")
+	}
+	standIn.objects["press/2024/launch.png"] = []byte("PNG")
+	standIn.objects["press/2024/team.jpg"] = []byte("JPG")
+
+	// Une ligne du tableau : colonnes séparées par au moins deux espaces
+	row := func(grantee, kind, permission string) *regexp.Regexp {
+		return regexp.MustCompile(`(?m)^` + regexp.QuoteMeta(grantee) + ` {2,}` + kind + ` {2,}` + permission + `$`)
+	}
+
+	t.Run("GetDefault", func(t *testing.T) {
+		output := runCommand(t, "acl", "get", "press", "logo.png")
+		assert.Contains(t, output, "Owner: test-owner (79a59df900b949e55d96a1e698fbaced)")
+		assert.Regexp(t, `(?m)^GRANTEE +TYPE +PERMISSION$`, output)
+		assert.Regexp(t, row("test-owner (79a59df900b949e55d96a1e698fbaced)", "user", "FULL_CONTROL"), output)
+		assert.NotContains(t, output, "Warning")
+	})
+
+	t.Run("SetCanned", func(t *testing.T) {
+		output := runCommand(t, "acl", "set", "s3://press/logo.png", "--canned", "public-read")
+		assert.Contains(t, output, "ACL of object 'logo.png' in bucket 'press' updated.")
+
+		output = runCommand(t, "acl", "get", "press", "logo.png")
+		assert.Regexp(t, row("AllUsers", "group", "READ"), output)
+		assert.Contains(t, output, "Warning: the ACL grants access to everyone (AllUsers).")
+		assert.Contains(t, runCommand(t, "acl", "get", "press", "logo.png", "--xml"), `<URI>http://acs.amazonaws.com/groups/global/AllUsers</URI>`)
+	})
+
+	t.Run("SetGrants", func(t *testing.T) {
+		output := runCommand(t, "acl", "set", "press", "--grant", "read=email:auditor@example.com", "--grant", "write-acp=id:a1b2c3d4")
+		assert.Contains(t, output, "ACL of bucket 'press' updated.")
+		stored := string(standIn.acls["press/"])
+		assert.Contains(t, stored, `<Grantee xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type="AmazonCustomerByEmail"><EmailAddress>auditor@example.com</EmailAddress></Grantee><Permission>READ</Permission>`)
+
+		output = runCommand(t, "acl", "get", "press")
+		assert.Regexp(t, row("79a59df900b949e55d96a1e698fbaced", "user", "FULL_CONTROL"), output, "Expected the owner to keep FULL_CONTROL")
+		assert.Regexp(t, row("auditor@example.com", "email", "READ"), output)
+		assert.Regexp(t, row("a1b2c3d4", "user", "WRITE_ACP"), output)
+
+		// --add complète les permissions actuelles, sans doublon
+		runCommand(t, "acl", "set", "press", "--add", "--grant", "write=group:LogDelivery", "--grant", "read=email:auditor@example.com")
+		output = runCommand(t, "acl", "get", "press")
+		assert.Regexp(t, row("LogDelivery", "group", "WRITE"), output)
+		assert.Len(t, regexp.MustCompile(`auditor@example.com`).FindAllString(output, -1), 1)
+
+		// Sans --add, les permissions sont remplacées
+		runCommand(t, "acl", "set", "press", "--grant", "read=group:AuthenticatedUsers")
+		output = runCommand(t, "acl", "get", "press")
+		assert.NotContains(t, output, "auditor@example.com")
+		assert.Regexp(t, row("AuthenticatedUsers", "group", "READ"), output)
+	})
+
+	t.Run("Recursive", func(t *testing.T) {
+		output := runCommand(t, "acl", "set", "s3://press/2024/", "--recursive", "--canned", "authenticated-read")
+		assert.Contains(t, output, "ACL applied to 2 object(s) under prefix '2024/' in bucket 'press'.")
+		assert.Contains(t, string(standIn.acls["press/2024/launch.png"]), "AuthenticatedUsers")
+		assert.Contains(t, string(standIn.acls["press/2024/team.jpg"]), "AuthenticatedUsers")
+		assert.NotContains(t, string(standIn.acls["press/banner.png"]), "AuthenticatedUsers", "Expected objects outside the prefix to be left alone")
+
+		assert.Contains(t, runCommand(t, "acl", "set", "press", "archive/", "--recursive", "--canned", "private"), "No objects under prefix 'archive/' in bucket 'press'.")
+	})
+
+	t.Run("InvalidOptions", func(t *testing.T) {
+		cases := map[string][]string{
+			"invalid grant 'read' (expected <permission>=<id|email|uri|group>:<value>)":        {"--grant", "read"},
+			"invalid permission in grant 'delete=id:a1'":                                       {"--grant", "delete=id:a1"},
+			"unknown group 'Everyone' in grant 'read=group:Everyone'":                          {"--grant", "read=group:Everyone"},
+			"invalid grantee type 'user' in grant 'read=user:bob'":                             {"--grant", "read=user:bob"},
+			"unsupported canned ACL 'bucket-owner-read' (expected one of private, public-read": {"--canned", "bucket-owner-read"},
+			"--add only applies to --grant":                                                    {"--canned", "private", "--add"},
+		}
+		for message, flags := range cases {
+			assert.Contains(t, runCommand(t, append([]string{"acl", "set", "press"}, flags...)...), message)
+		}
+		assert.Contains(t, runCommand(t, "acl", "set", "press", "banner.png", "--canned", "bucket-owner-read"), "ACL of object 'banner.png' in bucket 'press' updated.")
+
+		standIn.aclsDisabled["enforced"] = true
+		output := runCommand(t, "acl", "get", "enforced")
+		assert.Contains(t, output, "ACLs are disabled on bucket 'enforced' (Object Ownership: bucket owner enforced); use a bucket policy instead")
+	})
+}
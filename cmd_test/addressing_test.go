@@ -0,0 +1,100 @@
+package cmd_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/AlizeaMassePlat/plateforme-mycli/cmd"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+// Serveur S3 multi-régions : le premier segment du chemin correspond à la région de l'endpoint
+func TestBucketRegionRedirect(t *testing.T) {
+	var mu sync.Mutex
+	var authorizations []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/us-east-1/euro-bucket/":
+			w.Header().Set("X-Amz-Bucket-Region", "eu-west-3")
+			w.WriteHeader(http.StatusMovedPermanently)
+			fmt.Fprint(w, "<Error><Code>PermanentRedirect</Code><Message>The bucket you are attempting to access must be addressed using the specified endpoint.</Message></Error>")
+		case "/eu-west-3/euro-bucket/":
+			authorizations = append(authorizations, r.Header.Get("Authorization"))
+			fmt.Fprint(w, "<ListBucketResult><Contents><Key>paris.txt</Key><LastModified>2024-09-17T08:58:31Z</LastModified><Size>12</Size></Contents></ListBucketResult>")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	viper.Set("profiles.regional.api_url", server.URL+"/us-east-1")
+	viper.Set("profiles.regional.endpoint_template", server.URL+"/{region}")
+	viper.Set("profiles.regional.access_key", "AKIAREGIONAL")
+	viper.Set("profiles.regional.secret_key", "regional-secret")
+	t.Setenv("MYCLI_PROFILE", "regional")
+
+	output := CaptureOutput(func() {
+		cmd.RootCmd.SetArgs([]string{"list-object", "euro-bucket"})
+		err := cmd.RootCmd.Execute()
+		assert.NoError(t, err, "Expected no error when the bucket lives in another region")
+	})
+
+	assert.Contains(t, output, "paris.txt", "Expected the request to be replayed against the bucket region")
+	assert.Len(t, authorizations, 1)
+	assert.Contains(t, authorizations[0], "/eu-west-3/s3/aws4_request", "Expected the replayed request to be signed for the bucket region")
+}
+
+func TestAddressingStyle(t *testing.T) {
+	viper.Set("profiles.hosted.api_url", "https://s3.example.com")
+	viper.Set("profiles.hosted.access_key", "AKIAHOSTED")
+	viper.Set("profiles.hosted.secret_key", "hosted-secret")
+	t.Setenv("MYCLI_PROFILE", "hosted")
+
+	presignedURL := func(bucket, key string) *url.URL {
+		output := runCommand(t, "presign", bucket, key)
+		u, err := url.Parse(strings.TrimSpace(output))
+		assert.NoError(t, err, "Expected a valid URL")
+		return u
+	}
+
+	// En mode auto, un nom compatible DNS utilise l'adressage virtual-host
+	t.Run("AutoVirtualHost", func(t *testing.T) {
+		viper.Set("profiles.hosted.addressing_style", "auto")
+		u := presignedURL("media", "photos/cat.jpg")
+		assert.Equal(t, "media.s3.example.com", u.Host)
+		assert.Equal(t, "/photos/cat.jpg", u.Path)
+	})
+
+	// Un bucket avec des points en HTTPS reste en path-style (certificat wildcard)
+	t.Run("AutoDottedBucket", func(t *testing.T) {
+		viper.Set("profiles.hosted.addressing_style", "auto")
+		u := presignedURL("my.dotted.bucket", "file.txt")
+		assert.Equal(t, "s3.example.com", u.Host)
+		assert.Equal(t, "/my.dotted.bucket/file.txt", u.Path)
+	})
+
+	t.Run("ForcedPathStyle", func(t *testing.T) {
+		viper.Set("profiles.hosted.addressing_style", "path")
+		u := presignedURL("media", "photos/cat.jpg")
+		assert.Equal(t, "s3.example.com", u.Host)
+		assert.Equal(t, "/media/photos/cat.jpg", u.Path)
+	})
+
+	t.Run("InvalidStyle", func(t *testing.T) {
+		viper.Set("profiles.hosted.addressing_style", "subdomain")
+		output := CaptureOutput(func() {
+			cmd.RootCmd.SetArgs([]string{"list-buckets"})
+			err := cmd.RootCmd.Execute()
+			assert.NoError(t, err)
+		})
+		assert.Contains(t, output, "invalid addressing_style 'subdomain'")
+	})
+}
@@ -0,0 +1,159 @@
+package cmd_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AlizeaMassePlat/plateforme-mycli/cmd"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+// stsStandIn simule le service STS : chaque appel AssumeRole délivre un nouveau jeton
+type stsStandIn struct {
+	mu       sync.Mutex
+	calls    int
+	lifetime time.Duration
+	lastAuth string
+	lastForm map[string]string
+}
+
+func (s *stsStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := r.ParseForm(); err != nil || r.Form.Get("Action") != "AssumeRole" {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, "<ErrorResponse><Error><Code>InvalidAction</Code><Message>bad request</Message></Error></ErrorResponse>")
+		return
+	}
+	s.calls++
+	s.lastAuth = r.Header.Get("Authorization")
+	s.lastForm = map[string]string{"RoleArn": r.Form.Get("RoleArn"), "RoleSessionName": r.Form.Get("RoleSessionName")}
+
+	expiration := time.Now().Add(s.lifetime).UTC().Format(time.RFC3339)
+	fmt.Fprintf(w, `<AssumeRoleResponse><AssumeRoleResult><Credentials>
+<AccessKeyId>ASIATEMP%d</AccessKeyId><SecretAccessKey>temp-secret-%d</SecretAccessKey>
+<SessionToken>token-%d</SessionToken><Expiration>%s</Expiration>
+</Credentials></AssumeRoleResult></AssumeRoleResponse>`, s.calls, s.calls, s.calls, expiration)
+}
+
+func (s *stsStandIn) setLifetime(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lifetime = d
+}
+
+func (s *stsStandIn) callCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls
+}
+
+func TestAssumeRoleProfile(t *testing.T) {
+	sts := &stsStandIn{lifetime: 2 * time.Minute}
+	stsServer := httptest.NewServer(sts)
+	defer stsServer.Close()
+
+	// Serveur S3 qui enregistre les en-têtes de signature reçus
+	var mu sync.Mutex
+	var tokens, authorizations []string
+	s3Server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		tokens = append(tokens, r.Header.Get("X-Amz-Security-Token"))
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		mu.Unlock()
+		fmt.Fprint(w, "<ListAllMyBucketsResult><Buckets></Buckets></ListAllMyBucketsResult>")
+	}))
+	defer s3Server.Close()
+
+	viper.Set("s3.api_url", s3Server.URL)
+	viper.Set("s3.access_key", "SOURCEKEY")
+	viper.Set("s3.secret_key", "source-secret")
+	viper.Set("profiles.role-test.role_arn", "arn:aws:iam::123456789012:role/bs3-test")
+	viper.Set("profiles.role-test.source_profile", "default")
+	viper.Set("profiles.role-test.sts_endpoint", stsServer.URL)
+	viper.Set("profiles.role-test.role_session_name", "bs3-test-session")
+	viper.Set("cache_dir", t.TempDir())
+	t.Setenv("MYCLI_PROFILE", "role-test")
+	t.Cleanup(func() {
+		viper.Set("s3.access_key", "")
+		viper.Set("s3.secret_key", "")
+		viper.Set("cache_dir", "")
+		viper.Set("s3.api_url", "http://localhost:9090")
+	})
+
+	listBuckets := func() string {
+		return CaptureOutput(func() {
+			cmd.RootCmd.SetArgs([]string{"list-buckets"})
+			err := cmd.RootCmd.Execute()
+			assert.NoError(t, err, "Expected no error when listing buckets with an assumed role")
+		})
+	}
+
+	// Premier appel : AssumeRole signé avec la clé du profil source
+	t.Run("AssumeRoleOnFirstRequest", func(t *testing.T) {
+		output := listBuckets()
+		assert.Contains(t, output, "No buckets found.")
+		assert.Equal(t, 1, sts.callCount(), "Expected a single AssumeRole call")
+		assert.Contains(t, sts.lastAuth, "Credential=SOURCEKEY/", "Expected AssumeRole to be signed with the source profile")
+		assert.Contains(t, sts.lastAuth, "/sts/aws4_request", "Expected AssumeRole to be signed for the sts service")
+		assert.Equal(t, "arn:aws:iam::123456789012:role/bs3-test", sts.lastForm["RoleArn"])
+		assert.Equal(t, "bs3-test-session", sts.lastForm["RoleSessionName"])
+		assert.Equal(t, "token-1", tokens[len(tokens)-1], "Expected the session token on the S3 request")
+		assert.Contains(t, authorizations[len(authorizations)-1], "Credential=ASIATEMP1/")
+	})
+
+	// Les identifiants expirent dans la fenêtre de renouvellement : nouvel appel STS
+	t.Run("RefreshExpiringCredentials", func(t *testing.T) {
+		sts.setLifetime(time.Hour)
+		listBuckets()
+		assert.Equal(t, 2, sts.callCount(), "Expected expiring credentials to be refreshed")
+		assert.Equal(t, "token-2", tokens[len(tokens)-1])
+	})
+
+	// Les identifiants valides sont relus depuis le cache
+	t.Run("ReuseCachedCredentials", func(t *testing.T) {
+		listBuckets()
+		listBuckets()
+		assert.Equal(t, 2, sts.callCount(), "Expected cached credentials to be reused")
+		assert.Equal(t, "token-2", tokens[len(tokens)-1])
+	})
+
+	// Changer les paramètres de l'appel AssumeRole n'utilise pas la session en cache
+	t.Run("CacheKeyedOnRoleParameters", func(t *testing.T) {
+		viper.Set("profiles.role-test.external_id", "partner-42")
+		t.Cleanup(func() { viper.Set("profiles.role-test.external_id", "") })
+		listBuckets()
+		assert.Equal(t, 3, sts.callCount(), "Expected a new ExternalId to call AssumeRole again")
+		listBuckets()
+		assert.Equal(t, 3, sts.callCount(), "Expected the new session to be cached")
+	})
+
+	// Profil inconnu
+	t.Run("UnknownProfile", func(t *testing.T) {
+		t.Setenv("MYCLI_PROFILE", "missing-profile")
+		output := listBuckets()
+		assert.Contains(t, output, "profile 'missing-profile' is not defined")
+	})
+
+	// Erreur STS remontée à l'utilisateur
+	t.Run("AssumeRoleDenied", func(t *testing.T) {
+		viper.Set("profiles.denied.role_arn", "arn:aws:iam::123456789012:role/denied")
+		viper.Set("profiles.denied.source_profile", "default")
+		denied := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, "<ErrorResponse><Error><Code>AccessDenied</Code><Message>not authorized to assume role</Message></Error></ErrorResponse>")
+		}))
+		defer denied.Close()
+		viper.Set("profiles.denied.sts_endpoint", denied.URL)
+		t.Setenv("MYCLI_PROFILE", "denied")
+
+		output := listBuckets()
+		assert.Contains(t, output, "AccessDenied: not authorized to assume role")
+	})
+}
@@ -0,0 +1,35 @@
+package cmd_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatCmd(t *testing.T) {
+	standIn, server := newS3StandIn(t)
+	standIn.objects["cat-bucket/notes.txt"] = []byte("0123456789abcdef")
+	useRetryProfile(t, "cat", server.URL)
+
+	t.Run("WholeObject", func(t *testing.T) {
+		assert.Equal(t, "0123456789abcdef", runCommand(t, "cat", "cat-bucket", "notes.txt"))
+	})
+
+	t.Run("ByteRange", func(t *testing.T) {
+		assert.Equal(t, "456", runCommand(t, "cat", "s3://cat-bucket/notes.txt", "--range", "4-6"))
+		assert.Equal(t, "cdef", runCommand(t, "cat", "s3://cat-bucket/notes.txt", "--range", "-4"))
+		assert.Equal(t, "ef", runCommand(t, "cat", "s3://cat-bucket/notes.txt", "--range", "bytes=14-"))
+	})
+
+	t.Run("InvalidRange", func(t *testing.T) {
+		assert.Contains(t, runCommand(t, "cat", "cat-bucket", "notes.txt", "--range", "9-2"), "invalid range '9-2'")
+	})
+
+	t.Run("UnsatisfiableRange", func(t *testing.T) {
+		assert.Contains(t, runCommand(t, "cat", "cat-bucket", "notes.txt", "--range", "100-"), "range '100-' is not satisfiable")
+	})
+
+	t.Run("MissingObject", func(t *testing.T) {
+		assert.Contains(t, runCommand(t, "cat", "cat-bucket", "missing.txt"), "object 'missing.txt' not found in bucket 'cat-bucket'")
+	})
+}
@@ -0,0 +1,115 @@
+package cmd_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientSideEncryption(t *testing.T) {
+	standIn, server := newS3StandIn(t)
+	useRetryProfile(t, "cse", server.URL)
+
+	dir := t.TempDir()
+	masterKey := bytes.Repeat([]byte{42}, 32)
+	masterKeyFile := filepath.Join(dir, "master.key")
+	os.WriteFile(masterKeyFile, []byte(base64.StdEncoding.EncodeToString(masterKey)+"\n"), 0o600)
+	otherKeyFile := filepath.Join(dir, "other.key")
+	os.WriteFile(otherKeyFile, bytes.Repeat([]byte{1}, 32), 0o600)
+
+	// Trois blocs de 64 Kio, dont le dernier incomplet
+	content := make([]byte, 150<<10)
+	rand.Read(content)
+	file := filepath.Join(dir, "ledger.bin")
+	os.WriteFile(file, content, 0o644)
+
+	download := func(t *testing.T, args ...string) (string, []byte, bool) {
+		out := t.TempDir()
+		output := runCommand(t, append([]string{"download-file", "vault", "ledger.bin", out, "--progress", "none"}, args...)...)
+		data, err := os.ReadFile(filepath.Join(out, "ledger.bin"))
+		_, partErr := os.Stat(filepath.Join(out, "ledger.bin.part"))
+		assert.True(t, os.IsNotExist(partErr), "Expected no partial file to be left behind")
+		return output, data, err == nil
+	}
+
+	t.Run("Upload", func(t *testing.T) {
+		output := runCommand(t, "upload-file", "vault", file, "--encrypt", "--master-key-file", masterKeyFile, "--progress", "none")
+		assert.Contains(t, output, "File 'ledger.bin' uploaded successfully to bucket 'vault'.")
+		assert.Contains(t, output, "Client-side encryption: AES-256-GCM-CHUNKED (master key id ")
+
+		stored := standIn.objects["vault/ledger.bin"]
+		assert.Len(t, stored, len(content)+3*16, "Expected one authentication tag per chunk")
+		assert.False(t, bytes.Contains(stored, content[:64]), "Expected the stored content to be encrypted")
+		header := standIn.headers["vault/ledger.bin"]
+		assert.Equal(t, "AES-256-GCM-CHUNKED", header.Get("X-Amz-Meta-Bs3-Cse-Algorithm"))
+		assert.Equal(t, "153600", header.Get("X-Amz-Meta-Bs3-Cse-Plaintext-Length"))
+		assert.NotEmpty(t, header.Get("X-Amz-Meta-Bs3-Cse-Wrapped-Key"))
+	})
+
+	t.Run("DownloadDecrypts", func(t *testing.T) {
+		output, data, ok := download(t, "--master-key-file", masterKeyFile)
+		assert.Contains(t, output, "Content decrypted and authenticated (client-side encryption).")
+		assert.True(t, ok)
+		assert.Equal(t, content, data)
+	})
+
+	t.Run("MasterKeyFromProfile", func(t *testing.T) {
+		output, _, _ := download(t)
+		assert.Contains(t, output, "'ledger.bin' is encrypted client-side: no master key: pass --master-key-file or set master_key_file in the profile")
+
+		viper.Set("profiles.cse.master_key_file", masterKeyFile)
+		defer viper.Set("profiles.cse.master_key_file", "")
+		_, data, _ := download(t)
+		assert.Equal(t, content, data)
+	})
+
+	t.Run("WrongMasterKey", func(t *testing.T) {
+		output, _, ok := download(t, "--master-key-file", otherKeyFile)
+		assert.Contains(t, output, "cannot decrypt 'ledger.bin': the object was encrypted with another master key")
+		assert.False(t, ok)
+	})
+
+	t.Run("TamperedContent", func(t *testing.T) {
+		original := standIn.objects["vault/ledger.bin"]
+		defer func() { standIn.objects["vault/ledger.bin"] = original }()
+
+		tampered := bytes.Clone(original)
+		tampered[70000] ^= 1
+		standIn.objects["vault/ledger.bin"] = tampered
+		output, _, ok := download(t, "--master-key-file", masterKeyFile)
+		assert.Contains(t, output, "cannot decrypt 'ledger.bin', partial file removed: authentication failed")
+		assert.False(t, ok)
+
+		// Suppression du dernier bloc : l'avant-dernier n'est pas marqué comme dernier
+		standIn.objects["vault/ledger.bin"] = original[:2*(64<<10+16)]
+		output, _, ok = download(t, "--master-key-file", masterKeyFile)
+		assert.Contains(t, output, "authentication failed: the encrypted content was modified or truncated")
+		assert.False(t, ok)
+	})
+
+	t.Run("RangeRefused", func(t *testing.T) {
+		output, _, ok := download(t, "--master-key-file", masterKeyFile, "--range", "0-99")
+		assert.Contains(t, output, "'ledger.bin' is encrypted client-side: a byte range cannot be decrypted")
+		assert.False(t, ok)
+	})
+
+	t.Run("EmptyFile", func(t *testing.T) {
+		empty := filepath.Join(dir, "empty.txt")
+		os.WriteFile(empty, nil, 0o644)
+		runCommand(t, "upload-file", "vault", empty, "--encrypt", "--master-key-file", masterKeyFile, "--progress", "none")
+		assert.Len(t, standIn.objects["vault/empty.txt"], 16)
+
+		out := t.TempDir()
+		output := runCommand(t, "download-file", "vault", "empty.txt", out, "--master-key-file", masterKeyFile, "--progress", "none")
+		assert.Contains(t, output, "Content decrypted and authenticated")
+		data, err := os.ReadFile(filepath.Join(out, "empty.txt"))
+		assert.NoError(t, err)
+		assert.Empty(t, data)
+	})
+}
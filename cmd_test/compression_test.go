@@ -0,0 +1,103 @@
+package cmd_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompression(t *testing.T) {
+	standIn, server := newS3StandIn(t)
+	useRetryProfile(t, "compress", server.URL)
+
+	dir := t.TempDir()
+	var logs strings.Builder
+	for i := 0; i < 20000; i++ {
+		fmt.Fprintf(&logs, "2024-01-01T00:00:%02dZ INFO request served path=/api/items/%d status=200\n", i%60, i%100)
+	}
+	logFile := filepath.Join(dir, "app.log")
+	os.WriteFile(logFile, []byte(logs.String()), 0o644)
+
+	t.Run("UploadGzip", func(t *testing.T) {
+		output := runCommand(t, "upload-file", "logs", logFile, "--compress", "gzip", "--progress", "none")
+		assert.Contains(t, output, "File 'app.log' uploaded successfully to bucket 'logs'.")
+		assert.Contains(t, output, "Compressed with gzip: ")
+
+		header := standIn.headers["logs/app.log"]
+		assert.Equal(t, "gzip", header.Get("Content-Encoding"))
+		assert.Equal(t, "gzip", header.Get("X-Amz-Meta-Bs3-Compression"))
+		assert.Equal(t, fmt.Sprint(logs.Len()), header.Get("X-Amz-Meta-Bs3-Original-Size"))
+
+		stored := standIn.objects["logs/app.log"]
+		assert.Less(t, len(stored), logs.Len()/10, "Expected log lines to compress well")
+		reader, err := gzip.NewReader(bytes.NewReader(stored))
+		assert.NoError(t, err)
+		data, _ := io.ReadAll(reader)
+		assert.Equal(t, logs.String(), string(data))
+	})
+
+	t.Run("DownloadDecompresses", func(t *testing.T) {
+		out := t.TempDir()
+		output := runCommand(t, "download-file", "logs", "app.log", out, "--progress", "none")
+		assert.Contains(t, output, "Content decompressed (gzip).")
+		data, _ := os.ReadFile(filepath.Join(out, "app.log"))
+		assert.Equal(t, logs.String(), string(data))
+	})
+
+	t.Run("DownloadRaw", func(t *testing.T) {
+		out := t.TempDir()
+		output := runCommand(t, "download-file", "logs", "app.log", out, "--raw", "--progress", "none")
+		assert.Contains(t, output, "Content kept compressed (gzip) in '"+filepath.Join(out, "app.log.gz")+"'.")
+		data, _ := os.ReadFile(filepath.Join(out, "app.log.gz"))
+		assert.Equal(t, standIn.objects["logs/app.log"], data)
+
+		output = runCommand(t, "download-file", "logs", "app.log", out, "--range", "0-9", "--progress", "none")
+		assert.Contains(t, output, "'app.log' is compressed (gzip): a byte range cannot be decompressed")
+	})
+
+	t.Run("CorruptedContent", func(t *testing.T) {
+		original := standIn.objects["logs/app.log"]
+		defer func() { standIn.objects["logs/app.log"] = original }()
+		standIn.objects["logs/app.log"] = original[:len(original)/2]
+
+		out := t.TempDir()
+		output := runCommand(t, "download-file", "logs", "app.log", out, "--progress", "none")
+		assert.Contains(t, output, "cannot decompress 'app.log', partial file removed")
+		_, err := os.Stat(filepath.Join(out, "app.log"))
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("ZstdEncryptedMultipart", func(t *testing.T) {
+		// Contenu incompressible, plus grand qu'une partie : l'envoi passe par un upload multipart
+		content := make([]byte, 9<<20)
+		rand.Read(content)
+		file := filepath.Join(dir, "blob.bin")
+		os.WriteFile(file, content, 0o644)
+		keyFile := filepath.Join(dir, "master.key")
+		os.WriteFile(keyFile, bytes.Repeat([]byte{3}, 32), 0o600)
+
+		output := runCommand(t, "upload-file", "logs", file, "--compress", "zstd", "--encrypt", "--master-key-file", keyFile, "--progress", "none")
+		assert.Contains(t, output, "Compressed with zstd: 9.0 MiB -> ")
+		assert.Contains(t, output, "Client-side encryption: AES-256-GCM-CHUNKED")
+		assert.True(t, strings.Contains(strings.Join(standIn.requestLog(), "\n"), "POST /logs/blob.bin?uploads"), "Expected a multipart upload")
+
+		header := standIn.headers["logs/blob.bin"]
+		assert.Empty(t, header.Get("Content-Encoding"), "Expected no Content-Encoding on encrypted content")
+		assert.Equal(t, "zstd", header.Get("X-Amz-Meta-Bs3-Compression"))
+
+		out := t.TempDir()
+		output = runCommand(t, "download-file", "logs", "blob.bin", out, "--master-key-file", keyFile, "--progress", "none")
+		assert.Contains(t, output, "Content decompressed (zstd).")
+		assert.Contains(t, output, "Content decrypted and authenticated")
+		data, _ := os.ReadFile(filepath.Join(out, "blob.bin"))
+		assert.True(t, bytes.Equal(content, data), "Expected the original content back")
+	})
+}
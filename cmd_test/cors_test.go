@@ -0,0 +1,96 @@
+package cmd_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+const corsRules = `rules:
+  - id: web-app
+    origins: [https://app.example.com]
+    methods: [get, head, put]
+    headers: ["*"]
+    expose: [ETag]
+    max_age: 3600
+  - origins: ["*"]
+    methods: [GET]
+`
+
+func TestCORS(t *testing.T) {
+	standIn, server := newS3StandIn(t)
+	useRetryProfile(t, "cors", server.URL)
+
+	t.Run("NoConfiguration", func(t *testing.T) {
+		assert.Contains(t, runCommand(t, "cors", "get", "assets"), "No CORS configuration on bucket 'assets'.")
+		output := runCommand(t, "cors", "test", "assets", "--origin", "https://app.example.com")
+		assert.Contains(t, output, "DENIED (HTTP 403)")
+		assert.Contains(t, output, "Bucket 'assets' has no CORS configuration: every preflight request is rejected.")
+	})
+
+	t.Run("PutYAML", func(t *testing.T) {
+		output := runWithStdin(t, strings.NewReader(corsRules), "cors", "put", "assets", "-")
+		assert.Contains(t, output, "CORS configuration of bucket 'assets' replaced (2 rule(s)).")
+
+		rules := standIn.cors["assets"].Rules
+		assert.Len(t, rules, 2)
+		assert.Equal(t, []string{"GET", "HEAD", "PUT"}, rules[0].AllowedMethods, "Expected methods to be normalised")
+		assert.Equal(t, 3600, rules[0].MaxAgeSeconds)
+
+		output = runCommand(t, "cors", "get", "assets")
+		assert.Contains(t, output, "rules:\n  - id: web-app\n    origins:\n      - https://app.example.com\n    methods:\n      - GET")
+		assert.Contains(t, runCommand(t, "cors", "get", "assets", "--xml"), "<AllowedOrigin>https://app.example.com</AllowedOrigin>")
+	})
+
+	t.Run("PutJSONList", func(t *testing.T) {
+		output := runWithStdin(t, strings.NewReader(`[{"origins": ["https://a.example"], "methods": ["POST"]}]`), "cors", "put", "forms", "-")
+		assert.Contains(t, output, "CORS configuration of bucket 'forms' replaced (1 rule(s)).")
+	})
+
+	t.Run("PutInvalid", func(t *testing.T) {
+		output := runWithStdin(t, strings.NewReader("rules:\n  - origins: [https://a.example]\n    methods: [PATCH]\n"), "cors", "put", "assets", "-")
+		assert.Contains(t, output, "rule 1: unsupported method 'PATCH' (expected GET, PUT, POST, DELETE, HEAD)")
+
+		output = runWithStdin(t, strings.NewReader("rules:\n  - origin: [https://a.example]\n"), "cors", "put", "assets", "-")
+		assert.Contains(t, output, "field origin not found")
+	})
+
+	t.Run("PreflightAllowed", func(t *testing.T) {
+		output := runCommand(t, "cors", "test", "assets", "--origin", "https://app.example.com", "--method", "PUT", "--header", "Content-Type", "--key", "uploads/a.png")
+		assert.Contains(t, output, "Preflight from https://app.example.com for PUT on s3://assets/uploads/a.png: ALLOWED (HTTP 200)")
+		assert.Contains(t, output, "Access-Control-Max-Age: 3600")
+		assert.Contains(t, output, "  - rule 1 (web-app): matches")
+		assert.NotContains(t, output, "differs")
+	})
+
+	t.Run("PreflightDenied", func(t *testing.T) {
+		output := runCommand(t, "cors", "test", "assets", "--origin", "https://evil.example", "--method", "PUT")
+		assert.Contains(t, output, "DENIED (HTTP 403)")
+		assert.Contains(t, output, "  - rule 1 (web-app): origin 'https://evil.example' is not allowed (allowed: https://app.example.com)")
+		assert.Contains(t, output, "  - rule 2: method PUT is not allowed (allowed: GET)")
+	})
+
+	t.Run("PreflightTimeout", func(t *testing.T) {
+		// La requête préliminaire, non signée, respecte le délai de l'opération
+		slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(300 * time.Millisecond)
+		}))
+		defer slow.Close()
+		useRetryProfile(t, "cors-slow", slow.URL)
+		viper.Set("profiles.cors-slow.timeouts", map[string]string{"OptionsObject": "50ms"})
+
+		output := runCommand(t, "cors", "test", "assets", "--origin", "https://app.example.com")
+		assert.Contains(t, output, "Error sending preflight request")
+		assert.Contains(t, output, "context deadline exceeded")
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		assert.Contains(t, runCommand(t, "cors", "delete", "assets"), "CORS configuration deleted from bucket 'assets'.")
+		assert.Contains(t, runCommand(t, "cors", "get", "assets"), "No CORS configuration on bucket 'assets'.")
+	})
+}
@@ -0,0 +1,70 @@
+package cmd_test
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateBucketOptions(t *testing.T) {
+	standIn, server := newS3StandIn(t)
+	useRetryProfile(t, "create", server.URL)
+	viper.Set("profiles.create.access_key", "AKIACREATE")
+	viper.Set("profiles.create.secret_key", "create-secret")
+
+	t.Run("InvalidNames", func(t *testing.T) {
+		cases := map[string]string{
+			"Invoices-2024":    "invalid bucket name 'Invoices-2024': uppercase letters are not allowed; use 'invoices-2024'",
+			"invoices_2024":    "underscores are not allowed; use hyphens instead",
+			"ab":               "must be between 3 and 63 characters long (got 2)",
+			"invoices-":        "must begin and end with a letter or a digit",
+			"invoices..2024":   "must not contain two adjacent dots",
+			"invoices.-2024":   "a dot must not be next to a hyphen",
+			"192.168.10.4":     "must not be formatted as an IP address",
+			"xn--invoices":     "the prefix 'xn--' is reserved",
+			"invoices-s3alias": "the suffix '-s3alias' is reserved",
+			"factures@2024":    "character '@' is not allowed",
+		}
+		for name, message := range cases {
+			assert.Contains(t, runCommand(t, "create-bucket", name), message)
+		}
+		assert.Empty(t, standIn.requestLog(), "Expected invalid names to be rejected before any request")
+	})
+
+	t.Run("Region", func(t *testing.T) {
+		output := runCommand(t, "create-bucket", "reports-paris", "--region", "eu-west-3")
+		assert.Contains(t, output, "Bucket 'reports-paris' created successfully.")
+		assert.Contains(t, output, "Region: eu-west-3")
+		assert.Contains(t, string(standIn.configs["reports-paris?create"]), "<LocationConstraint>eu-west-3</LocationConstraint>")
+		assert.Contains(t, standIn.headers["reports-paris/"].Get("Authorization"), "/eu-west-3/s3/aws4_request", "Expected the request to be signed for the bucket region")
+
+		// us-east-1 est la région par défaut : S3 refuse qu'elle soit précisée
+		runCommand(t, "create-bucket", "reports-virginia", "--region", "us-east-1")
+		assert.Empty(t, standIn.configs["reports-virginia?create"])
+	})
+
+	t.Run("ACLAndVersioning", func(t *testing.T) {
+		output := runCommand(t, "create-bucket", "press-kit", "--acl", "public-read", "--versioning")
+		assert.Contains(t, output, "ACL: public-read")
+		assert.Contains(t, output, "Versioning enabled.")
+		assert.Equal(t, "public-read", standIn.headers["press-kit/"].Get("X-Amz-Acl"))
+		assert.Equal(t, "Enabled", standIn.versioning["press-kit"])
+
+		output = runCommand(t, "create-bucket", "press-kit-2", "--acl", "bucket-owner-full-control")
+		assert.Contains(t, output, "unsupported canned ACL 'bucket-owner-full-control' (expected one of private, public-read, public-read-write, authenticated-read)")
+	})
+
+	t.Run("IfNotExists", func(t *testing.T) {
+		output := runCommand(t, "create-bucket", "press-kit")
+		assert.Contains(t, output, "BucketAlreadyOwnedByYou", "Expected the conflict to be reported without --if-not-exists")
+
+		output = runCommand(t, "create-bucket", "press-kit", "--if-not-exists", "--acl", "private")
+		assert.Contains(t, output, "Bucket 'press-kit' already exists and is yours; left unchanged (--if-not-exists).")
+		assert.Equal(t, "public-read", standIn.headers["press-kit/"].Get("X-Amz-Acl"), "Expected the existing bucket to be left unchanged")
+
+		standIn.buckets["shared-name"] = "another-account"
+		output = runCommand(t, "create-bucket", "shared-name", "--if-not-exists")
+		assert.Contains(t, output, "bucket name 'shared-name' is already taken by another account (bucket names are global); choose another name")
+	})
+}
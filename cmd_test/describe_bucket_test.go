@@ -0,0 +1,67 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescribeBucket(t *testing.T) {
+	standIn, server := newS3StandIn(t)
+	useRetryProfile(t, "describe", server.URL)
+	viper.Set("profiles.describe.access_key", "AKIADESCRIBE")
+	viper.Set("profiles.describe.secret_key", "describe-secret")
+
+	runCommand(t, "create-bucket", "vault", "--region", "eu-west-3", "--object-lock")
+	standIn.configs["vault?object-lock"] = []byte(`<ObjectLockConfiguration><ObjectLockEnabled>Enabled</ObjectLockEnabled><Rule><DefaultRetention><Mode>GOVERNANCE</Mode><Days>30</Days></DefaultRetention></Rule></ObjectLockConfiguration>`)
+	standIn.configs["vault?encryption"] = []byte(`<ServerSideEncryptionConfiguration><Rule><ApplyServerSideEncryptionByDefault><SSEAlgorithm>AES256</SSEAlgorithm></ApplyServerSideEncryptionByDefault></Rule></ServerSideEncryptionConfiguration>`)
+	standIn.configs["vault?lifecycle"] = []byte(`<LifecycleConfiguration><Rule><ID>purge-tmp</ID><Filter><Prefix>tmp/</Prefix></Filter><Status>Enabled</Status><Expiration><Days>7</Days></Expiration></Rule><Rule><ID>archive</ID><Filter><Prefix></Prefix></Filter><Status>Disabled</Status><Transition><Days>90</Days><StorageClass>GLACIER</StorageClass></Transition></Rule></LifecycleConfiguration>`)
+	standIn.policies["vault"] = []byte(`{"Version":"2012-10-17","Statement":[{"Sid":"ReadReports","Effect":"Allow","Principal":"*","Action":"s3:GetObject","Resource":"arn:aws:s3:::vault/reports/*"}]}`)
+	standIn.tags["vault/"] = map[string]string{"team": "finance", "env": "prod"}
+
+	t.Run("Text", func(t *testing.T) {
+		output := runCommand(t, "describe-bucket", "vault")
+		assert.Contains(t, output, "Bucket: vault")
+		assert.Regexp(t, `(?m)^  Location: +eu-west-3$`, output)
+		assert.Regexp(t, `(?m)^  Versioning: +Enabled$`, output)
+		assert.Regexp(t, `(?m)^  Encryption: +SSE-S3 \(AES256\)$`, output)
+		assert.Regexp(t, `(?m)^  Policy: +1 statement\(s\)$`, output)
+		assert.Regexp(t, `(?m)^  CORS: +none$`, output)
+		assert.Regexp(t, `(?m)^  Lifecycle: +2 rule\(s\), 1 enabled$`, output)
+		assert.Regexp(t, `(?m)^  Tags: +env=prod, team=finance$`, output)
+		assert.Regexp(t, `(?m)^  Object Lock: +enabled, default retention GOVERNANCE for 30 day\(s\)$`, output)
+		assert.Regexp(t, `(?m)^  ACL: +owner test-owner \(79a59df900b949e55d96a1e698fbaced\), 1 grant\(s\)$`, output)
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		output := runCommand(t, "describe-bucket", "vault", "--format", "json")
+		var report map[string]any
+		assert.NoError(t, json.Unmarshal([]byte(output), &report))
+		assert.Equal(t, "vault", report["bucket"])
+		section := func(name string) map[string]any { return report[name].(map[string]any) }
+
+		assert.Equal(t, "configured", section("lifecycle")["status"])
+		assert.Equal(t, "not_configured", section("cors")["status"])
+		assert.Equal(t, map[string]any{"region": "eu-west-3"}, section("location")["details"])
+		assert.Equal(t, map[string]any{"env": "prod", "team": "finance"}, section("tags")["details"])
+		assert.Equal(t, "purge-tmp", section("lifecycle")["details"].(map[string]any)["rules"].([]any)[0].(map[string]any)["id"])
+		assert.Equal(t, "ReadReports", section("policy")["details"].(map[string]any)["Statement"].([]any)[0].(map[string]any)["Sid"])
+		assert.Equal(t, map[string]any{"mode": "GOVERNANCE", "days": float64(30)}, section("object_lock")["details"].(map[string]any)["default_retention"])
+	})
+
+	t.Run("NotConfigured", func(t *testing.T) {
+		standIn.aclsDisabled["plain"] = true
+		output := runCommand(t, "describe-bucket", "plain")
+		assert.Regexp(t, `(?m)^  Location: +us-east-1$`, output)
+		assert.Regexp(t, `(?m)^  Versioning: +Unversioned \(never enabled\)$`, output)
+		assert.Regexp(t, `(?m)^  Encryption: +none$`, output)
+		assert.Regexp(t, `(?m)^  Policy: +none$`, output)
+		assert.Regexp(t, `(?m)^  Tags: +none$`, output)
+		assert.Regexp(t, `(?m)^  Object Lock: +disabled$`, output)
+		assert.Regexp(t, `(?m)^  ACL: +disabled \(Object Ownership: bucket owner enforced\)$`, output)
+
+		assert.Contains(t, runCommand(t, "describe-bucket", "plain", "--format", "yaml"), "unsupported format 'yaml' (expected text or json)")
+	})
+}
@@ -0,0 +1,118 @@
+package cmd_test
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServerSideEncryption(t *testing.T) {
+	standIn, server := newS3StandIn(t)
+	useRetryProfile(t, "sse", server.URL)
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "payslip.pdf")
+	os.WriteFile(file, []byte("confidential"), 0o644)
+
+	// Clé SSE-C : brute dans un fichier, en base64 dans l'environnement
+	key := bytes.Repeat([]byte{7}, 32)
+	keyFile := filepath.Join(dir, "customer.key")
+	os.WriteFile(keyFile, key, 0o600)
+	sum := md5.Sum(key)
+	keyMD5 := base64.StdEncoding.EncodeToString(sum[:])
+	t.Setenv("HR_SSE_KEY", base64.StdEncoding.EncodeToString(key))
+	t.Setenv("OTHER_SSE_KEY", base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{9}, 32)))
+
+	t.Run("UploadKMS", func(t *testing.T) {
+		output := runCommand(t, "upload-file", "hr", file, "--sse", "aws:kms", "--sse-kms-key-id", "alias/hr", "--progress", "none")
+		assert.Contains(t, output, "File 'payslip.pdf' uploaded successfully to bucket 'hr'.")
+		assert.Contains(t, output, "Encryption: SSE-KMS (key alias/hr)")
+		assert.Equal(t, "aws:kms", standIn.headers["hr/payslip.pdf"].Get("X-Amz-Server-Side-Encryption"))
+
+		assert.Contains(t, runCommand(t, "stat", "hr", "payslip.pdf"), "Encryption:    SSE-KMS (key alias/hr)")
+	})
+
+	t.Run("UploadCustomerKey", func(t *testing.T) {
+		secret := filepath.Join(dir, "secret.pdf")
+		os.WriteFile(secret, []byte("confidential"), 0o644)
+		output := runCommand(t, "upload-file", "hr", secret, "--sse-c-key-file", keyFile, "--progress", "none")
+		assert.Contains(t, output, "Encryption: SSE-C (AES256, key MD5 "+keyMD5+")")
+		assert.Contains(t, output, "Warning: the SSE-C key is sent over plain HTTP")
+		header := standIn.headers["hr/secret.pdf"]
+		assert.Equal(t, base64.StdEncoding.EncodeToString(key), header.Get("X-Amz-Server-Side-Encryption-Customer-Key"))
+		assert.Equal(t, keyMD5, header.Get("X-Amz-Server-Side-Encryption-Customer-Key-Md5"))
+	})
+
+	t.Run("ReadCustomerKey", func(t *testing.T) {
+		output := runCommand(t, "stat", "hr", "secret.pdf")
+		assert.Contains(t, output, "object 'secret.pdf' is encrypted with a customer key (SSE-C): pass --sse-c-key-file or --sse-c-key-env")
+
+		output = runCommand(t, "stat", "hr", "secret.pdf", "--sse-c-key-env", "OTHER_SSE_KEY")
+		assert.Contains(t, output, "the SSE-C key may not be the one used to encrypt the object")
+
+		output = runCommand(t, "stat", "s3://hr/secret.pdf", "--sse-c-key-env", "HR_SSE_KEY")
+		assert.Contains(t, output, "Encryption:    SSE-C (AES256, key MD5 "+keyMD5+")")
+
+		out := t.TempDir()
+		output = runCommand(t, "download-file", "hr", "secret.pdf", out, "--progress", "none")
+		assert.Contains(t, output, "pass --sse-c-key-file or --sse-c-key-env")
+		output = runCommand(t, "download-file", "hr", "secret.pdf", out, "--sse-c-key-env", "HR_SSE_KEY", "--progress", "none")
+		assert.Contains(t, output, "Download completed successfully.")
+		data, _ := os.ReadFile(filepath.Join(out, "secret.pdf"))
+		assert.Equal(t, "confidential", string(data))
+	})
+
+	t.Run("OtherErrorsUnchanged", func(t *testing.T) {
+		// Un refus sans rapport avec SSE-C est rapporté tel quel
+		other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, "<Error><Code>InvalidArgument</Code><Message>Invalid version id specified</Message></Error>")
+		}))
+		defer other.Close()
+		useRetryProfile(t, "sse-other", other.URL)
+
+		output := runCommand(t, "download-file", "hr", "secret.pdf", t.TempDir(), "--progress", "none")
+		assert.Contains(t, output, "InvalidArgument: Invalid version id specified (status 400)")
+		assert.NotContains(t, output, "SSE-C")
+	})
+
+	t.Run("CopyReencrypts", func(t *testing.T) {
+		output := runCommand(t, "copy-object", "s3://hr/secret.pdf", "s3://archive/secret.pdf", "--sse", "AES256")
+		assert.Contains(t, output, "InvalidRequest", "Expected the source key to be required")
+
+		output = runCommand(t, "copy-object", "s3://hr/secret.pdf", "s3://archive/secret.pdf", "--sse", "AES256", "--source-sse-c-key-file", keyFile)
+		assert.Contains(t, output, "Copied s3://hr/secret.pdf to s3://archive/secret.pdf.")
+		assert.Contains(t, output, "Encryption: SSE-S3 (AES256)")
+		assert.Equal(t, keyMD5, standIn.headers["archive/secret.pdf"].Get("X-Amz-Copy-Source-Server-Side-Encryption-Customer-Key-Md5"))
+		assert.Contains(t, runCommand(t, "stat", "archive", "secret.pdf"), "Encryption:    SSE-S3 (AES256)")
+	})
+
+	t.Run("InvalidOptions", func(t *testing.T) {
+		assert.Contains(t, runCommand(t, "stat", "hr", "secret.pdf", "--sse-c-key-env", "MISSING_SSE_KEY"), "environment variable MISSING_SSE_KEY is not set")
+		os.WriteFile(filepath.Join(dir, "short.key"), []byte("c2hvcnQ="), 0o600)
+		output := runCommand(t, "stat", "hr", "secret.pdf", "--sse-c-key-file", filepath.Join(dir, "short.key"))
+		assert.Contains(t, output, "must be 32 bytes (raw, or base64 encoded)")
+		output = runCommand(t, "copy-object", "s3://hr/a", "s3://hr/b", "--sse", "DES")
+		assert.Contains(t, output, "unsupported --sse value 'DES' (expected AES256 or aws:kms)")
+	})
+
+	t.Run("BucketDefault", func(t *testing.T) {
+		assert.Contains(t, runCommand(t, "encryption", "get", "hr"), "Bucket 'hr' has no default encryption.")
+
+		output := runCommand(t, "encryption", "put", "hr", "--sse", "aws:kms", "--sse-kms-key-id", "alias/hr", "--bucket-key")
+		assert.Contains(t, output, "Default encryption of bucket 'hr' set to SSE-KMS (key alias/hr), bucket key enabled.")
+		assert.Contains(t, string(standIn.configs["hr?encryption"]), "<SSEAlgorithm>aws:kms</SSEAlgorithm><KMSMasterKeyID>alias/hr</KMSMasterKeyID>")
+		assert.Contains(t, runCommand(t, "encryption", "get", "hr"), "Default encryption of bucket 'hr': SSE-KMS (key alias/hr), bucket key enabled")
+
+		assert.Contains(t, runCommand(t, "encryption", "put", "hr", "--sse", "AES256", "--bucket-key"), "--bucket-key only applies to --sse aws:kms")
+		assert.Contains(t, runCommand(t, "encryption", "put", "hr", "--sse", "AES256"), "Default encryption of bucket 'hr' set to SSE-S3 (AES256).")
+	})
+}
@@ -0,0 +1,80 @@
+package cmd_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// logLines génère n lignes de 40 octets
+func logLines(n int) []string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("%05d %s\n", i+1, strings.Repeat("x", 33))
+	}
+	return lines
+}
+
+func TestHeadTail(t *testing.T) {
+	standIn, server := newS3StandIn(t)
+	lines := logLines(5000) // 200 000 octets
+	standIn.objects["logs/app.log"] = []byte(strings.Join(lines, ""))
+	standIn.objects["logs/no-newline.txt"] = []byte("first\nsecond\nlast")
+	useRetryProfile(t, "head-tail", server.URL)
+
+	resetRanges := func() {
+		standIn.mu.Lock()
+		standIn.ranges = nil
+		standIn.mu.Unlock()
+	}
+
+	t.Run("HeadLines", func(t *testing.T) {
+		resetRanges()
+		assert.Equal(t, strings.Join(lines[:3], ""), runCommand(t, "head", "-n", "3", "s3://logs/app.log"))
+		assert.Equal(t, []string{"bytes=0-65535"}, standIn.ranges, "Expected a single ranged read")
+	})
+
+	t.Run("HeadBytes", func(t *testing.T) {
+		assert.Equal(t, "00001 xxxx", runCommand(t, "head", "-c", "10", "logs", "app.log"))
+	})
+
+	t.Run("TailLines", func(t *testing.T) {
+		resetRanges()
+		assert.Equal(t, strings.Join(lines[4998:], ""), runCommand(t, "tail", "-n", "2", "s3://logs/app.log"))
+		assert.Equal(t, []string{"bytes=-65536"}, standIn.ranges)
+	})
+
+	t.Run("TailExtendsRange", func(t *testing.T) {
+		resetRanges()
+		// 3000 lignes (120 000 octets) ne tiennent pas dans la première plage de 64 Kio : la suivante en couvre 128
+		assert.Equal(t, strings.Join(lines[2000:], ""), runCommand(t, "tail", "-n", "3000", "s3://logs/app.log"))
+		assert.Equal(t, []string{"bytes=-65536", "bytes=3392-134463"}, standIn.ranges)
+	})
+
+	t.Run("TailWholeObject", func(t *testing.T) {
+		assert.Equal(t, "first\nsecond\nlast", runCommand(t, "tail", "-n", "10", "s3://logs/no-newline.txt"))
+		assert.Equal(t, "second\nlast", runCommand(t, "tail", "-n", "2", "s3://logs/no-newline.txt"))
+	})
+
+	t.Run("TailBytes", func(t *testing.T) {
+		assert.Equal(t, "d\nlast", runCommand(t, "tail", "-c", "6", "s3://logs/no-newline.txt"))
+	})
+
+	t.Run("MissingObject", func(t *testing.T) {
+		assert.Contains(t, runCommand(t, "tail", "s3://logs/missing.log"), "object 'missing.log' not found in bucket 'logs'")
+	})
+
+	t.Run("DownloadRange", func(t *testing.T) {
+		dir := t.TempDir()
+		output := runCommand(t, "download-file", "logs", "no-newline.txt", dir, "--range", "6-11")
+		assert.Contains(t, output, "Download completed successfully.")
+
+		content, err := os.ReadFile(filepath.Join(dir, "no-newline.txt"))
+		assert.NoError(t, err)
+		assert.Equal(t, "second", string(content))
+	})
+}
@@ -0,0 +1,104 @@
+package cmd_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AlizeaMassePlat/plateforme-mycli/cmd"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+// stallingServer transmet (ou lit) les premiers octets puis bloque jusqu'à l'abandon de la requête,
+// en signalant sur started que le transfert est en cours
+func stallingServer(started chan<- struct{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			w.Header().Set("Content-Length", "1048576")
+			w.Write([]byte(strings.Repeat("a", 1024)))
+			w.(http.Flusher).Flush()
+			started <- struct{}{}
+			<-r.Context().Done()
+		case "PUT":
+			io.ReadFull(r.Body, make([]byte, 1024))
+			started <- struct{}{}
+			// Ne jamais répondre : la lecture se termine lorsque le client ferme la connexion
+			if conn, _, err := w.(http.Hijacker).Hijack(); err == nil {
+				io.Copy(io.Discard, conn)
+				conn.Close()
+			}
+		}
+	}))
+}
+
+// runInterrupted exécute la commande et annule son contexte dès que interrupt est signalé
+func runInterrupted(t *testing.T, command *cobra.Command, interrupt <-chan struct{}, args ...string) string {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	command.SetContext(ctx)
+	t.Cleanup(func() { command.SetContext(nil) })
+
+	go func() {
+		<-interrupt
+		cancel()
+	}()
+
+	return CaptureOutput(func() {
+		cmd.RootCmd.SetArgs(args)
+		err := cmd.RootCmd.Execute()
+		assert.NoError(t, err)
+	})
+}
+
+func TestInterruptedTransfers(t *testing.T) {
+	t.Run("DownloadRemovesPartialFile", func(t *testing.T) {
+		started := make(chan struct{}, 1)
+		server := stallingServer(started)
+		defer server.Close()
+		useRetryProfile(t, "interrupt-download", server.URL)
+
+		// Interrompre une fois les premiers octets écrits dans le fichier temporaire
+		dir := t.TempDir()
+		interrupt := make(chan struct{})
+		go func() {
+			<-started
+			for {
+				if info, err := os.Stat(filepath.Join(dir, "big.bin.part")); err == nil && info.Size() == 1024 {
+					close(interrupt)
+					return
+				}
+				time.Sleep(5 * time.Millisecond)
+			}
+		}()
+		output := runInterrupted(t, cmd.DownloadFileCmd, interrupt, "download-file", "bucket", "big.bin", dir)
+
+		assert.Contains(t, output, "download of 'big.bin' interrupted after 1024 of 1048576 bytes, partial file removed")
+		entries, err := os.ReadDir(dir)
+		assert.NoError(t, err)
+		assert.Empty(t, entries, "Expected no partial or temporary file to be left behind")
+	})
+
+	t.Run("UploadStopsCleanly", func(t *testing.T) {
+		started := make(chan struct{}, 1)
+		server := stallingServer(started)
+		defer server.Close()
+		useRetryProfile(t, "interrupt-upload", server.URL)
+
+		filePath := filepath.Join(t.TempDir(), "big.bin")
+		assert.NoError(t, os.WriteFile(filePath, make([]byte, 8<<20), 0644))
+
+		output := runInterrupted(t, cmd.UploadFileCmd, started, "upload-file", "bucket", filePath)
+
+		assert.Contains(t, output, "Upload of 'big.bin' interrupted after")
+		assert.Contains(t, output, "the object was not stored")
+		assert.NotContains(t, output, "uploaded successfully")
+	})
+}
@@ -0,0 +1,113 @@
+package cmd_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const lifecycleRules = `rules:
+  - id: expire-app-logs
+    prefix: app/
+    expire_days: 30
+    transitions:
+      - days: 7
+        storage_class: STANDARD_IA
+    noncurrent_expire_days: 7
+    abort_multipart_days: 3
+  - id: temporary
+    tags: {retention: temporary}
+    expire_date: 2024-02-01
+  - id: disabled
+    prefix: audit/
+    expire_days: 1
+    enabled: false
+`
+
+func TestLifecycle(t *testing.T) {
+	standIn, server := newS3StandIn(t)
+	useRetryProfile(t, "lifecycle", server.URL)
+	for _, key := range []string{"app/a.log", "app/b.log", "audit/c.log", "tmp/d.bin", "tmp/e.bin"} {
+		standIn.objects["logs/"+key] = []byte("line\n")
+	}
+	standIn.modified["logs/app/b.log"] = time.Date(2024, 2, 10, 15, 30, 0, 0, time.UTC)
+	standIn.tags["logs/tmp/d.bin"] = map[string]string{"retention": "temporary"}
+
+	t.Run("NoConfiguration", func(t *testing.T) {
+		assert.Contains(t, runCommand(t, "lifecycle", "get", "logs"), "No lifecycle configuration on bucket 'logs'.")
+		output := runCommand(t, "lifecycle", "simulate", "logs", "--date", "2024-06-01")
+		assert.Contains(t, output, "Bucket 'logs' has no lifecycle configuration: no object would expire.")
+	})
+
+	t.Run("PutYAML", func(t *testing.T) {
+		output := runWithStdin(t, strings.NewReader(lifecycleRules), "lifecycle", "put", "logs", "-")
+		assert.Contains(t, output, "Lifecycle configuration of bucket 'logs' replaced (3 rule(s)).")
+
+		raw := string(standIn.configs["logs?lifecycle"])
+		assert.Contains(t, raw, "<Filter><Prefix>app/</Prefix></Filter>")
+		assert.Contains(t, raw, "<Date>2024-02-01T00:00:00.000Z</Date>")
+		assert.Contains(t, raw, "<Status>Disabled</Status>")
+
+		output = runCommand(t, "lifecycle", "get", "logs")
+		assert.Contains(t, output, "rules:\n  - id: expire-app-logs\n    prefix: app/\n")
+		assert.Contains(t, output, "expire_date: \"2024-02-01\"")
+		assert.Contains(t, output, "enabled: false")
+		assert.Contains(t, runCommand(t, "lifecycle", "get", "logs", "--xml"), "<DaysAfterInitiation>3</DaysAfterInitiation>")
+	})
+
+	t.Run("PutInvalid", func(t *testing.T) {
+		output := runWithStdin(t, strings.NewReader("rules:\n  - id: empty\n    prefix: a/\n"), "lifecycle", "put", "logs", "-")
+		assert.Contains(t, output, "rule 1 (empty): at least one action is required")
+
+		output = runWithStdin(t, strings.NewReader("rules:\n  - expire_days: 3\n    expire_date: 2024-01-01\n"), "lifecycle", "put", "logs", "-")
+		assert.Contains(t, output, "rule 1: use either expire_days (positive) or expire_date")
+
+		output = runWithStdin(t, strings.NewReader("rules:\n  - expire_date: 01/02/2024\n"), "lifecycle", "put", "logs", "-")
+		assert.Contains(t, output, "01/02/2024")
+		assert.Contains(t, string(standIn.configs["logs?lifecycle"]), "expire-app-logs", "Expected the configuration to be left unchanged")
+	})
+
+	t.Run("Simulate", func(t *testing.T) {
+		output := runCommand(t, "lifecycle", "simulate", "logs", "--date", "2024-01-31")
+		assert.Contains(t, output, "Lifecycle of bucket 'logs' as of 2024-01-31 23:59:59 UTC:")
+		assert.Contains(t, output, "  expire      app/a.log on 2024-01-31 (rule 1 (expire-app-logs))")
+		assert.NotContains(t, output, "app/b.log", "Expected a recent object to be unaffected")
+		assert.NotContains(t, output, "audit/c.log", "Expected disabled rules to be ignored")
+		assert.Contains(t, output, "1 object(s) would expire, 0 would transition, 4 unaffected.")
+
+		output = runCommand(t, "lifecycle", "simulate", "logs", "--date", "2024-02-20")
+		assert.Contains(t, output, "  expire      tmp/d.bin on 2024-02-01 (rule 2 (temporary))")
+		assert.NotContains(t, output, "tmp/e.bin", "Expected the tag filter to apply")
+		assert.Contains(t, output, "  transition  app/b.log to STANDARD_IA on 2024-02-18 (rule 1 (expire-app-logs))")
+		assert.Contains(t, output, "2 object(s) would expire, 1 would transition, 2 unaffected.")
+
+		output = runCommand(t, "lifecycle", "simulate", "logs", "--date", "2024-02-20", "--prefix", "tmp/")
+		assert.Contains(t, output, "1 object(s) would expire, 0 would transition, 1 unaffected.")
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		assert.Contains(t, runCommand(t, "lifecycle", "delete", "logs"), "Lifecycle configuration deleted from bucket 'logs'.")
+		assert.Contains(t, runCommand(t, "lifecycle", "get", "logs"), "No lifecycle configuration on bucket 'logs'.")
+	})
+
+	t.Run("SimulateLegacyPrefix", func(t *testing.T) {
+		// Une règle créée sans Filter porte son préfixe directement sous Rule
+		standIn.configs["logs?lifecycle"] = []byte("<LifecycleConfiguration><Rule><ID>legacy</ID><Prefix>tmp/</Prefix><Status>Enabled</Status><Expiration><Days>1</Days></Expiration></Rule></LifecycleConfiguration>")
+
+		assert.Contains(t, runCommand(t, "lifecycle", "get", "logs"), "prefix: tmp/")
+		output := runCommand(t, "lifecycle", "simulate", "logs", "--date", "2024-02-20")
+		assert.Contains(t, output, "  expire      tmp/e.bin on 2024-01-02 (rule 1 (legacy))")
+		assert.NotContains(t, output, "app/a.log", "Expected the legacy prefix to apply")
+		assert.Contains(t, output, "2 object(s) would expire, 0 would transition, 3 unaffected.")
+	})
+
+	t.Run("SimulateInvalidDate", func(t *testing.T) {
+		standIn.configs["logs?lifecycle"] = []byte("<LifecycleConfiguration><Rule><ID>broken</ID><Filter><Prefix>tmp/</Prefix></Filter><Status>Enabled</Status><Expiration><Date>2024-13-45</Date></Expiration></Rule></LifecycleConfiguration>")
+
+		output := runCommand(t, "lifecycle", "simulate", "logs", "--date", "2024-02-20")
+		assert.Contains(t, output, "Error: invalid lifecycle configuration on bucket 'logs': rule 1 (broken): invalid expiration date '2024-13-45'")
+		assert.NotContains(t, output, "would expire")
+	})
+}
@@ -0,0 +1,98 @@
+package cmd_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObjectLock(t *testing.T) {
+	standIn, server := newS3StandIn(t)
+	useRetryProfile(t, "lock", server.URL)
+
+	dir := t.TempDir()
+	upload := func(t *testing.T, name string) string {
+		file := filepath.Join(dir, name)
+		os.WriteFile(file, []byte("2024;closing;1200.00"), 0o644)
+		runCommand(t, "upload-file", "vault", file, "--progress", "none")
+		history := standIn.versions["vault/"+name]
+		assert.NotEmpty(t, history, "Expected the upload to create a version")
+		return history[len(history)-1].id
+	}
+
+	t.Run("CreateBucket", func(t *testing.T) {
+		output := runCommand(t, "create-bucket", "vault", "--object-lock")
+		assert.Contains(t, output, "Bucket 'vault' created successfully.")
+		assert.Contains(t, output, "Object Lock enabled")
+		assert.Equal(t, "Enabled", standIn.versioning["vault"])
+		assert.Contains(t, runCommand(t, "retention", "get", "vault"), "Object Lock is enabled on bucket 'vault', without default retention.")
+	})
+
+	t.Run("DefaultRetention", func(t *testing.T) {
+		output := runCommand(t, "retention", "set", "vault", "--mode", "governance", "--days", "30")
+		assert.Contains(t, output, "Default retention of bucket 'vault' set to GOVERNANCE for 30 day(s).")
+		assert.Contains(t, string(standIn.configs["vault?object-lock"]), "<ObjectLockEnabled>Enabled</ObjectLockEnabled><Rule><DefaultRetention><Mode>GOVERNANCE</Mode><Days>30</Days>")
+		assert.Contains(t, runCommand(t, "retention", "get", "s3://vault"), "Default retention of bucket 'vault': GOVERNANCE for 30 day(s)")
+
+		assert.Contains(t, runCommand(t, "retention", "set", "vault", "--mode", "COMPLIANCE", "--days", "1", "--years", "1"), "takes either --days or --years")
+		assert.Contains(t, runCommand(t, "retention", "clear", "vault"), "Default retention of bucket 'vault' removed; Object Lock stays enabled.")
+	})
+
+	t.Run("BucketWithoutObjectLock", func(t *testing.T) {
+		output := runCommand(t, "retention", "get", "plain")
+		assert.Contains(t, output, "bucket 'plain' does not have Object Lock enabled; it can only be enabled at creation (create-bucket --object-lock)")
+		output = runCommand(t, "retention", "set", "plain", "--mode", "GOVERNANCE", "--days", "1")
+		assert.Contains(t, output, "bucket 'plain' does not have Object Lock enabled")
+		output = runCommand(t, "legal-hold", "on", "plain", "report.pdf")
+		assert.Contains(t, output, "bucket 'plain' does not have Object Lock enabled")
+	})
+
+	t.Run("GovernanceRetention", func(t *testing.T) {
+		versionID := upload(t, "ledger.csv")
+		output := runCommand(t, "retention", "set", "vault", "ledger.csv", "--mode", "GOVERNANCE", "--until", "2099-01-01T00:00:00Z")
+		assert.Contains(t, output, "Object 'ledger.csv' in bucket 'vault' retained in GOVERNANCE mode until 2099-01-01T00:00:00Z.")
+		output = runCommand(t, "retention", "get", "s3://vault/ledger.csv")
+		assert.Contains(t, output, "Retention:  GOVERNANCE until 2099-01-01T00:00:00Z")
+		assert.Contains(t, output, "Legal hold: OFF")
+
+		output = runCommand(t, "delete-object", "vault", "ledger.csv", "--version-id", versionID)
+		assert.Contains(t, output, "Cannot delete version '"+versionID+"' of object 'ledger.csv' from bucket 'vault': it is retained in GOVERNANCE mode until 2099-01-01T00:00:00Z; retry with --bypass-governance")
+		assert.NotEmpty(t, standIn.versions["vault/ledger.csv"])
+
+		// La suspension légale bloque la suppression, même avec le contournement
+		assert.Contains(t, runCommand(t, "legal-hold", "on", "vault", "ledger.csv"), "Legal hold placed on object 'ledger.csv' in bucket 'vault'.")
+		assert.Contains(t, runCommand(t, "retention", "get", "vault", "ledger.csv"), "Legal hold: ON")
+		output = runCommand(t, "delete-object", "vault", "ledger.csv", "--version-id", versionID, "--bypass-governance")
+		assert.Contains(t, output, "it is under legal hold; remove it with legal-hold off first")
+
+		assert.Contains(t, runCommand(t, "legal-hold", "off", "s3://vault/ledger.csv"), "Legal hold removed from object 'ledger.csv' in bucket 'vault'.")
+		output = runCommand(t, "delete-object", "vault", "ledger.csv", "--version-id", versionID, "--bypass-governance")
+		assert.Contains(t, output, "Successfully deleted version '"+versionID+"' of object 'ledger.csv' from bucket 'vault'.")
+		assert.Empty(t, standIn.versions["vault/ledger.csv"])
+	})
+
+	t.Run("ComplianceRetention", func(t *testing.T) {
+		versionID := upload(t, "audit.csv")
+		runCommand(t, "retention", "set", "vault", "audit.csv", "--mode", "COMPLIANCE", "--until", "2099-06-30T00:00:00Z")
+
+		output := runCommand(t, "retention", "set", "vault", "audit.csv", "--mode", "COMPLIANCE", "--until", "2098-01-01T00:00:00Z", "--bypass-governance")
+		assert.Contains(t, output, "a COMPLIANCE retention cannot be shortened or removed")
+		output = runCommand(t, "retention", "clear", "vault", "audit.csv", "--bypass-governance")
+		assert.Contains(t, output, "a COMPLIANCE retention cannot be shortened or removed")
+
+		// L'extension reste possible
+		output = runCommand(t, "retention", "set", "vault", "audit.csv", "--mode", "COMPLIANCE", "--until", "2100-01-01T00:00:00Z")
+		assert.Contains(t, output, "retained in COMPLIANCE mode until 2100-01-01T00:00:00Z.")
+
+		output = runCommand(t, "delete-object", "vault", "audit.csv", "--version-id", versionID, "--bypass-governance")
+		assert.Contains(t, output, "it is retained in COMPLIANCE mode until 2100-01-01T00:00:00Z and cannot be deleted before")
+	})
+
+	t.Run("InvalidOptions", func(t *testing.T) {
+		assert.Contains(t, runCommand(t, "retention", "set", "vault", "audit.csv", "--mode", "WORM", "--until", "2099-01-01"), "unsupported retention mode 'WORM' (expected GOVERNANCE or COMPLIANCE)")
+		assert.Contains(t, runCommand(t, "retention", "set", "vault", "audit.csv", "--mode", "GOVERNANCE", "--until", "2020-01-01T00:00:00Z"), "retain-until date 2020-01-01T00:00:00Z is not in the future")
+		assert.Contains(t, runCommand(t, "retention", "set", "vault", "audit.csv", "--mode", "GOVERNANCE", "--days", "3"), "the retention of an object takes a retain-until date (--until)")
+	})
+}
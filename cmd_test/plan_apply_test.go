@@ -0,0 +1,150 @@
+package cmd_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AlizeaMassePlat/plateforme-mycli/cmd"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+const bucketsYAML = `buckets:
+  - name: invoices-2024
+    versioning: true
+    encryption:
+      sse: AES256
+    policy:
+      Version: 2012-10-17
+      Statement:
+        - Sid: ReadReports
+          Effect: Allow
+          Principal: "*"
+          Action: s3:GetObject
+          Resource: arn:aws:s3:::invoices-2024/reports/*
+    cors: []
+    tags:
+      team: finance
+  - name: reports-paris
+    region: eu-west-3
+    lifecycle:
+      - id: purge-tmp
+        prefix: tmp/
+        expire_days: 7
+    tags:
+      env: prod
+`
+
+func TestPlanApply(t *testing.T) {
+	standIn, server := newS3StandIn(t)
+	useRetryProfile(t, "declarative", server.URL)
+	viper.Set("profiles.declarative.access_key", "AKIADECLARATIVE")
+	viper.Set("profiles.declarative.secret_key", "declarative-secret")
+
+	runCommand(t, "create-bucket", "invoices-2024")
+	runCommand(t, "create-bucket", "legacy")
+	standIn.cors["invoices-2024"] = cmd.CORSConfiguration{Rules: []cmd.CORSRule{{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET"}}}}
+	standIn.tags["invoices-2024/"] = map[string]string{"team": "ops"}
+
+	dir := t.TempDir()
+	writeFile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		os.WriteFile(path, []byte(content), 0o644)
+		return path
+	}
+	config := writeFile("buckets.yaml", bucketsYAML)
+
+	t.Run("Plan", func(t *testing.T) {
+		before := len(standIn.requestLog())
+		output := runCommand(t, "plan", config)
+		assert.Contains(t, output, "~ bucket 'invoices-2024' will be updated")
+		assert.Contains(t, output, "    + versioning: Enabled")
+		assert.Contains(t, output, "    + encryption: SSE-S3 (AES256)")
+		assert.Contains(t, output, "    + policy: 1 statement(s)")
+		assert.Contains(t, output, `+   "Version": "2012-10-17"`, "Expected the policy document to be shown, with its version kept as a string")
+		assert.Contains(t, output, "    - cors: 1 rule(s) (removed)")
+		assert.Contains(t, output, "    ~ tags: team=ops -> team=finance")
+		assert.Contains(t, output, "+ bucket 'reports-paris' will be created in eu-west-3")
+		assert.Contains(t, output, "    + lifecycle: 1 rule(s), 1 enabled")
+		assert.Contains(t, output, "  bucket 'legacy' is not declared (kept; use --prune to delete it)")
+		assert.Contains(t, output, "Plan: 1 to create, 1 to update, 0 to delete, 0 up to date.")
+		for _, request := range standIn.requestLog()[before:] {
+			assert.True(t, strings.HasPrefix(request, "GET "), "Expected plan to only read, got %s", request)
+		}
+
+		assert.Contains(t, runCommand(t, "plan", config, "--prune"), "- bucket 'legacy' will be deleted (not declared)")
+	})
+
+	t.Run("PruneNeedsConfirmation", func(t *testing.T) {
+		// Sans terminal, les suppressions doivent être confirmées par --yes
+		noTerminal, input, _ := os.Pipe()
+		input.Close()
+		defer noTerminal.Close()
+		output := runWithStdin(t, noTerminal, "apply", config, "--prune")
+		assert.Contains(t, output, "Error: --prune would delete 1 bucket(s): pass --yes to confirm, or run apply from a terminal; nothing was changed")
+
+		output = runWithStdin(t, strings.NewReader("no\n"), "apply", config, "--prune")
+		assert.Contains(t, output, "Delete 1 bucket(s) not declared in the configuration? Type 'yes' to confirm: ")
+		assert.Contains(t, output, "Apply cancelled: nothing was changed.")
+		assert.Contains(t, standIn.buckets, "legacy")
+		assert.NotContains(t, standIn.buckets, "reports-paris")
+		assert.NotEqual(t, "Enabled", standIn.versioning["invoices-2024"])
+	})
+
+	t.Run("Apply", func(t *testing.T) {
+		output := runCommand(t, "apply", config, "--prune", "--yes")
+		assert.Contains(t, output, "Bucket 'invoices-2024': versioning set to Enabled.")
+		assert.Contains(t, output, "Bucket 'invoices-2024': cors removed.")
+		assert.Contains(t, output, "Bucket 'reports-paris' created.")
+		assert.Contains(t, output, "Bucket 'legacy' deleted.")
+		assert.Contains(t, output, "Apply complete: 9 change(s) applied.")
+
+		assert.Equal(t, "Enabled", standIn.versioning["invoices-2024"])
+		assert.Contains(t, string(standIn.configs["invoices-2024?encryption"]), "<SSEAlgorithm>AES256</SSEAlgorithm>")
+		assert.Contains(t, string(standIn.policies["invoices-2024"]), `"Sid":"ReadReports"`)
+		assert.NotContains(t, standIn.cors, "invoices-2024")
+		assert.Equal(t, map[string]string{"team": "finance"}, standIn.tags["invoices-2024/"])
+		assert.Contains(t, string(standIn.configs["reports-paris?create"]), "<LocationConstraint>eu-west-3</LocationConstraint>")
+		assert.Contains(t, string(standIn.configs["reports-paris?lifecycle"]), "<Prefix>tmp/</Prefix>")
+		assert.NotContains(t, standIn.buckets, "legacy")
+
+		// Une fois appliqué, le fichier ne prévoit plus aucune modification
+		assert.Contains(t, runCommand(t, "plan", config), "Plan: 0 to create, 0 to update, 0 to delete, 2 up to date.")
+		assert.Contains(t, runCommand(t, "apply", config), "Nothing to apply: the buckets match the configuration.")
+	})
+
+	t.Run("StopsOnError", func(t *testing.T) {
+		runCommand(t, "create-bucket", "archives")
+		standIn.objects["archives/2019.zip"] = []byte("zip")
+		output := runWithStdin(t, strings.NewReader("yes\n"), "apply", config, "--prune")
+		assert.Contains(t, output, "cannot delete bucket 'archives': bucket 'archives' is not empty; delete its objects (and versions) first")
+		assert.Contains(t, output, "0 of 1 change(s) applied before the error; fix it and run apply again.")
+	})
+
+	t.Run("Conflict", func(t *testing.T) {
+		moved := writeFile("moved.yaml", "buckets:\n  - name: invoices-2024\n    region: eu-west-1\n    versioning: false\n")
+		output := runCommand(t, "apply", moved)
+		assert.Contains(t, output, "! bucket 'invoices-2024' cannot be applied: it is in region us-east-1, not eu-west-1; a bucket cannot be moved to another region")
+		assert.Contains(t, output, "Error: 1 bucket(s) in conflict; nothing was changed")
+		assert.Equal(t, "Enabled", standIn.versioning["invoices-2024"])
+	})
+
+	t.Run("InvalidFile", func(t *testing.T) {
+		cases := map[string]string{
+			"buckets:\n  - name: invoices-2024\n    versionning: true\n":                       "field versionning not found",
+			"buckets:\n  - name: Invoices\n":                                                   "invalid bucket name 'Invoices'",
+			"buckets:\n  - name: invoices-2024\n  - name: invoices-2024\n":                     "bucket 'invoices-2024' is declared twice",
+			"buckets:\n  - name: invoices-2024\n    cors:\n      - origins: [\"*\"]\n":         "bucket 'invoices-2024': rule 1: at least one method is required",
+			"buckets:\n  - name: invoices-2024\n    encryption:\n      sse: DES\n":             "bucket 'invoices-2024': encryption: unsupported sse 'DES' (expected AES256 or aws:kms)",
+			"buckets:\n  - name: invoices-2024\n    policy: '{\"Version\": \"2012-10-17\"}'\n": "bucket 'invoices-2024': invalid policy:",
+			"buckets: []\n": "declares no buckets",
+		}
+		for content, message := range cases {
+			before := len(standIn.requestLog())
+			assert.Contains(t, runCommand(t, "plan", writeFile("invalid.yaml", content)), message)
+			assert.Len(t, standIn.requestLog(), before, "Expected an invalid file to be rejected before any request")
+		}
+	})
+}
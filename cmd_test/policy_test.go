@@ -0,0 +1,73 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicy(t *testing.T) {
+	standIn, server := newS3StandIn(t)
+	useRetryProfile(t, "policy", server.URL)
+
+	t.Run("Generate", func(t *testing.T) {
+		output := runCommand(t, "policy", "generate", "read-only", "reports", "--principal", "arn:aws:iam::123456789012:role/analytics", "--prefix", "2024/")
+
+		var policy map[string]any
+		assert.NoError(t, json.Unmarshal([]byte(output), &policy), "Expected a JSON policy")
+		assert.Contains(t, output, `"Resource": [
+        "arn:aws:s3:::reports/2024/*"
+      ]`)
+		assert.Contains(t, output, `"s3:prefix": "2024/*"`)
+
+		output = runCommand(t, "policy", "generate", "read-only", "reports")
+		assert.Contains(t, output, "pattern 'read-only' requires at least one --principal")
+	})
+
+	t.Run("PutRejectsInvalidPolicy", func(t *testing.T) {
+		invalid := `{
+  "Version": "2012-10-17",
+  "Statement": [
+    {"Effect": "Permit", "Principal": "*", "Action": "s3:GetObjekt", "Resource": "arn:aws:s3:::other-bucket/*"}
+  ]
+}`
+		output := runPolicyPut(t, invalid, "reports")
+		assert.Contains(t, output, "the policy is not valid for bucket 'reports'")
+		assert.Contains(t, output, "statement 1: Effect must be Allow or Deny (got 'Permit')")
+		assert.Contains(t, output, "statement 1: unknown action 's3:GetObjekt'")
+		assert.Contains(t, output, "statement 1: resource 'arn:aws:s3:::other-bucket/*' does not target bucket 'reports'")
+		assert.NotContains(t, standIn.requestLog(), "PUT /reports/?policy=", "Expected an invalid policy not to be sent")
+
+		output = runPolicyPut(t, `{"Version": "2012-10-17", "Statement": [], "Extra": true}`, "reports")
+		assert.Contains(t, output, `unknown field "Extra"`)
+	})
+
+	t.Run("PutShowsDiff", func(t *testing.T) {
+		public := runCommand(t, "policy", "generate", "public-read", "reports", "--prefix", "public/")
+
+		output := runPolicyPut(t, public, "reports")
+		assert.Contains(t, output, "Bucket 'reports' has no policy; the new policy will be:")
+		assert.Contains(t, output, `+   "Version": "2012-10-17"`)
+		assert.Contains(t, output, "Policy applied to bucket 'reports'.")
+
+		wider := strings.Replace(public, "public/*", "*", 1)
+		output = runPolicyPut(t, wider, "reports", "--dry-run")
+		assert.Contains(t, output, "Changes to the policy of bucket 'reports':")
+		assert.Contains(t, output, `-         "arn:aws:s3:::reports/public/*"`+"\n"+`+         "arn:aws:s3:::reports/*"`)
+		assert.Contains(t, output, "Dry run: the policy was not changed.")
+
+		assert.Contains(t, runPolicyPut(t, public, "reports"), "The policy of bucket 'reports' is already up to date.")
+	})
+
+	t.Run("GetAndDelete", func(t *testing.T) {
+		assert.Contains(t, runCommand(t, "policy", "get", "reports"), `"Sid": "PublicRead"`)
+		assert.Contains(t, runCommand(t, "policy", "delete", "reports"), "Policy deleted from bucket 'reports'.")
+		assert.Contains(t, runCommand(t, "policy", "get", "reports"), "No policy on bucket 'reports'.")
+	})
+}
+
+func runPolicyPut(t *testing.T, policy string, args ...string) string {
+	return runWithStdin(t, strings.NewReader(policy), append([]string{"policy", "put", args[0], "-"}, args[1:]...)...)
+}
@@ -0,0 +1,85 @@
+package cmd_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/AlizeaMassePlat/plateforme-mycli/cmd"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+// PostPolicyDocument représente le document de politique décodé
+type PostPolicyDocument struct {
+	Expiration string        `json:"expiration"`
+	Conditions []interface{} `json:"conditions"`
+}
+
+// postPolicySignature recalcule la signature SigV4 d'une politique POST
+func postPolicySignature(secret, date, region, policy string) string {
+	key := []byte("AWS4" + secret)
+	for _, part := range []string{date, region, "s3", "aws4_request", policy} {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(part))
+		key = mac.Sum(nil)
+	}
+	return hex.EncodeToString(key)
+}
+
+func TestPresignPostCmd(t *testing.T) {
+	viper.Set("s3.api_url", "http://localhost:9090")
+	viper.Set("s3.access_key", "AKIAPOSTTEST")
+	viper.Set("s3.secret_key", "post-secret")
+	t.Cleanup(func() {
+		viper.Set("s3.access_key", "")
+		viper.Set("s3.secret_key", "")
+	})
+
+	t.Run("PolicyAsJSON", func(t *testing.T) {
+		output := runCommand(t, "presign-post", "uploads-bucket", "--key-prefix", "user/42/",
+			"--content-length-range", "1:1048576", "--content-type", "image/", "--meta", "owner=alice")
+
+		var form cmd.PostPolicyForm
+		assert.NoError(t, json.Unmarshal([]byte(output), &form), "Expected JSON output")
+		assert.Equal(t, "http://localhost:9090/uploads-bucket/", form.URL)
+		assert.Equal(t, "user/42/${filename}", form.Fields["key"])
+		assert.Equal(t, "alice", form.Fields["x-amz-meta-owner"])
+		assert.Equal(t, "AWS4-HMAC-SHA256", form.Fields["x-amz-algorithm"])
+
+		// Le document de politique contient toutes les conditions demandées
+		raw, err := base64.StdEncoding.DecodeString(form.Fields["policy"])
+		assert.NoError(t, err)
+		var policy PostPolicyDocument
+		assert.NoError(t, json.Unmarshal(raw, &policy))
+		conditions, _ := json.Marshal(policy.Conditions)
+		assert.Contains(t, string(conditions), `{"bucket":"uploads-bucket"}`)
+		assert.Contains(t, string(conditions), `["starts-with","$key","user/42/"]`)
+		assert.Contains(t, string(conditions), `["content-length-range",1,1048576]`)
+		assert.Contains(t, string(conditions), `["starts-with","$Content-Type","image/"]`)
+		assert.Contains(t, string(conditions), `{"x-amz-meta-owner":"alice"}`)
+
+		// La signature correspond à la politique encodée
+		credential := strings.Split(form.Fields["x-amz-credential"], "/")
+		assert.Equal(t, "AKIAPOSTTEST", credential[0])
+		expected := postPolicySignature("post-secret", credential[1], credential[2], form.Fields["policy"])
+		assert.Equal(t, expected, form.Fields["x-amz-signature"])
+	})
+
+	t.Run("PolicyAsHTML", func(t *testing.T) {
+		output := runCommand(t, "presign-post", "uploads-bucket", "--content-type", "text/plain", "--format", "html")
+		assert.Contains(t, output, `<form action="http://localhost:9090/uploads-bucket/" method="post" enctype="multipart/form-data">`)
+		assert.Contains(t, output, `<input type="hidden" name="Content-Type" value="text/plain" />`)
+		assert.Contains(t, output, `name="x-amz-signature"`)
+		assert.Less(t, strings.Index(output, `name="policy"`), strings.Index(output, `name="file"`), "Expected the file field to come last")
+	})
+
+	t.Run("InvalidLengthRange", func(t *testing.T) {
+		output := runCommand(t, "presign-post", "uploads-bucket", "--content-length-range", "100:10")
+		assert.Contains(t, output, "invalid content length range '100:10'")
+	})
+}
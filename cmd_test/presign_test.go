@@ -0,0 +1,74 @@
+package cmd_test
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPresignCmd(t *testing.T) {
+	viper.Set("s3.api_url", "http://localhost:9090")
+	viper.Set("s3.access_key", "AKIAPRESIGNTEST")
+	viper.Set("s3.secret_key", "presign-secret")
+	t.Cleanup(func() {
+		viper.Set("s3.access_key", "")
+		viper.Set("s3.secret_key", "")
+	})
+
+	// Génération d'une URL GET avec Content-Disposition
+	t.Run("PresignGet", func(t *testing.T) {
+		output := strings.TrimSpace(runCommand(t, "presign", "presign-bucket", "reports/q1.pdf", "--expires", "1h",
+			"--response-content-disposition", "attachment; filename=q1.pdf"))
+
+		u, err := url.Parse(output)
+		assert.NoError(t, err, "Expected a valid URL")
+		assert.Equal(t, "/presign-bucket/reports/q1.pdf", u.Path)
+		query := u.Query()
+		assert.Equal(t, "AWS4-HMAC-SHA256", query.Get("X-Amz-Algorithm"))
+		assert.Equal(t, "3600", query.Get("X-Amz-Expires"))
+		assert.Equal(t, "host", query.Get("X-Amz-SignedHeaders"))
+		assert.True(t, strings.HasPrefix(query.Get("X-Amz-Credential"), "AKIAPRESIGNTEST/"))
+		assert.Equal(t, "attachment; filename=q1.pdf", query.Get("response-content-disposition"))
+		assert.Len(t, query.Get("X-Amz-Signature"), 64)
+
+		verify := runCommand(t, "presign", "--verify", output)
+		assert.Contains(t, verify, "Signed by:  AKIAPRESIGNTEST (GET, region us-east-1)")
+		assert.Contains(t, verify, "Presigned URL is valid for")
+	})
+
+	// La méthode signée est retrouvée lors de la vérification
+	t.Run("PresignPut", func(t *testing.T) {
+		output := strings.TrimSpace(runCommand(t, "presign", "presign-bucket", "upload.bin", "--method", "put"))
+		verify := runCommand(t, "presign", "--verify", output)
+		assert.Contains(t, verify, "(PUT, region us-east-1)")
+	})
+
+	// Une URL modifiée n'est plus valide
+	t.Run("TamperedURL", func(t *testing.T) {
+		output := strings.TrimSpace(runCommand(t, "presign", "presign-bucket", "file.txt"))
+		tampered := strings.Replace(output, "/file.txt", "/other.txt", 1)
+		verify := runCommand(t, "presign", "--verify", tampered)
+		assert.Contains(t, verify, "Presigned URL is NOT valid: signature does not match")
+	})
+
+	// URL signée avec une autre clé d'accès
+	t.Run("ForeignAccessKey", func(t *testing.T) {
+		output := strings.TrimSpace(runCommand(t, "presign", "presign-bucket", "file.txt"))
+		viper.Set("s3.access_key", "AKIAOTHERKEY")
+		defer viper.Set("s3.access_key", "AKIAPRESIGNTEST")
+		verify := runCommand(t, "presign", "--verify", output)
+		assert.Contains(t, verify, "signed with access key 'AKIAPRESIGNTEST' but the profile uses 'AKIAOTHERKEY'")
+	})
+
+	// URL expirée
+	t.Run("ExpiredURL", func(t *testing.T) {
+		output := strings.TrimSpace(runCommand(t, "presign", "presign-bucket", "file.txt", "--expires", "1s"))
+		time.Sleep(2 * time.Second)
+		verify := runCommand(t, "presign", "--verify", output)
+		assert.Contains(t, verify, "signature is valid but the URL EXPIRED")
+	})
+}
@@ -0,0 +1,115 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/AlizeaMassePlat/plateforme-mycli/cmd"
+	"github.com/stretchr/testify/assert"
+)
+
+// serveObject répond avec un objet de 64 Kio
+func serveObject(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Length", "65536")
+	w.Write([]byte(strings.Repeat("p", 64*1024)))
+}
+
+// runDownload télécharge l'objet servi par handler avec les options de progression données
+func runDownload(t *testing.T, profile string, handler http.HandlerFunc, progressArgs ...string) string {
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	useRetryProfile(t, profile, server.URL)
+	t.Cleanup(func() {
+		cmd.RootCmd.PersistentFlags().Set("progress", "auto")
+		cmd.RootCmd.PersistentFlags().Set("progress-fd", "2")
+	})
+
+	return CaptureOutput(func() {
+		cmd.RootCmd.SetArgs(append([]string{"download-file", "bucket", "progress.bin", t.TempDir()}, progressArgs...))
+		err := cmd.RootCmd.Execute()
+		assert.NoError(t, err)
+	})
+}
+
+// progressEvents extrait les événements JSON de la sortie
+func progressEvents(t *testing.T, output string) []cmd.ProgressEvent {
+	var events []cmd.ProgressEvent
+	for _, line := range strings.Split(output, "\n") {
+		if !strings.HasPrefix(line, "{") {
+			continue
+		}
+		var event cmd.ProgressEvent
+		assert.NoError(t, json.Unmarshal([]byte(line), &event), "Expected one JSON event per line: %s", line)
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestProgress(t *testing.T) {
+	t.Run("BarShowsBytesRateAndETA", func(t *testing.T) {
+		output := runDownload(t, "progress-bar", serveObject, "--progress", "bar")
+
+		assert.Contains(t, output, "progress.bin")
+		assert.Contains(t, output, "[##############################] 100.0%")
+		assert.Contains(t, output, "64.0 KiB / 64.0 KiB")
+		assert.Contains(t, output, "/s  done")
+		assert.Contains(t, output, "Download completed successfully.")
+	})
+
+	t.Run("DisabledWhenNotATerminal", func(t *testing.T) {
+		output := runDownload(t, "progress-auto", serveObject, "--progress", "auto")
+
+		assert.NotContains(t, output, "\r", "Expected no redrawn bar when stdout is not a terminal")
+		assert.Contains(t, output, "Download completed successfully.")
+	})
+
+	t.Run("InvalidMode", func(t *testing.T) {
+		output := runDownload(t, "progress-invalid", serveObject, "--progress", "fancy")
+
+		assert.Contains(t, output, "invalid --progress 'fancy' (expected auto, bar, json or none)")
+		assert.NotContains(t, output, "Download completed successfully.")
+	})
+
+	t.Run("JSONEventStream", func(t *testing.T) {
+		var calls int32
+		output := runDownload(t, "progress-json", func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			serveObject(w, r)
+		}, "--progress", "json", "--progress-fd", "1")
+
+		events := progressEvents(t, output)
+		if assert.GreaterOrEqual(t, len(events), 3) {
+			assert.Equal(t, "started", events[0].Event)
+			assert.Equal(t, "progress.bin", events[0].File)
+			assert.False(t, events[0].Time.IsZero(), "Expected events to be timestamped")
+
+			assert.Equal(t, "retried", events[1].Event)
+			assert.Equal(t, 2, events[1].Attempt)
+			assert.Contains(t, events[1].Error, "503")
+
+			last := events[len(events)-1]
+			assert.Equal(t, "finished", last.Event)
+			assert.Equal(t, int64(65536), last.Bytes)
+			assert.Equal(t, int64(65536), last.Total)
+		}
+		assert.NotContains(t, output, "\r", "Expected no bar in json mode")
+	})
+
+	t.Run("JSONFailedEvent", func(t *testing.T) {
+		output := runDownload(t, "progress-json-failed", http.NotFound, "--progress", "json", "--progress-fd", "1")
+
+		events := progressEvents(t, output)
+		if assert.Len(t, events, 2) {
+			assert.Equal(t, "started", events[0].Event)
+			assert.Equal(t, "failed", events[1].Event)
+			assert.Contains(t, events[1].Error, "404 Not Found")
+		}
+	})
+}
@@ -0,0 +1,65 @@
+package cmd_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPutCmd(t *testing.T) {
+	t.Run("StdinMultipart", func(t *testing.T) {
+		standIn, server := newS3StandIn(t)
+		useRetryProfile(t, "put-multipart", server.URL)
+
+		// 11 Mio en parties de 5 Mio : 3 parties, la dernière plus petite
+		data := make([]byte, 11<<20)
+		rand.Read(data)
+		output := runWithStdin(t, bytes.NewReader(data), "put", "-", "s3://put-bucket/backups/data.bin", "--part-size", "5MiB", "--concurrency", "2")
+
+		assert.Contains(t, output, "Uploaded 11534336 bytes to s3://put-bucket/backups/data.bin (3 part(s)).")
+		stored, ok := standIn.object("put-bucket/backups/data.bin")
+		assert.True(t, ok, "Expected the multipart upload to be completed")
+		assert.True(t, bytes.Equal(data, stored), "Expected the parts to be reassembled in order")
+		assert.Contains(t, standIn.requestLog(), "POST /put-bucket/backups/data.bin?uploads=")
+	})
+
+	t.Run("StdinSinglePart", func(t *testing.T) {
+		standIn, server := newS3StandIn(t)
+		useRetryProfile(t, "put-single", server.URL)
+
+		output := runWithStdin(t, strings.NewReader("hello from stdin"), "put", "-", "s3://put-bucket/hello.txt", "--content-type", "text/plain")
+
+		assert.Contains(t, output, "Uploaded 16 bytes to s3://put-bucket/hello.txt (1 part(s)).")
+		stored, _ := standIn.object("put-bucket/hello.txt")
+		assert.Equal(t, "hello from stdin", string(stored))
+		assert.Equal(t, []string{"PUT /put-bucket/hello.txt"}, standIn.requestLog(), "Expected a plain PUT without multipart upload")
+		assert.Equal(t, "text/plain", standIn.headers["put-bucket/hello.txt"].Get("Content-Type"))
+	})
+
+	t.Run("AbortOnPartFailure", func(t *testing.T) {
+		standIn, server := newS3StandIn(t)
+		standIn.failPart = 2
+		useRetryProfile(t, "put-abort", server.URL)
+
+		output := runWithStdin(t, bytes.NewReader(make([]byte, 12<<20)), "put", "-", "s3://put-bucket/broken.bin", "--part-size", "5MiB")
+
+		assert.Contains(t, output, "failed to upload part 2: InternalError")
+		assert.Contains(t, output, "(multipart upload aborted)")
+		assert.Equal(t, []string{"upload-1"}, standIn.aborted, "Expected the multipart upload to be aborted")
+		_, ok := standIn.object("put-bucket/broken.bin")
+		assert.False(t, ok, "Expected no object after an aborted upload")
+	})
+
+	t.Run("KeyRequiredForStdin", func(t *testing.T) {
+		output := runWithStdin(t, strings.NewReader("data"), "put", "-", "s3://put-bucket/")
+		assert.Contains(t, output, "an object key is required when reading standard input")
+	})
+
+	t.Run("PartSizeTooSmall", func(t *testing.T) {
+		output := runWithStdin(t, strings.NewReader("data"), "put", "-", "s3://put-bucket/key", "--part-size", "1MiB")
+		assert.Contains(t, output, "--part-size must be between 5.0 MiB and 5.0 GiB")
+	})
+}
@@ -0,0 +1,69 @@
+package cmd_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPointInTime(t *testing.T) {
+	standIn, server := newS3StandIn(t)
+	useRetryProfile(t, "point-in-time", server.URL)
+
+	// Les versions du stand-in sont datées d'une minute en une minute à partir du 2024-01-01 00:00 UTC :
+	// à 00:01:30, index.html vaut v1 et style.css v2 ; ensuite index.html est écrasé (v3),
+	// style.css supprimé (v4) et new.html créé (v5)
+	runCommand(t, "versioning", "enable", "site")
+	runWithStdin(t, strings.NewReader("<h1>home</h1>"), "put", "-", "s3://site/www/index.html")
+	runWithStdin(t, strings.NewReader("body{}"), "put", "-", "s3://site/www/style.css")
+	runWithStdin(t, strings.NewReader("oops"), "put", "-", "s3://site/www/index.html")
+	runCommand(t, "delete-object", "site", "www/style.css")
+	runWithStdin(t, strings.NewReader("<h1>new</h1>"), "put", "-", "s3://site/www/new.html")
+	const asOf = "2024-01-01T00:01:30Z"
+
+	t.Run("ListAsOf", func(t *testing.T) {
+		output := runCommand(t, "list-object", "site", "--as-of", asOf)
+		assert.Contains(t, output, "Objects as of 2024-01-01T00:01:30Z:")
+		assert.Contains(t, output, "- [2024-01-01 00:00:00] 13B www/index.html (version: v1)")
+		assert.Contains(t, output, "- [2024-01-01 00:01:00] 6B www/style.css (version: v2)")
+		assert.NotContains(t, output, "new.html")
+	})
+
+	t.Run("InvalidTimestamp", func(t *testing.T) {
+		output := runCommand(t, "list-object", "site", "--as-of", "yesterday")
+		assert.Contains(t, output, "invalid timestamp 'yesterday'")
+	})
+
+	t.Run("DryRun", func(t *testing.T) {
+		before := len(standIn.requestLog())
+		output := runCommand(t, "restore-prefix", "site", "www/", "--as-of", asOf, "--dry-run", "--delete-new")
+
+		assert.Contains(t, output, "restore   www/index.html (version v1 replaces v3)")
+		assert.Contains(t, output, "recreate  www/style.css (version v2, deleted since)")
+		assert.Contains(t, output, "delete    www/new.html (created after, current version v5)")
+		assert.Contains(t, output, "Plan: 2 to restore, 1 to delete, 0 unchanged.")
+		assert.Contains(t, output, "Dry run: no changes made.")
+		for _, request := range standIn.requestLog()[before:] {
+			assert.True(t, strings.HasPrefix(request, "GET "), "Expected a dry run to only read, got %s", request)
+		}
+	})
+
+	t.Run("Restore", func(t *testing.T) {
+		output := runCommand(t, "restore-prefix", "site", "www/", "--as-of", asOf)
+
+		assert.Contains(t, output, "Plan: 2 to restore, 0 to delete, 1 unchanged.")
+		assert.Contains(t, output, "Restore completed: 2 change(s) applied.")
+		index, _ := standIn.object("site/www/index.html")
+		assert.Equal(t, "<h1>home</h1>", string(index))
+		style, _ := standIn.object("site/www/style.css")
+		assert.Equal(t, "body{}", string(style))
+		_, kept := standIn.object("site/www/new.html")
+		assert.True(t, kept, "Expected keys created afterwards to be kept without --delete-new")
+
+		output = runCommand(t, "restore-prefix", "site", "www/", "--as-of", asOf, "--delete-new")
+		assert.Contains(t, output, "Plan: 0 to restore, 1 to delete, 2 unchanged.")
+		_, kept = standIn.object("site/www/new.html")
+		assert.False(t, kept, "Expected --delete-new to delete keys created afterwards")
+	})
+}
@@ -0,0 +1,181 @@
+package cmd_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/AlizeaMassePlat/plateforme-mycli/cmd"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+const retryBucketList = "<ListAllMyBucketsResult><Buckets><Bucket><Name>retried-bucket</Name><CreationDate>2024-09-17T08:58:31Z</CreationDate></Bucket></Buckets></ListAllMyBucketsResult>"
+
+// flakyServer échoue avec le gestionnaire fail pour les `failures` premières requêtes
+func flakyServer(failures int32, fail http.HandlerFunc) (*httptest.Server, *int32) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= failures {
+			fail(w, r)
+			return
+		}
+		fmt.Fprint(w, retryBucketList)
+	}))
+	return server, &calls
+}
+
+func useRetryProfile(t *testing.T, name, apiURL string) {
+	viper.Set("profiles."+name+".api_url", apiURL)
+	viper.Set("profiles."+name+".retry_base_delay", "1ms")
+	viper.Set("profiles."+name+".retry_max_delay", "10ms")
+	t.Setenv("MYCLI_PROFILE", name)
+}
+
+func TestRetries(t *testing.T) {
+	t.Run("RetrySlowDown", func(t *testing.T) {
+		server, calls := flakyServer(2, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, "<Error><Code>SlowDown</Code><Message>Please reduce your request rate.</Message></Error>")
+		})
+		defer server.Close()
+		useRetryProfile(t, "retry-slowdown", server.URL)
+
+		assert.Contains(t, listBucketsOutput(t), "retried-bucket")
+		assert.Equal(t, int32(3), atomic.LoadInt32(calls), "Expected two retries before success")
+	})
+
+	t.Run("RetryConnectionReset", func(t *testing.T) {
+		server, calls := flakyServer(1, func(w http.ResponseWriter, r *http.Request) {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				conn.Close()
+			}
+		})
+		defer server.Close()
+		useRetryProfile(t, "retry-reset", server.URL)
+
+		assert.Contains(t, listBucketsOutput(t), "retried-bucket")
+		assert.Equal(t, int32(2), atomic.LoadInt32(calls))
+	})
+
+	t.Run("HonorRetryAfter", func(t *testing.T) {
+		server, calls := flakyServer(1, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		})
+		defer server.Close()
+		useRetryProfile(t, "retry-after", server.URL)
+		viper.Set("profiles.retry-after.retry_max_delay", "5s")
+
+		start := time.Now()
+		assert.Contains(t, listBucketsOutput(t), "retried-bucket")
+		assert.GreaterOrEqual(t, time.Since(start), time.Second, "Expected the Retry-After delay to be honored")
+		assert.Equal(t, int32(2), atomic.LoadInt32(calls))
+	})
+
+	t.Run("CapRetryAfter", func(t *testing.T) {
+		server, calls := flakyServer(1, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Retry-After", "86400")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		})
+		defer server.Close()
+		useRetryProfile(t, "retry-after-cap", server.URL)
+
+		start := time.Now()
+		assert.Contains(t, listBucketsOutput(t), "retried-bucket")
+		assert.Less(t, time.Since(start), time.Second, "Expected Retry-After to be capped at retry_max_delay")
+		assert.Equal(t, int32(2), atomic.LoadInt32(calls))
+	})
+
+	t.Run("GiveUpAfterMaxAttempts", func(t *testing.T) {
+		server, calls := flakyServer(10, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+		defer server.Close()
+		useRetryProfile(t, "retry-exhausted", server.URL)
+		viper.Set("profiles.retry-exhausted.max_attempts", 4)
+
+		assert.Contains(t, listBucketsOutput(t), "status code 500")
+		assert.Equal(t, int32(4), atomic.LoadInt32(calls))
+	})
+
+	t.Run("NoRetryOnClientError", func(t *testing.T) {
+		server, calls := flakyServer(10, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		})
+		defer server.Close()
+		useRetryProfile(t, "retry-forbidden", server.URL)
+
+		assert.Contains(t, listBucketsOutput(t), "status code 403")
+		assert.Equal(t, int32(1), atomic.LoadInt32(calls))
+	})
+
+	t.Run("OperationTimeout", func(t *testing.T) {
+		server, calls := flakyServer(10, func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(300 * time.Millisecond)
+		})
+		defer server.Close()
+		useRetryProfile(t, "retry-timeout", server.URL)
+		viper.Set("profiles.retry-timeout.max_attempts", 2)
+		viper.Set("profiles.retry-timeout.timeouts", map[string]string{"ListBuckets": "50ms"})
+
+		assert.Contains(t, listBucketsOutput(t), "context deadline exceeded")
+		assert.Equal(t, int32(2), atomic.LoadInt32(calls), "Expected timed out requests to be retried")
+	})
+}
+
+func TestRetryIdempotentPost(t *testing.T) {
+	// Une suppression groupée (POST ?delete) est idempotente et peut être rejouée
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	useRetryProfile(t, "retry-delete", server.URL)
+
+	output := CaptureOutput(func() {
+		cmd.RootCmd.SetArgs([]string{"delete-object", "retry-bucket", "retry-object.txt"})
+		err := cmd.RootCmd.Execute()
+		assert.NoError(t, err)
+	})
+	assert.Contains(t, output, "Successfully deleted object 'retry-object.txt' from bucket 'retry-bucket'.")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestRetryUploadFile(t *testing.T) {
+	// Un upload en une seule requête relit le fichier depuis le début à chaque tentative
+	var calls int32
+	var received []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Method != http.MethodPut {
+			return
+		}
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		received = body
+	}))
+	defer server.Close()
+	useRetryProfile(t, "retry-upload", server.URL)
+
+	file := filepath.Join(t.TempDir(), "report.csv")
+	os.WriteFile(file, []byte("id,amount\n1,42\n"), 0o644)
+
+	output := runCommand(t, "upload-file", "retry-bucket", file, "--progress", "none")
+	assert.Contains(t, output, "File 'report.csv' uploaded successfully to bucket 'retry-bucket'.")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls), "Expected the upload to be retried once")
+	assert.Equal(t, "id,amount\n1,42\n", string(received), "Expected the whole file to be sent again")
+}
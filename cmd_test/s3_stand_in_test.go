@@ -0,0 +1,880 @@
+package cmd_test
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AlizeaMassePlat/plateforme-mycli/cmd"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+// standInVersion est une version d'objet, ou un marqueur de suppression
+type standInVersion struct {
+	id           string
+	data         []byte
+	deleteMarker bool
+	modified     time.Time
+}
+
+// s3StandIn simule les opérations S3 sur les objets (PUT, GET avec Range, upload multipart)
+// et enregistre les requêtes reçues
+type s3StandIn struct {
+	mu       sync.Mutex
+	objects  map[string][]byte // "bucket/clé" -> contenu
+	headers  map[string]http.Header
+	uploads  map[string]map[int][]byte
+	aborted  []string
+	requests []string
+	ranges   []string // en-têtes Range reçus
+
+	// versioning associe un bucket à son statut ; versions conserve l'historique des clés
+	// (de la plus ancienne à la plus récente) des buckets versionnés
+	versioning map[string]string
+	versions   map[string][]standInVersion
+
+	// tags associe "bucket/clé" (ou "bucket/" pour le bucket) à ses tags
+	tags map[string]map[string]string
+
+	// policies associe un bucket à sa politique
+	policies map[string][]byte
+
+	// configs conserve les configurations de bucket stockées telles quelles ("bucket?lifecycle" -> XML)
+	configs map[string][]byte
+
+	// modified fixe la date de dernière modification d'une clé dans les listes (2024-01-01 par défaut)
+	modified map[string]time.Time
+
+	// cors associe un bucket à sa configuration CORS
+	cors map[string]cmd.CORSConfiguration
+
+	// buckets associe les buckets créés à leur propriétaire ("" pour le compte du test)
+	buckets map[string]string
+
+	// acls associe "bucket/clé" (ou "bucket/") à son ACL ; aclsDisabled simule l'Object Ownership « bucket owner enforced »
+	acls         map[string][]byte
+	aclsDisabled map[string]bool
+
+	// locks associe "bucket/clé?version" à sa rétention (?retention) et à sa suspension légale (?legal-hold)
+	locks map[string]map[string][]byte
+
+	// pageSize limite les pages des listes d'objets quand la requête ne précise pas max-keys
+	pageSize int
+
+	// failPart fait échouer l'envoi de cette partie (500)
+	failPart int
+
+	// failDeletesFrom fait échouer (500) les requêtes ?delete à partir de la n-ième ; deletes les compte
+	failDeletesFrom int
+	deletes         int
+
+	// denyDelete refuse la suppression groupée de cette clé ("bucket/clé", AccessDenied)
+	denyDelete string
+}
+
+// ResetFlags remet les flags d'une commande à leur valeur par défaut,
+// Cobra conservant leurs valeurs d'une exécution à l'autre
+func ResetFlags(c *cobra.Command) {
+	c.Flags().VisitAll(func(f *pflag.Flag) {
+		if slice, ok := f.Value.(pflag.SliceValue); ok {
+			slice.Replace(nil)
+		} else {
+			f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	})
+}
+
+// runCommand exécute une commande (sous-commandes comprises) après avoir réinitialisé ses flags
+func runCommand(t *testing.T, args ...string) string {
+	// Les options persistent d'une exécution à l'autre : elles sont remises à zéro avant et après
+	if command, _, err := cmd.RootCmd.Find(args); err == nil {
+		ResetFlags(command)
+		t.Cleanup(func() { ResetFlags(command) })
+	}
+	return CaptureOutput(func() {
+		cmd.RootCmd.SetArgs(args)
+		err := cmd.RootCmd.Execute()
+		assert.NoError(t, err, "Unexpected error during %s", args[0])
+	})
+}
+
+// runWithStdin exécute une commande en lui fournissant son entrée standard
+func runWithStdin(t *testing.T, stdin io.Reader, args ...string) string {
+	cmd.RootCmd.SetIn(stdin)
+	t.Cleanup(func() { cmd.RootCmd.SetIn(nil) })
+	return runCommand(t, args...)
+}
+
+func newS3StandIn(t *testing.T) (*s3StandIn, *httptest.Server) {
+	s := &s3StandIn{
+		objects: map[string][]byte{},
+		headers: map[string]http.Header{},
+		uploads: map[string]map[int][]byte{},
+
+		versioning: map[string]string{},
+		versions:   map[string][]standInVersion{},
+		tags:       map[string]map[string]string{},
+		policies:   map[string][]byte{},
+		cors:       map[string]cmd.CORSConfiguration{},
+		configs:    map[string][]byte{},
+		modified:   map[string]time.Time{},
+		locks:      map[string]map[string][]byte{},
+		buckets:    map[string]string{},
+		acls:       map[string][]byte{},
+
+		aclsDisabled: map[string]bool{},
+	}
+	server := httptest.NewServer(s)
+	t.Cleanup(server.Close)
+	return s, server
+}
+
+func (s *s3StandIn) object(path string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.objects[path]
+	return data, ok
+}
+
+func (s *s3StandIn) requestLog() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+func (s *s3StandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	query := r.URL.Query()
+	body, _ := io.ReadAll(r.Body)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.RequestURI())
+	if rangeHeader := r.Header.Get("Range"); rangeHeader != "" {
+		s.ranges = append(s.ranges, rangeHeader)
+	}
+
+	bucket, _, _ := strings.Cut(path, "/")
+	switch {
+	case query.Has("versioning"):
+		s.serveVersioning(w, r, bucket, body)
+
+	case query.Has("policy"):
+		switch r.Method {
+		case "PUT":
+			s.policies[bucket] = body
+			w.WriteHeader(http.StatusNoContent)
+		case "DELETE":
+			delete(s.policies, bucket)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			policy, ok := s.policies[bucket]
+			if !ok {
+				s.fail(w, http.StatusNotFound, "NoSuchBucketPolicy", "The bucket policy does not exist")
+				return
+			}
+			w.Write(policy)
+		}
+
+	case storedConfig(query) != "":
+		name := storedConfig(query)
+		switch r.Method {
+		case "PUT":
+			if r.Header.Get("Content-MD5") == "" {
+				s.fail(w, http.StatusBadRequest, "InvalidRequest", "Missing required header for this request: Content-MD5")
+				return
+			}
+			if _, ok := s.configs[bucket+"?"+name]; name == "object-lock" && !ok {
+				s.fail(w, http.StatusConflict, "InvalidBucketState", "Object Lock configuration cannot be enabled on existing buckets")
+				return
+			}
+			s.configs[bucket+"?"+name] = body
+		case "DELETE":
+			delete(s.configs, bucket+"?"+name)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			config, ok := s.configs[bucket+"?"+name]
+			if !ok {
+				s.fail(w, http.StatusNotFound, configNotFound[name], "The configuration does not exist")
+				return
+			}
+			w.Write(config)
+		}
+
+	case query.Has("retention") || query.Has("legal-hold"):
+		s.serveObjectLock(w, r, path, query, body)
+
+	case r.Method == "GET" && query.Has("location"):
+		// La région est celle donnée à la création ; vide pour us-east-1
+		var config cmd.CreateBucketConfiguration
+		xml.Unmarshal(s.configs[bucket+"?create"], &config)
+		fmt.Fprintf(w, `<LocationConstraint xmlns="http://s3.amazonaws.com/doc/2006-03-01/">%s</LocationConstraint>`, config.LocationConstraint)
+
+	case r.Method == "PUT" && path == bucket+"/" && len(query) == 0:
+		// Création de bucket ; Object Lock active le versioning
+		if owner, exists := s.buckets[bucket]; exists {
+			if owner != "" {
+				s.fail(w, http.StatusConflict, "BucketAlreadyExists", "The requested bucket name is not available.")
+			} else {
+				s.fail(w, http.StatusConflict, "BucketAlreadyOwnedByYou", "Your previous request to create the named bucket succeeded and you already own it.")
+			}
+			return
+		}
+		s.buckets[bucket] = ""
+		s.headers[path] = r.Header.Clone()
+		s.configs[bucket+"?create"] = body
+		if r.Header.Get("X-Amz-Bucket-Object-Lock-Enabled") == "true" {
+			s.configs[bucket+"?object-lock"] = []byte("<ObjectLockConfiguration><ObjectLockEnabled>Enabled</ObjectLockEnabled></ObjectLockConfiguration>")
+			s.versioning[bucket] = "Enabled"
+		}
+
+	case r.Method == "GET" && path == "":
+		// Buckets du compte du test, triés comme par S3
+		result := cmd.ListAllMyBucketsResult{}
+		for name, owner := range s.buckets {
+			if owner == "" {
+				result.Buckets = append(result.Buckets, cmd.Bucket{Name: name, CreationDate: "2024-01-01T00:00:00Z"})
+			}
+		}
+		sort.Slice(result.Buckets, func(i, j int) bool { return result.Buckets[i].Name < result.Buckets[j].Name })
+		data, _ := xml.Marshal(result)
+		w.Write(data)
+
+	case r.Method == "DELETE" && path == bucket+"/" && len(query) == 0:
+		for key := range s.objects {
+			if strings.HasPrefix(key, bucket+"/") {
+				s.fail(w, http.StatusConflict, "BucketNotEmpty", "The bucket you tried to delete is not empty")
+				return
+			}
+		}
+		delete(s.buckets, bucket)
+		w.WriteHeader(http.StatusNoContent)
+
+	case query.Has("cors"):
+		switch r.Method {
+		case "PUT":
+			var config cmd.CORSConfiguration
+			if err := xml.Unmarshal(body, &config); err != nil || r.Header.Get("Content-MD5") == "" {
+				s.fail(w, http.StatusBadRequest, "MalformedXML", "The XML you provided was not well-formed.")
+				return
+			}
+			s.cors[bucket] = config
+		case "DELETE":
+			delete(s.cors, bucket)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			config, ok := s.cors[bucket]
+			if !ok {
+				s.fail(w, http.StatusNotFound, "NoSuchCORSConfiguration", "The CORS configuration does not exist")
+				return
+			}
+			data, _ := xml.Marshal(config)
+			w.Write(data)
+		}
+
+	case r.Method == "HEAD" && path == bucket+"/" && len(query) == 0:
+		switch owner, exists := s.buckets[bucket]; {
+		case !exists:
+			w.WriteHeader(http.StatusNotFound)
+		case owner != "":
+			w.WriteHeader(http.StatusForbidden)
+		}
+
+	case r.Method == "OPTIONS":
+		s.preflight(w, r, bucket)
+
+	case query.Has("acl"):
+		s.serveACL(w, r, path, body)
+
+	case query.Has("tagging"):
+		s.serveTagging(w, r, path, body)
+
+	case r.Method == "GET" && path == bucket+"/" && isListObjects(query):
+		s.listObjects(w, bucket, query)
+
+	case r.Method == "GET" && query.Has("versions"):
+		s.listVersions(w, bucket, query)
+
+	case r.Method == "POST" && query.Has("delete"):
+		s.deleteObjects(w, r, bucket, body)
+
+	case r.Method == "PUT" && r.Header.Get("X-Amz-Copy-Source") != "":
+		s.copyObject(w, r, path)
+
+	case (r.Method == "GET" || r.Method == "HEAD") && query.Has("versionId"):
+		version, ok := s.version(path, query.Get("versionId"))
+		switch {
+		case !ok:
+			s.fail(w, http.StatusNotFound, "NoSuchVersion", "The specified version does not exist.")
+		case version.deleteMarker:
+			w.Header().Set("x-amz-delete-marker", "true")
+			s.fail(w, http.StatusMethodNotAllowed, "MethodNotAllowed", "The specified method is not allowed against this resource.")
+		default:
+			w.Header().Set("x-amz-version-id", version.id)
+			http.ServeContent(w, r, path, time.Time{}, bytes.NewReader(version.data))
+		}
+
+	case r.Method == "POST" && query.Has("uploads"):
+		id := fmt.Sprintf("upload-%d", len(s.uploads)+len(s.aborted)+1)
+		s.uploads[id] = map[int][]byte{}
+		s.headers[path] = r.Header.Clone()
+		fmt.Fprintf(w, "<InitiateMultipartUploadResult><UploadId>%s</UploadId></InitiateMultipartUploadResult>", id)
+
+	case r.Method == "PUT" && query.Has("uploadId"):
+		number, _ := strconv.Atoi(query.Get("partNumber"))
+		parts, ok := s.uploads[query.Get("uploadId")]
+		if !ok {
+			s.fail(w, http.StatusNotFound, "NoSuchUpload", "The specified upload does not exist.")
+			return
+		}
+		if number == s.failPart {
+			s.fail(w, http.StatusInternalServerError, "InternalError", "We encountered an internal error.")
+			return
+		}
+		parts[number] = body
+		w.Header().Set("ETag", fmt.Sprintf(`"etag-%d"`, number))
+
+	case r.Method == "POST" && query.Has("uploadId"):
+		parts, ok := s.uploads[query.Get("uploadId")]
+		if !ok {
+			s.fail(w, http.StatusNotFound, "NoSuchUpload", "The specified upload does not exist.")
+			return
+		}
+		var complete cmd.CompleteMultipartUpload
+		xml.Unmarshal(body, &complete)
+		var object bytes.Buffer
+		for i, part := range complete.Parts {
+			if part.PartNumber != i+1 || part.ETag != fmt.Sprintf(`"etag-%d"`, part.PartNumber) {
+				s.fail(w, http.StatusBadRequest, "InvalidPartOrder", "The list of parts was not in ascending order.")
+				return
+			}
+			object.Write(parts[part.PartNumber])
+		}
+		s.objects[path] = object.Bytes()
+		delete(s.uploads, query.Get("uploadId"))
+		fmt.Fprint(w, "<CompleteMultipartUploadResult></CompleteMultipartUploadResult>")
+
+	case r.Method == "DELETE" && query.Has("uploadId"):
+		delete(s.uploads, query.Get("uploadId"))
+		s.aborted = append(s.aborted, query.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+
+	case r.Method == "PUT":
+		if tagging := r.Header.Get("X-Amz-Tagging"); tagging != "" {
+			values, _ := url.ParseQuery(tagging)
+			s.tags[path] = map[string]string{}
+			for name := range values {
+				s.tags[path][name] = values.Get(name)
+			}
+		}
+		if !s.validCustomerKey(w, r.Header, "X-Amz-") {
+			return
+		}
+		s.headers[path] = r.Header.Clone()
+		echoEncryption(w, r.Header)
+		s.store(w, path, body)
+		w.Header().Set("ETag", `"etag"`)
+
+	case r.Method == "GET" || r.Method == "HEAD":
+		data, ok := s.objects[path]
+		if !ok {
+			s.fail(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+			return
+		}
+		if !s.checkCustomerKey(w, s.headers[path], r.Header, "X-Amz-") {
+			return
+		}
+		echoEncryption(w, s.headers[path])
+		for name, values := range s.headers[path] {
+			if strings.HasPrefix(strings.ToLower(name), "x-amz-meta-") {
+				w.Header()[name] = values
+			}
+		}
+		if history := s.versions[path]; len(history) > 0 {
+			w.Header().Set("x-amz-version-id", history[len(history)-1].id)
+		}
+		http.ServeContent(w, r, path, time.Time{}, bytes.NewReader(data))
+
+	default:
+		s.fail(w, http.StatusNotImplemented, "NotImplemented", "Not implemented by the stand-in.")
+	}
+}
+
+func (s *s3StandIn) fail(w http.ResponseWriter, status int, code, message string) {
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "<Error><Code>%s</Code><Message>%s</Message></Error>", code, message)
+}
+
+// store enregistre le contenu d'une clé ; dans un bucket versionné, une nouvelle version est créée
+func (s *s3StandIn) store(w http.ResponseWriter, path string, data []byte) {
+	s.objects[path] = data
+	bucket, _, _ := strings.Cut(path, "/")
+	if s.versioning[bucket] == "Enabled" {
+		id := s.addVersion(path, standInVersion{data: data})
+		w.Header().Set("x-amz-version-id", id)
+	}
+}
+
+// addVersion ajoute une version à l'historique de la clé, datée d'une minute après la précédente
+func (s *s3StandIn) addVersion(path string, version standInVersion) string {
+	count := 0
+	for _, history := range s.versions {
+		count += len(history)
+	}
+	version.id = fmt.Sprintf("v%d", count+1)
+	version.modified = time.Date(2024, 1, 1, 0, count, 0, 0, time.UTC)
+	s.versions[path] = append(s.versions[path], version)
+	return version.id
+}
+
+func (s *s3StandIn) version(path, id string) (standInVersion, bool) {
+	for _, version := range s.versions[path] {
+		if version.id == id {
+			return version, true
+		}
+	}
+	return standInVersion{}, false
+}
+
+func (s *s3StandIn) serveVersioning(w http.ResponseWriter, r *http.Request, bucket string, body []byte) {
+	if r.Method == "PUT" {
+		var config cmd.VersioningConfiguration
+		if err := xml.Unmarshal(body, &config); err != nil || r.Header.Get("Content-MD5") == "" {
+			s.fail(w, http.StatusBadRequest, "MalformedXML", "The XML you provided was not well-formed.")
+			return
+		}
+		s.versioning[bucket] = config.Status
+		return
+	}
+	status := ""
+	if s.versioning[bucket] != "" {
+		status = "<Status>" + s.versioning[bucket] + "</Status>"
+	}
+	fmt.Fprintf(w, `<VersioningConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/">%s</VersioningConfiguration>`, status)
+}
+
+// listVersions pagine l'historique : max-keys entrées par page, reprise après key-marker/version-id-marker
+func (s *s3StandIn) listVersions(w http.ResponseWriter, bucket string, query url.Values) {
+	var paths []string
+	for path := range s.versions {
+		if strings.HasPrefix(path, bucket+"/"+query.Get("prefix")) {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	var result cmd.ListVersionsResult
+	resumed := query.Get("key-marker") == ""
+	limit, _ := strconv.Atoi(query.Get("max-keys"))
+	count := 0
+	for _, path := range paths {
+		key := strings.TrimPrefix(path, bucket+"/")
+		history := s.versions[path]
+		for i := len(history) - 1; i >= 0; i-- {
+			version := history[i]
+			if !resumed {
+				resumed = key == query.Get("key-marker") && version.id == query.Get("version-id-marker")
+				continue
+			}
+			if limit > 0 && count == limit {
+				result.IsTruncated = true
+				break
+			}
+			modified := version.modified.Format(time.RFC3339)
+			if version.deleteMarker {
+				result.DeleteMarkers = append(result.DeleteMarkers, cmd.DeleteMarkerEntry{Key: key, VersionID: version.id, IsLatest: i == len(history)-1, LastModified: modified})
+			} else {
+				result.Versions = append(result.Versions, cmd.ObjectVersion{Key: key, VersionID: version.id, IsLatest: i == len(history)-1, LastModified: modified, Size: int64(len(version.data)), ETag: fmt.Sprintf(`"%x"`, md5.Sum(version.data))})
+			}
+			result.NextKeyMarker, result.NextVersionIDMarker = key, version.id
+			count++
+		}
+		if result.IsTruncated {
+			break
+		}
+	}
+	if !result.IsTruncated {
+		result.NextKeyMarker, result.NextVersionIDMarker = "", ""
+	}
+	data, _ := xml.Marshal(result)
+	w.Write(data)
+}
+
+// deleteObjects traite ?delete : une version précise est supprimée définitivement,
+// sinon un marqueur de suppression est ajouté dans un bucket versionné
+func (s *s3StandIn) deleteObjects(w http.ResponseWriter, r *http.Request, bucket string, body []byte) {
+	if r.Header.Get("Content-MD5") == "" {
+		s.fail(w, http.StatusBadRequest, "InvalidRequest", "Missing required header for this request: Content-MD5")
+		return
+	}
+	if s.deletes++; s.failDeletesFrom > 0 && s.deletes >= s.failDeletesFrom {
+		s.fail(w, http.StatusInternalServerError, "InternalError", "We encountered an internal error.")
+		return
+	}
+	var request cmd.DeleteObjectRequest
+	xml.Unmarshal(body, &request)
+	var result strings.Builder
+	for _, object := range request.Objects {
+		path := bucket + "/" + object.Key
+		if object.VersionId != "" {
+			if s.locked(path+"?"+object.VersionId, r.Header.Get("X-Amz-Bypass-Governance-Retention") == "true") {
+				fmt.Fprintf(&result, "<Error><Key>%s</Key><VersionId>%s</VersionId><Code>AccessDenied</Code><Message>Access Denied because object protected by object lock.</Message></Error>", object.Key, object.VersionId)
+				continue
+			}
+			history := s.versions[path]
+			for i, version := range history {
+				if version.id == object.VersionId {
+					s.versions[path] = append(history[:i:i], history[i+1:]...)
+					break
+				}
+			}
+			continue
+		}
+		if path == s.denyDelete {
+			fmt.Fprintf(&result, "<Error><Key>%s</Key><Code>AccessDenied</Code><Message>Access Denied</Message></Error>", object.Key)
+			continue
+		}
+		delete(s.objects, path)
+		if s.versioning[bucket] == "Enabled" {
+			s.addVersion(path, standInVersion{deleteMarker: true})
+		}
+	}
+	fmt.Fprintf(w, "<DeleteResult>%s</DeleteResult>", result.String())
+}
+
+// standInOwner est le propriétaire des buckets et objets du stand-in
+var standInOwner = cmd.ACLOwner{ID: "79a59df900b949e55d96a1e698fbaced", DisplayName: "test-owner"}
+
+// serveACL traite ?acl : une ACL prédéfinie (x-amz-acl) est convertie en permissions,
+// un document complet est conservé tel quel
+func (s *s3StandIn) serveACL(w http.ResponseWriter, r *http.Request, path string, body []byte) {
+	bucket, _, _ := strings.Cut(path, "/")
+	if s.aclsDisabled[bucket] {
+		s.fail(w, http.StatusBadRequest, "AccessControlListNotSupported", "The bucket does not allow ACLs")
+		return
+	}
+	if _, exists := s.objects[path]; !exists && !strings.HasSuffix(path, "/") {
+		s.fail(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+		return
+	}
+
+	if r.Method == "PUT" {
+		if canned := r.Header.Get("X-Amz-Acl"); canned != "" {
+			s.acls[path] = cannedPolicy(canned)
+			return
+		}
+		var policy cmd.AccessControlPolicy
+		if err := xml.Unmarshal(body, &policy); err != nil || r.Header.Get("Content-MD5") == "" || policy.Owner.ID != standInOwner.ID {
+			s.fail(w, http.StatusBadRequest, "MalformedACLError", "The XML you provided was not well-formed or did not validate against our published schema.")
+			return
+		}
+		s.acls[path] = body
+		return
+	}
+	policy, ok := s.acls[path]
+	if !ok {
+		policy = cannedPolicy("private")
+	}
+	w.Write(policy)
+}
+
+// cannedPolicy retourne le document d'ACL correspondant à une ACL prédéfinie
+func cannedPolicy(canned string) []byte {
+	grant := func(grantee cmd.Grantee, permission string) cmd.Grant {
+		return cmd.Grant{Grantee: grantee, Permission: permission}
+	}
+	allUsers := cmd.Grantee{Type: "Group", URI: "http://acs.amazonaws.com/groups/global/AllUsers"}
+	policy := cmd.AccessControlPolicy{Owner: standInOwner, Grants: []cmd.Grant{
+		grant(cmd.Grantee{Type: "CanonicalUser", ID: standInOwner.ID, DisplayName: standInOwner.DisplayName}, "FULL_CONTROL"),
+	}}
+	switch canned {
+	case "public-read":
+		policy.Grants = append(policy.Grants, grant(allUsers, "READ"))
+	case "public-read-write":
+		policy.Grants = append(policy.Grants, grant(allUsers, "READ"), grant(allUsers, "WRITE"))
+	case "authenticated-read":
+		policy.Grants = append(policy.Grants, grant(cmd.Grantee{Type: "Group", URI: "http://acs.amazonaws.com/groups/global/AuthenticatedUsers"}, "READ"))
+	}
+	data, _ := xml.Marshal(policy)
+	return data
+}
+
+// serveObjectLock traite ?retention et ?legal-hold d'une version d'objet (la plus récente par défaut) ;
+// comme S3, une rétention COMPLIANCE ne peut pas être raccourcie, ni une rétention GOVERNANCE sans contournement
+func (s *s3StandIn) serveObjectLock(w http.ResponseWriter, r *http.Request, path string, query url.Values, body []byte) {
+	bucket, _, _ := strings.Cut(path, "/")
+	if _, ok := s.configs[bucket+"?object-lock"]; !ok {
+		s.fail(w, http.StatusBadRequest, "InvalidRequest", "Bucket is missing Object Lock Configuration")
+		return
+	}
+	versionID := query.Get("versionId")
+	if history := s.versions[path]; versionID == "" && len(history) > 0 {
+		versionID = history[len(history)-1].id
+	}
+	name := "retention"
+	if query.Has("legal-hold") {
+		name = "legal-hold"
+	}
+	target := path + "?" + versionID
+	if s.locks[target] == nil {
+		s.locks[target] = map[string][]byte{}
+	}
+
+	if r.Method == "PUT" {
+		if r.Header.Get("Content-MD5") == "" {
+			s.fail(w, http.StatusBadRequest, "InvalidRequest", "Missing required header for this request: Content-MD5")
+			return
+		}
+		if name == "retention" {
+			var current, next cmd.Retention
+			xml.Unmarshal(s.locks[target]["retention"], &current)
+			xml.Unmarshal(body, &next)
+			shortened := next.Mode == "" || next.RetainUntilDate < current.RetainUntilDate || next.Mode != current.Mode
+			bypass := r.Header.Get("X-Amz-Bypass-Governance-Retention") == "true"
+			if current.Mode != "" && shortened && (current.Mode == "COMPLIANCE" || !bypass) {
+				s.fail(w, http.StatusForbidden, "AccessDenied", "Access Denied")
+				return
+			}
+		}
+		s.locks[target][name] = body
+		return
+	}
+	data, ok := s.locks[target][name]
+	if !ok {
+		s.fail(w, http.StatusNotFound, "NoSuchObjectLockConfiguration", "The specified object does not have a ObjectLock configuration")
+		return
+	}
+	w.Write(data)
+}
+
+// locked indique si une version est protégée par une suspension légale ou une rétention en cours
+func (s *s3StandIn) locked(target string, bypass bool) bool {
+	var hold cmd.LegalHold
+	var retention cmd.Retention
+	xml.Unmarshal(s.locks[target]["legal-hold"], &hold)
+	xml.Unmarshal(s.locks[target]["retention"], &retention)
+	until, _ := time.Parse(time.RFC3339, retention.RetainUntilDate)
+	retained := until.After(time.Now()) && (retention.Mode == "COMPLIANCE" || !bypass)
+	return hold.Status == "ON" || retained
+}
+
+// copyObject copie la source (ou sa version) désignée par X-Amz-Copy-Source
+func (s *s3StandIn) copyObject(w http.ResponseWriter, r *http.Request, path string) {
+	source, _ := url.PathUnescape(r.Header.Get("X-Amz-Copy-Source"))
+	sourcePath, rawQuery, _ := strings.Cut(strings.TrimPrefix(source, "/"), "?")
+	sourceQuery, _ := url.ParseQuery(rawQuery)
+
+	data, ok := s.objects[sourcePath]
+	if versionID := sourceQuery.Get("versionId"); versionID != "" {
+		var version standInVersion
+		version, ok = s.version(sourcePath, versionID)
+		ok = ok && !version.deleteMarker
+		data = version.data
+	}
+	if !ok {
+		s.fail(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+		return
+	}
+	if !s.checkCustomerKey(w, s.headers[sourcePath], r.Header, "X-Amz-Copy-Source-") || !s.validCustomerKey(w, r.Header, "X-Amz-") {
+		return
+	}
+	s.headers[path] = r.Header.Clone()
+	echoEncryption(w, r.Header)
+	s.store(w, path, data)
+	fmt.Fprint(w, `<CopyObjectResult><ETag>"etag"</ETag></CopyObjectResult>`)
+}
+
+func (s *s3StandIn) serveTagging(w http.ResponseWriter, r *http.Request, path string, body []byte) {
+	switch r.Method {
+	case "PUT":
+		var tagging cmd.Tagging
+		if err := xml.Unmarshal(body, &tagging); err != nil || r.Header.Get("Content-MD5") == "" {
+			s.fail(w, http.StatusBadRequest, "MalformedXML", "The XML you provided was not well-formed.")
+			return
+		}
+		s.tags[path] = map[string]string{}
+		for _, tag := range tagging.TagSet {
+			s.tags[path][tag.Key] = tag.Value
+		}
+	case "DELETE":
+		delete(s.tags, path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		tags, ok := s.tags[path]
+		if !ok && strings.HasSuffix(path, "/") {
+			s.fail(w, http.StatusNotFound, "NoSuchTagSet", "The TagSet does not exist.")
+			return
+		}
+		if _, exists := s.objects[path]; !ok && !exists {
+			s.fail(w, http.StatusNotFound, "NoSuchKey", "The specified key does not exist.")
+			return
+		}
+		tagging := cmd.Tagging{}
+		for name, value := range tags {
+			tagging.TagSet = append(tagging.TagSet, cmd.Tag{Key: name, Value: value})
+		}
+		data, _ := xml.Marshal(tagging)
+		w.Write(data)
+	}
+}
+
+// listObjects liste les objets courants du bucket : max-keys par page, reprise après marker
+func (s *s3StandIn) listObjects(w http.ResponseWriter, bucket string, query url.Values) {
+	var keys []string
+	for path := range s.objects {
+		key, found := strings.CutPrefix(path, bucket+"/")
+		if found && strings.HasPrefix(key, query.Get("prefix")) && key > query.Get("marker") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var result cmd.ListObjectResult
+	limit, _ := strconv.Atoi(query.Get("max-keys"))
+	if limit == 0 {
+		limit = s.pageSize
+	}
+	if limit > 0 && len(keys) > limit {
+		keys, result.IsTruncated = keys[:limit], true
+	}
+	for _, key := range keys {
+		modified, ok := s.modified[bucket+"/"+key]
+		if !ok {
+			modified = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		}
+		result.Objects = append(result.Objects, cmd.Object{Key: key, LastModified: modified.Format(time.RFC3339), Size: len(s.objects[bucket+"/"+key])})
+	}
+	data, _ := xml.Marshal(result)
+	w.Write(data)
+}
+
+func isListObjects(query url.Values) bool {
+	for name := range query {
+		if name != "prefix" && name != "marker" && name != "max-keys" {
+			return false
+		}
+	}
+	return true
+}
+
+// preflight répond à une requête OPTIONS avec la première règle CORS correspondante
+// (jokers limités à "*" entier) ; les requêtes préliminaires ne doivent pas être signées
+func (s *s3StandIn) preflight(w http.ResponseWriter, r *http.Request, bucket string) {
+	if r.Header.Get("Authorization") != "" {
+		s.fail(w, http.StatusBadRequest, "InvalidRequest", "Preflight requests must not be signed.")
+		return
+	}
+	origin := r.Header.Get("Origin")
+	method := r.Header.Get("Access-Control-Request-Method")
+	var headers []string
+	if value := r.Header.Get("Access-Control-Request-Headers"); value != "" {
+		headers = strings.Split(value, ", ")
+	}
+	for _, rule := range s.cors[bucket].Rules {
+		allowed := func(values []string, value string) bool {
+			for _, v := range values {
+				if v == "*" || strings.EqualFold(v, value) {
+					return true
+				}
+			}
+			return false
+		}
+		headersOK := true
+		for _, header := range headers {
+			headersOK = headersOK && allowed(rule.AllowedHeaders, header)
+		}
+		if allowed(rule.AllowedOrigins, origin) && allowed(rule.AllowedMethods, method) && headersOK {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Methods", strings.Join(rule.AllowedMethods, ", "))
+			if len(headers) > 0 {
+				w.Header().Set("Access-Control-Allow-Headers", strings.Join(headers, ", "))
+			}
+			if rule.MaxAgeSeconds > 0 {
+				w.Header().Set("Access-Control-Max-Age", strconv.Itoa(rule.MaxAgeSeconds))
+			}
+			return
+		}
+	}
+	s.fail(w, http.StatusForbidden, "AccessForbidden", "CORSResponse: This CORS request is not allowed.")
+}
+
+// configNotFound associe les configurations stockées telles quelles au code d'erreur d'une configuration absente
+var configNotFound = map[string]string{
+	"lifecycle":   "NoSuchLifecycleConfiguration",
+	"encryption":  "ServerSideEncryptionConfigurationNotFoundError",
+	"object-lock": "ObjectLockConfigurationNotFoundError",
+}
+
+func storedConfig(query url.Values) string {
+	for name := range configNotFound {
+		if query.Has(name) {
+			return name
+		}
+	}
+	return ""
+}
+
+// validCustomerKey vérifie qu'une clé SSE-C reçue correspond à son MD5, comme S3
+func (s *s3StandIn) validCustomerKey(w http.ResponseWriter, request http.Header, prefix string) bool {
+	encoded := request.Get(prefix + "Server-Side-Encryption-Customer-Key")
+	if encoded == "" {
+		return true
+	}
+	key, _ := base64.StdEncoding.DecodeString(encoded)
+	sum := md5.Sum(key)
+	if len(key) != 32 || base64.StdEncoding.EncodeToString(sum[:]) != request.Get(prefix+"Server-Side-Encryption-Customer-Key-Md5") {
+		s.fail(w, http.StatusBadRequest, "InvalidArgument", "The calculated MD5 hash of the key did not match the hash that was provided.")
+		return false
+	}
+	return true
+}
+
+// checkCustomerKey exige, pour lire un objet chiffré en SSE-C, la clé qui a servi à l'écrire :
+// 400 si elle est absente, 403 si elle diffère
+func (s *s3StandIn) checkCustomerKey(w http.ResponseWriter, stored, request http.Header, prefix string) bool {
+	storedMD5 := stored.Get("X-Amz-Server-Side-Encryption-Customer-Key-Md5")
+	if storedMD5 == "" {
+		return true
+	}
+	if !s.validCustomerKey(w, request, prefix) {
+		return false
+	}
+	switch request.Get(prefix + "Server-Side-Encryption-Customer-Key-Md5") {
+	case storedMD5:
+		return true
+	case "":
+		s.fail(w, http.StatusBadRequest, "InvalidRequest", "The object was stored using a form of Server Side Encryption. The correct parameters must be provided to retrieve the object.")
+	default:
+		s.fail(w, http.StatusForbidden, "AccessDenied", "Access Denied")
+	}
+	return false
+}
+
+// echoEncryption renvoie les en-têtes de chiffrement d'un objet, sans la clé SSE-C elle-même
+func echoEncryption(w http.ResponseWriter, stored http.Header) {
+	for _, name := range []string{
+		"X-Amz-Server-Side-Encryption",
+		"X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id",
+		"X-Amz-Server-Side-Encryption-Customer-Algorithm",
+		"X-Amz-Server-Side-Encryption-Customer-Key-Md5",
+	} {
+		if value := stored.Get(name); value != "" {
+			w.Header().Set(name, value)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package cmd_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/AlizeaMassePlat/plateforme-mycli/cmd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTags(t *testing.T) {
+	standIn, server := newS3StandIn(t)
+	useRetryProfile(t, "tags", server.URL)
+	for i := 1; i <= 12; i++ {
+		standIn.objects[fmt.Sprintf("data/file-%02d.csv", i)] = []byte("a,b\n")
+		env := "prod"
+		if i%3 == 0 {
+			env = "dev"
+		}
+		standIn.tags[fmt.Sprintf("data/file-%02d.csv", i)] = map[string]string{"env": env}
+	}
+
+	t.Run("ObjectTags", func(t *testing.T) {
+		output := runCommand(t, "tag", "set", "s3://data/file-01.csv", "env=prod", "team=finance")
+		assert.Contains(t, output, "Tags updated on object 'file-01.csv' in bucket 'data' (2 tag(s)).")
+
+		output = runCommand(t, "tag", "set", "data", "file-01.csv", "owner=alice", "--merge")
+		assert.Contains(t, output, "(3 tag(s))")
+		assert.Equal(t, "env=prod\nowner=alice\nteam=finance\n", runCommand(t, "tag", "get", "data", "file-01.csv"))
+
+		assert.Contains(t, runCommand(t, "tag", "delete", "s3://data/file-01.csv"), "Tags deleted from object 'file-01.csv' in bucket 'data'.")
+		assert.Contains(t, runCommand(t, "tag", "get", "s3://data/file-01.csv"), "No tags on object 'file-01.csv' in bucket 'data'.")
+	})
+
+	t.Run("BucketTags", func(t *testing.T) {
+		assert.Contains(t, runCommand(t, "tag", "get", "data"), "No tags on bucket 'data'.")
+		assert.Contains(t, runCommand(t, "tag", "set", "data", "cost-center=42"), "Tags updated on bucket 'data' (1 tag(s)).")
+		assert.Equal(t, "cost-center=42\n", runCommand(t, "tag", "get", "s3://data"))
+	})
+
+	t.Run("InvalidTag", func(t *testing.T) {
+		assert.Contains(t, runCommand(t, "tag", "set", "s3://data/file-02.csv", "=value"), "invalid tag '=value' (expected key=value)")
+	})
+
+	t.Run("UploadWithTags", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "report.txt")
+		os.WriteFile(file, []byte("report"), 0644)
+		t.Cleanup(func() { cmd.RootCmd.PersistentFlags().Set("progress", "auto") })
+
+		output := runCommand(t, "upload-file", "data", file, "--tag", "env=dev", "--tag", "kind=report", "--progress", "none")
+		assert.Contains(t, output, "File 'report.txt' uploaded successfully to bucket 'data'.")
+		assert.Equal(t, "env=dev&kind=report", standIn.headers["data/report.txt"].Get("X-Amz-Tagging"))
+		assert.Equal(t, "env=dev\nkind=report\n", runCommand(t, "tag", "get", "data", "report.txt"))
+	})
+
+	t.Run("ListWithTagFilter", func(t *testing.T) {
+		// Le filtre porte sur toutes les pages du listage
+		standIn.pageSize = 5
+		t.Cleanup(func() { standIn.pageSize = 0 })
+
+		output := runCommand(t, "list-object", "data", "--tag-filter", "env=dev", "--prefix", "file-")
+		assert.Contains(t, output, "file-03.csv")
+		assert.Contains(t, output, "file-12.csv")
+		assert.NotContains(t, output, "file-02.csv")
+
+		output = runCommand(t, "list-object", "data", "--tag-filter", "env=dev", "--tag-filter", "kind")
+		assert.Contains(t, output, "report.txt")
+		assert.NotContains(t, output, "file-03.csv")
+
+		output = runCommand(t, "list-object", "data", "--tag-filter", "env=dev", "--as-of", "2024-06-01")
+		assert.Contains(t, output, "Error: --tag-filter cannot be combined with --as-of")
+		assert.NotContains(t, output, "file-03.csv")
+	})
+
+	t.Run("DeleteWithTagFilter", func(t *testing.T) {
+		output := runCommand(t, "delete-object", "data", "--tag-filter", "env=dev", "--bypass-governance")
+		assert.Contains(t, output, "Error: --version-id and --bypass-governance cannot be combined with --tag-filter")
+		output = runCommand(t, "delete-object", "data", "--tag-filter", "env=dev", "--version-id", "v1")
+		assert.Contains(t, output, "cannot be combined with --tag-filter")
+
+		output = runCommand(t, "delete-object", "data", "--tag-filter", "env=dev", "--prefix", "file-", "--dry-run")
+		assert.Contains(t, output, "Dry run: 4 object(s) would be deleted from bucket 'data'.")
+		_, ok := standIn.object("data/file-03.csv")
+		assert.True(t, ok, "Expected a dry run to keep the objects")
+
+		output = runCommand(t, "delete-object", "data", "--tag-filter", "env=dev", "--prefix", "file-")
+		assert.Contains(t, output, "Deleted 4 object(s) matching the tag filter from bucket 'data'.")
+		for _, name := range []string{"file-03.csv", "file-06.csv", "file-09.csv", "file-12.csv"} {
+			_, ok := standIn.object("data/" + name)
+			assert.False(t, ok, "Expected %s to be deleted", name)
+		}
+		_, ok = standIn.object("data/file-04.csv")
+		assert.True(t, ok, "Expected objects tagged env=prod to be kept")
+		_, ok = standIn.object("data/report.txt")
+		assert.True(t, ok, "Expected keys outside --prefix to be kept")
+	})
+
+	t.Run("DeleteWithTagFilterPartialFailure", func(t *testing.T) {
+		standIn.denyDelete = "data/file-04.csv"
+		t.Cleanup(func() { standIn.denyDelete = "" })
+
+		output := runCommand(t, "delete-object", "data", "--tag-filter", "env=prod", "--prefix", "file-")
+		assert.Contains(t, output, "Error: failed to delete 'file-04.csv': AccessDenied: Access Denied")
+		assert.Contains(t, output, "Error: 1 of 7 key(s) could not be deleted")
+		assert.NotContains(t, output, "Deleted 7 object(s)")
+		_, ok := standIn.object("data/file-04.csv")
+		assert.True(t, ok, "Expected the denied key to be kept")
+		_, ok = standIn.object("data/file-05.csv")
+		assert.False(t, ok, "Expected the other keys to be deleted")
+	})
+}
+
+func TestDeleteWithTagFilterStopsMidway(t *testing.T) {
+	standIn, server := newS3StandIn(t)
+	useRetryProfile(t, "tags-midway", server.URL)
+	for i := 1; i <= 1001; i++ {
+		standIn.objects[fmt.Sprintf("data/tmp-%04d", i)] = []byte("x")
+		standIn.tags[fmt.Sprintf("data/tmp-%04d", i)] = map[string]string{"env": "dev"}
+	}
+	// Le premier lot de 1000 clés passe, le second échoue
+	standIn.failDeletesFrom = 2
+
+	output := runCommand(t, "delete-object", "data", "--tag-filter", "env=dev")
+	assert.Contains(t, output, "InternalError: We encountered an internal error. (status 500) (1000 of 1001 key(s) already deleted)")
+	assert.NotContains(t, output, "Deleted 1001 object(s)")
+	_, ok := standIn.object("data/tmp-1001")
+	assert.True(t, ok, "Expected the key of the failed batch to be kept")
+}
@@ -0,0 +1,138 @@
+package cmd_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AlizeaMassePlat/plateforme-mycli/cmd"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+// generateClientCert crée un certificat client auto-signé et retourne les chemins du certificat et de la clé
+func generateClientCert(t *testing.T, dir string) (*x509.Certificate, string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(42),
+		Subject:      pkix.Name{CommonName: "bs3-client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+
+	certPath := filepath.Join(dir, "client.pem")
+	keyPath := filepath.Join(dir, "client-key.pem")
+	writePEM(t, certPath, "CERTIFICATE", der)
+	writePEM(t, keyPath, "EC PRIVATE KEY", keyDER)
+	return cert, certPath, keyPath
+}
+
+func listBucketsOutput(t *testing.T) string {
+	return CaptureOutput(func() {
+		cmd.RootCmd.SetArgs([]string{"list-buckets"})
+		err := cmd.RootCmd.Execute()
+		assert.NoError(t, err)
+	})
+}
+
+func TestTLSConfiguration(t *testing.T) {
+	dir := t.TempDir()
+	clientCert, certPath, keyPath := generateClientCert(t, dir)
+
+	// Serveur TLS exigeant un certificat client
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "<ListAllMyBucketsResult><Buckets><Bucket><Name>secure-bucket</Name><CreationDate>2024-09-17T08:58:31Z</CreationDate></Bucket></Buckets></ListAllMyBucketsResult>")
+	}))
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs, MaxVersion: tls.VersionTLS12}
+	server.StartTLS()
+	defer server.Close()
+
+	caPath := filepath.Join(dir, "ca.pem")
+	writePEM(t, caPath, "CERTIFICATE", server.Certificate().Raw)
+	serverPin := sha256.Sum256(server.Certificate().RawSubjectPublicKeyInfo)
+
+	// Chaque sous-test utilise son propre profil pour partir d'une configuration TLS vierge
+	useProfile := func(t *testing.T, name string, settings map[string]interface{}) {
+		viper.Set("profiles."+name+".api_url", server.URL)
+		for key, value := range settings {
+			viper.Set("profiles."+name+"."+key, value)
+		}
+		t.Setenv("MYCLI_PROFILE", name)
+	}
+
+	t.Run("UnknownAuthority", func(t *testing.T) {
+		useProfile(t, "tls-no-ca", map[string]interface{}{"client_cert": certPath, "client_key": keyPath})
+		assert.Contains(t, listBucketsOutput(t), "certificate signed by unknown authority")
+	})
+
+	t.Run("MissingClientCertificate", func(t *testing.T) {
+		useProfile(t, "tls-no-client", map[string]interface{}{"ca_bundle": caPath})
+		assert.NotContains(t, listBucketsOutput(t), "secure-bucket")
+	})
+
+	t.Run("CABundleAndClientCertificate", func(t *testing.T) {
+		useProfile(t, "tls-mtls", map[string]interface{}{"ca_bundle": caPath, "client_cert": certPath, "client_key": keyPath})
+		assert.Contains(t, listBucketsOutput(t), "secure-bucket")
+	})
+
+	t.Run("InsecureSkipVerifyWarns", func(t *testing.T) {
+		useProfile(t, "tls-insecure", map[string]interface{}{"insecure_skip_verify": true, "client_cert": certPath, "client_key": keyPath})
+		output := listBucketsOutput(t)
+		assert.Contains(t, output, "WARNING: TLS certificate verification is DISABLED for profile 'tls-insecure'")
+		assert.Contains(t, output, "secure-bucket")
+	})
+
+	t.Run("MatchingPin", func(t *testing.T) {
+		useProfile(t, "tls-pinned", map[string]interface{}{"ca_bundle": caPath, "client_cert": certPath, "client_key": keyPath,
+			"spki_pins": []string{"sha256/" + base64.StdEncoding.EncodeToString(serverPin[:])}})
+		assert.Contains(t, listBucketsOutput(t), "secure-bucket")
+	})
+
+	t.Run("MismatchingPin", func(t *testing.T) {
+		useProfile(t, "tls-wrong-pin", map[string]interface{}{"ca_bundle": caPath, "client_cert": certPath, "client_key": keyPath,
+			"spki_pins": []string{base64.StdEncoding.EncodeToString(make([]byte, 32))}})
+		assert.Contains(t, listBucketsOutput(t), "no certificate in the server chain matches the configured spki_pins")
+	})
+
+	t.Run("MinimumVersion", func(t *testing.T) {
+		useProfile(t, "tls-13", map[string]interface{}{"ca_bundle": caPath, "client_cert": certPath, "client_key": keyPath, "tls_min_version": "1.3"})
+		assert.Contains(t, listBucketsOutput(t), "protocol version")
+	})
+
+	t.Run("InvalidMinimumVersion", func(t *testing.T) {
+		useProfile(t, "tls-bad-version", map[string]interface{}{"tls_min_version": "2.0"})
+		assert.Contains(t, listBucketsOutput(t), "invalid tls_min_version '2.0'")
+	})
+}
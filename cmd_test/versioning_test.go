@@ -0,0 +1,122 @@
+package cmd_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/AlizeaMassePlat/plateforme-mycli/cmd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersioning(t *testing.T) {
+	standIn, server := newS3StandIn(t)
+	useRetryProfile(t, "versioning", server.URL)
+
+	t.Run("StatusNeverEnabled", func(t *testing.T) {
+		output := runCommand(t, "versioning", "status", "docs")
+		assert.Contains(t, output, "Versioning for bucket 'docs': Unversioned (never enabled)")
+	})
+
+	t.Run("EnableAndSuspend", func(t *testing.T) {
+		assert.Contains(t, runCommand(t, "versioning", "enable", "docs"), "Versioning enabled for bucket 'docs'.")
+		assert.Contains(t, runCommand(t, "versioning", "status", "docs"), "Versioning for bucket 'docs': Enabled")
+		assert.Contains(t, runCommand(t, "versioning", "suspend", "scratch"), "Versioning suspended for bucket 'scratch'.")
+		assert.Contains(t, runCommand(t, "versioning", "status", "scratch"), "Versioning for bucket 'scratch': Suspended")
+	})
+
+	// Historique de docs/report.txt : v1 "draft", v2 "final", puis un marqueur de suppression (v4) ;
+	// notes.txt n'a qu'une version (v3)
+	runWithStdin(t, strings.NewReader("draft"), "put", "-", "s3://docs/report.txt")
+	runWithStdin(t, strings.NewReader("final"), "put", "-", "s3://docs/report.txt")
+	runWithStdin(t, strings.NewReader("notes"), "put", "-", "s3://docs/notes.txt")
+	runCommand(t, "delete-object", "docs", "report.txt")
+
+	t.Run("ListVersionsPaginated", func(t *testing.T) {
+		output := runCommand(t, "list-object-versions", "docs", "--page-size", "2")
+
+		assert.Contains(t, output, "- [2024-01-01 00:02:00] 5B notes.txt (version: v3, latest)")
+		assert.Contains(t, output, "- [2024-01-01 00:03:00] delete marker report.txt (version: v4, latest)")
+		assert.Contains(t, output, "- [2024-01-01 00:01:00] 5B report.txt (version: v2)")
+		assert.Contains(t, output, "- [2024-01-01 00:00:00] 5B report.txt (version: v1)")
+		assert.Less(t, strings.Index(output, "version: v4"), strings.Index(output, "version: v1"), "Expected newest versions first")
+		assert.Contains(t, standIn.requestLog(), "GET /docs/?key-marker=report.txt&max-keys=2&version-id-marker=v4&versions=")
+	})
+
+	t.Run("StatVersion", func(t *testing.T) {
+		output := runCommand(t, "stat", "s3://docs/report.txt", "--version-id", "v1")
+		assert.Contains(t, output, "Size:          5")
+		assert.Contains(t, output, "Version-Id:    v1")
+
+		output = runCommand(t, "stat", "s3://docs/report.txt", "--version-id", "v4")
+		assert.Contains(t, output, "version 'v4' of object 'report.txt' is a delete marker")
+
+		output = runCommand(t, "stat", "s3://docs/report.txt")
+		assert.Contains(t, output, "object 'report.txt' not found in bucket 'docs'")
+	})
+
+	t.Run("DownloadVersion", func(t *testing.T) {
+		dir := t.TempDir()
+		t.Cleanup(func() { cmd.RootCmd.PersistentFlags().Set("progress", "auto") })
+		output := runCommand(t, "download-file", "docs", "report.txt", dir, "--version-id", "v1", "--progress", "none")
+		assert.Contains(t, output, "Download completed successfully.")
+		data, _ := os.ReadFile(filepath.Join(dir, "report.txt"))
+		assert.Equal(t, "draft", string(data))
+	})
+
+	t.Run("RestoreVersion", func(t *testing.T) {
+		output := runCommand(t, "restore-version", "s3://docs/report.txt", "v2")
+		assert.Contains(t, output, "Restored version 'v2' of 'report.txt' in bucket 'docs'.")
+		assert.Contains(t, output, "New current version: v5")
+		data, _ := standIn.object("docs/report.txt")
+		assert.Equal(t, "final", string(data))
+	})
+
+	t.Run("CopyVersion", func(t *testing.T) {
+		output := runCommand(t, "copy-object", "s3://docs/report.txt", "s3://archive/report-draft.txt", "--version-id", "v1")
+		assert.Contains(t, output, "Copied s3://docs/report.txt to s3://archive/report-draft.txt.")
+		data, _ := standIn.object("archive/report-draft.txt")
+		assert.Equal(t, "draft", string(data))
+
+		output = runCommand(t, "copy-object", "docs", "missing.txt", "archive", "missing.txt")
+		assert.Contains(t, output, "object 'missing.txt' not found in bucket 'docs'")
+	})
+
+	t.Run("DeleteVersion", func(t *testing.T) {
+		output := runCommand(t, "delete-object", "docs", "report.txt", "--version-id", "v1")
+		assert.Contains(t, output, "Successfully deleted version 'v1' of object 'report.txt' from bucket 'docs'.")
+		assert.NotContains(t, runCommand(t, "list-object-versions", "docs", "--prefix", "report"), "version: v1)")
+	})
+
+	t.Run("InvalidVersionDate", func(t *testing.T) {
+		// Une date illisible est signalée plutôt que classée au 1er janvier de l'an 1
+		broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, "<ListVersionsResult><Version><Key>report.txt</Key><VersionId>v9</VersionId><LastModified>yesterday</LastModified></Version></ListVersionsResult>")
+		}))
+		defer broken.Close()
+		useRetryProfile(t, "versioning-broken", broken.URL)
+
+		output := runCommand(t, "list-object-versions", "docs")
+		assert.Contains(t, output, "invalid LastModified for version 'v9' of 'report.txt'")
+	})
+
+	t.Run("DeleteMarkerSameSecond", func(t *testing.T) {
+		// Un marqueur posé dans la même seconde que la version qu'il masque reste le plus récent
+		sameSecond := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, "<ListVersionsResult>"+
+				"<Version><Key>report.txt</Key><VersionId>v1</VersionId><IsLatest>false</IsLatest><LastModified>2024-03-01T10:00:00Z</LastModified></Version>"+
+				"<DeleteMarker><Key>report.txt</Key><VersionId>v2</VersionId><IsLatest>true</IsLatest><LastModified>2024-03-01T10:00:00Z</LastModified></DeleteMarker>"+
+				"</ListVersionsResult>")
+		}))
+		defer sameSecond.Close()
+		useRetryProfile(t, "versioning-same-second", sameSecond.URL)
+
+		output := runCommand(t, "list-object-versions", "docs")
+		assert.Less(t, strings.Index(output, "delete marker report.txt"), strings.Index(output, "0B report.txt"), "Expected the delete marker to be listed first")
+		assert.Contains(t, runCommand(t, "list-object", "docs", "--as-of", "2024-03-02"), "No objects found as of")
+	})
+}